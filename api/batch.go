@@ -0,0 +1,457 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/recolabs/gosip"
+)
+
+//go:generate ggen -ent Batch -conf
+
+// batchMaxOperations is the maximum number of operations sent within a single $batch request
+const batchMaxOperations = 100
+
+// Batch represents SharePoint REST OData $batch requests queue
+// Always use NewBatch constructor or SP.Batch() instead of &Batch{}
+type Batch struct {
+	client     *gosip.SPClient
+	config     *RequestConfig
+	endpoint   string
+	operations []*batchOperation
+}
+
+// BatchResult - single batch operation result, populated after Batch.Execute
+type BatchResult struct {
+	StatusCode int         // operation response status code
+	Status     string      // operation response status, e.g. "201 Created"
+	Header     http.Header // operation response headers
+	Body       []byte      // operation response body
+	Err        error       // operation error, non-nil for error status codes or when the operation was not processed
+}
+
+// batchOperation - queued batch operation
+type batchOperation struct {
+	method   string
+	endpoint string
+	headers  map[string]string
+	body     []byte
+	prepare  func(ctx context.Context, body []byte) []byte // optional body processor called on execution
+	result   *BatchResult
+}
+
+// batchStatusRegExp matches batch response status line, e.g. "HTTP/1.1 201 Created"
+var batchStatusRegExp = regexp.MustCompile(`^HTTP/1\.1 (\d{3}) ?(.*)$`)
+
+// NewBatch - Batch struct constructor function
+func NewBatch(client *gosip.SPClient, endpoint string, config *RequestConfig) *Batch {
+	return &Batch{
+		client:   client,
+		endpoint: endpoint,
+		config:   config,
+	}
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (batch *Batch) ToURL() string {
+	return batch.endpoint
+}
+
+// Len returns a number of queued operations
+func (batch *Batch) Len() int {
+	return len(batch.operations)
+}
+
+// Get queues GET request to the endpoint, `endpoint` is an absolute API URL, e.g. list.Items().Top(10).ToURL()
+func (batch *Batch) Get(endpoint string) *BatchResult {
+	return batch.enqueue(&batchOperation{method: "GET", endpoint: endpoint})
+}
+
+// Post queues POST request to the endpoint with the `body` payload
+func (batch *Batch) Post(endpoint string, body []byte) *BatchResult {
+	return batch.enqueue(&batchOperation{method: "POST", endpoint: endpoint, body: body})
+}
+
+// Update queues MERGE request to the endpoint with the `body` payload
+func (batch *Batch) Update(endpoint string, body []byte) *BatchResult {
+	return batch.enqueue(newBatchUpdateOperation(endpoint, body))
+}
+
+// Delete queues DELETE request to the endpoint
+func (batch *Batch) Delete(endpoint string) *BatchResult {
+	return batch.enqueue(&batchOperation{
+		method:   "POST",
+		endpoint: endpoint,
+		headers: map[string]string{
+			"X-Http-Method": "DELETE",
+			"If-Match":      "*",
+		},
+	})
+}
+
+// AddItem queues adding new item to the list, the same as Items.Add but within the batch
+func (batch *Batch) AddItem(items *Items, body []byte) *BatchResult {
+	op := &batchOperation{method: "POST", endpoint: items.endpoint, body: body}
+	op.prepare = func(ctx context.Context, body []byte) []byte {
+		return patchMetadataTypeCB(body, func() string {
+			return getItemEntityType(ctx, items.client, items.endpoint)
+		})
+	}
+	return batch.enqueue(op)
+}
+
+// UpdateItem queues item's metadata update, the same as Item.Update but within the batch
+func (batch *Batch) UpdateItem(item *Item, body []byte) *BatchResult {
+	op := newBatchUpdateOperation(item.endpoint, body)
+	op.prepare = func(ctx context.Context, body []byte) []byte {
+		return patchMetadataTypeCB(body, func() string {
+			return getItemEntityType(ctx, item.client, item.endpoint)
+		})
+	}
+	return batch.enqueue(op)
+}
+
+// DeleteItem queues item deletion, the same as Item.Delete but within the batch
+func (batch *Batch) DeleteItem(item *Item) *BatchResult {
+	return batch.Delete(item.endpoint)
+}
+
+// RecycleItem queues moving item to the recycle bin, the same as Item.Recycle but within the batch
+func (batch *Batch) RecycleItem(item *Item) *BatchResult {
+	return batch.Post(fmt.Sprintf("%s/Recycle", item.endpoint), nil)
+}
+
+// UpdateField queues field's metadata update, the same as Field.Update but within the batch
+func (batch *Batch) UpdateField(field *Field, body []byte) *BatchResult {
+	return batch.Update(field.endpoint, patchMetadataType(body, "SP.Field"))
+}
+
+// AddRoleAssigment queues role assigment adding, the same as Roles.AddAssigment but within the batch
+func (batch *Batch) AddRoleAssigment(roles *Roles, principalID int, roleDefID int) *BatchResult {
+	return batch.Post(fmt.Sprintf(
+		"%s/RoleAssignments/AddRoleAssignment(principalid=%d,roledefid=%d)",
+		roles.endpoint,
+		principalID,
+		roleDefID,
+	), nil)
+}
+
+// RemoveRoleAssigment queues role assigment removal, the same as Roles.RemoveAssigment but within the batch
+func (batch *Batch) RemoveRoleAssigment(roles *Roles, principalID int, roleDefID int) *BatchResult {
+	return batch.Post(fmt.Sprintf(
+		"%s/RoleAssignments/RemoveRoleAssignment(principalid=%d,roledefid=%d)",
+		roles.endpoint,
+		principalID,
+		roleDefID,
+	), nil)
+}
+
+// Execute sends queued operations as $batch requests, each request contains up to 100 operations.
+// Operations' results are populated to the BatchResult objects returned when queueing.
+// Returned error is only relevant to batch requests failures, operation level errors are in BatchResult.Err.
+// The queue is emptied after the execution.
+func (batch *Batch) Execute(ctx context.Context) error {
+	operations := batch.operations
+	batch.operations = nil
+	for len(operations) > 0 {
+		size := batchMaxOperations
+		if len(operations) < size {
+			size = len(operations)
+		}
+		if err := batch.send(ctx, operations[:size]); err != nil {
+			for _, op := range operations {
+				op.result.Err = err
+			}
+			return err
+		}
+		operations = operations[size:]
+	}
+	return nil
+}
+
+// enqueue adds operation to the batch queue
+func (batch *Batch) enqueue(op *batchOperation) *BatchResult {
+	op.result = &BatchResult{}
+	batch.operations = append(batch.operations, op)
+	return op.result
+}
+
+// newBatchUpdateOperation creates MERGE batch operation
+func newBatchUpdateOperation(endpoint string, body []byte) *batchOperation {
+	return &batchOperation{
+		method:   "POST",
+		endpoint: endpoint,
+		body:     body,
+		headers: map[string]string{
+			"X-Http-Method": "MERGE",
+			"If-Match":      "*",
+		},
+	}
+}
+
+// send sends a single $batch request and processes its responses
+func (batch *Batch) send(ctx context.Context, operations []*batchOperation) error {
+	batchID := fmt.Sprintf("batch_%s", uuid.New().String())
+	body := batch.buildBody(ctx, batchID, operations)
+
+	conf := &RequestConfig{
+		Headers: map[string]string{
+			"Accept":       "application/json",
+			"Content-Type": fmt.Sprintf("multipart/mixed; boundary=\"%s\"", batchID),
+		},
+	}
+	if batch.config != nil {
+		conf.Context = batch.config.Context
+	}
+
+	client := NewHTTPClient(batch.client)
+	endpoint := fmt.Sprintf("%s/_api/$batch", getPriorEndpoint(batch.endpoint, "/_api"))
+	data, err := client.Post(ctx, endpoint, bytes.NewBuffer(body), conf)
+	if err != nil {
+		return err
+	}
+
+	batch.matchResults(operations, parseBatchParts(data))
+	return nil
+}
+
+// matchResults populates operations results, a failed changeset is responded with a single error
+// which is applied to all the changeset operations, so results are matched per batch part
+func (batch *Batch) matchResults(operations []*batchOperation, parts []*batchPartResult) {
+	byPart := map[int][]*batchPartResult{}
+	byContentID := map[string]*BatchResult{}
+	for _, p := range parts {
+		byPart[p.part] = append(byPart[p.part], p)
+		if p.contentID != "" {
+			byContentID[p.contentID] = p.result
+		}
+	}
+
+	groups := batchGroups(operations)
+	for part, group := range groups {
+		results := byPart[part]
+		for i, opIndex := range group {
+			op := operations[opIndex]
+			if res, ok := byContentID[strconv.Itoa(opIndex+1)]; ok {
+				*op.result = *res
+				continue
+			}
+			switch {
+			case i < len(results) && len(results) == len(group):
+				*op.result = *results[i].result
+			case len(results) == 1 && results[0].result.Err != nil:
+				// Changeset is failed as a whole, none of its operations is applied
+				*op.result = *results[0].result
+			default:
+				op.result.Err = fmt.Errorf("no response for %s %s in batch", op.method, op.endpoint)
+			}
+		}
+	}
+}
+
+// batchGroups groups operations indexes by batch parts, each GET is a separate part,
+// consecutive write operations share a changeset part
+func batchGroups(operations []*batchOperation) [][]int {
+	var groups [][]int
+	inChangeset := false
+	for i, op := range operations {
+		if op.method == "GET" || !inChangeset {
+			groups = append(groups, nil)
+		}
+		inChangeset = op.method != "GET"
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	return groups
+}
+
+// buildBody constructs multipart/mixed $batch payload, write operations are grouped into changesets
+func (batch *Batch) buildBody(ctx context.Context, batchID string, operations []*batchOperation) []byte {
+	var buf bytes.Buffer
+	changesetID := ""
+
+	closeChangeset := func() {
+		if changesetID != "" {
+			buf.WriteString(fmt.Sprintf("--%s--\r\n\r\n", changesetID))
+			changesetID = ""
+		}
+	}
+
+	for i, op := range operations {
+		if op.method == "GET" {
+			closeChangeset()
+			buf.WriteString(fmt.Sprintf("--%s\r\n", batchID))
+		} else {
+			if changesetID == "" {
+				changesetID = fmt.Sprintf("changeset_%s", uuid.New().String())
+				buf.WriteString(fmt.Sprintf("--%s\r\n", batchID))
+				buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", changesetID))
+			}
+			buf.WriteString(fmt.Sprintf("--%s\r\n", changesetID))
+		}
+
+		buf.WriteString("Content-Type: application/http\r\n")
+		buf.WriteString("Content-Transfer-Encoding: binary\r\n")
+		buf.WriteString(fmt.Sprintf("Content-ID: %d\r\n\r\n", i+1))
+		buf.WriteString(fmt.Sprintf("%s %s HTTP/1.1\r\n", op.method, op.endpoint))
+
+		for key, value := range batch.getHeaders(op) {
+			buf.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+		}
+		buf.WriteString("\r\n")
+
+		body := op.body
+		if op.prepare != nil {
+			body = op.prepare(ctx, body)
+		}
+		if len(body) > 0 {
+			buf.Write(body)
+			buf.WriteString("\r\n")
+		}
+		buf.WriteString("\r\n")
+	}
+
+	closeChangeset()
+	buf.WriteString(fmt.Sprintf("--%s--\r\n", batchID))
+
+	return buf.Bytes()
+}
+
+// getHeaders resolves operation headers, batch config headers overrides defaults
+func (batch *Batch) getHeaders(op *batchOperation) map[string]string {
+	headers := map[string]string{
+		"Accept": "application/json;odata=verbose", // default to SP2013 for backwards compatibility
+	}
+	if op.method != "GET" {
+		headers["Content-Type"] = "application/json;odata=verbose;charset=utf-8"
+	}
+	for key, value := range getConfHeaders(batch.config) {
+		if key == "Content-Type" && op.method == "GET" {
+			continue
+		}
+		headers[key] = value
+	}
+	for key, value := range op.headers {
+		headers[key] = value
+	}
+	return headers
+}
+
+// batchPartResult - operation result with its position in the batch response
+type batchPartResult struct {
+	part      int    // top level batch part index
+	contentID string // Content-ID of the operation when echoed in the response
+	result    *BatchResult
+}
+
+// parseBatchResponse parses $batch multipart response into results in operations order
+func parseBatchResponse(data []byte) []*BatchResult {
+	var results []*BatchResult
+	for _, p := range parseBatchParts(data) {
+		results = append(results, p.result)
+	}
+	return results
+}
+
+// parseBatchParts parses $batch multipart response into results keeping their batch parts
+func parseBatchParts(data []byte) []*batchPartResult {
+	var results []*batchPartResult
+	var current *BatchResult
+	var body []string
+	boundary, contentID := "", ""
+	part := -1
+	state := "boundary"
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Body = []byte(strings.TrimSpace(strings.Join(body, "\n")))
+		if !(current.StatusCode >= 200 && current.StatusCode < 300) {
+			current.Err = gosip.NewSPError(&http.Response{
+				StatusCode: current.StatusCode,
+				Status:     current.Status,
+				Header:     current.Header,
+			}, current.Body)
+		}
+		results = append(results, &batchPartResult{part: part, contentID: contentID, result: current})
+		current = nil
+		body = nil
+		contentID = ""
+	}
+
+	// nextPart counts top level parts, the first boundary line is the batch response boundary
+	nextPart := func(line string) {
+		if boundary == "" {
+			boundary = line
+		}
+		if line == boundary {
+			part++
+		}
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch state {
+		case "boundary":
+			if strings.HasPrefix(line, "--") {
+				nextPart(line)
+				continue
+			}
+			if kv := strings.SplitN(line, ":", 2); len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "Content-ID") {
+				contentID = strings.TrimSpace(kv[1])
+				continue
+			}
+			if parts := batchStatusRegExp.FindStringSubmatch(line); parts != nil {
+				statusCode, _ := strconv.Atoi(parts[1])
+				current = &BatchResult{
+					StatusCode: statusCode,
+					Status:     strings.TrimSpace(parts[1] + " " + parts[2]),
+					Header:     http.Header{},
+				}
+				state = "headers"
+			}
+		case "headers":
+			if strings.TrimSpace(line) == "" {
+				state = "body"
+				continue
+			}
+			if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+				current.Header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+			}
+		case "body":
+			if strings.HasPrefix(line, "--") {
+				flush()
+				nextPart(line)
+				state = "boundary"
+				continue
+			}
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return results
+}
+
+/* Result helpers */
+
+// ItemResp gets result body as item response
+func (res *BatchResult) ItemResp() ItemResp {
+	return ItemResp(res.Body)
+}
+
+// ItemsResp gets result body as items collection response
+func (res *BatchResult) ItemsResp() ItemsResp {
+	return ItemsResp(res.Body)
+}
+
+// FieldResp gets result body as field response
+func (res *BatchResult) FieldResp() FieldResp {
+	return FieldResp(res.Body)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/recolabs/gosip"
+)
+
+func TestBatch(t *testing.T) {
+	checkClient(t)
+
+	web := NewSP(spClient).Web()
+	newListTitle := strings.Replace(uuid.New().String(), "-", "", -1)
+	if _, err := web.Lists().Add(context.Background(), newListTitle, nil); err != nil {
+		t.Error(err)
+	}
+	list := web.Lists().GetByTitle(newListTitle)
+
+	t.Run("AddItems", func(t *testing.T) {
+		batch := NewSP(spClient).Batch()
+		var results []*BatchResult
+		for i := 1; i <= 5; i++ {
+			body := []byte(fmt.Sprintf(`{"Title":"Batch item %d"}`, i))
+			results = append(results, batch.AddItem(list.Items(), body))
+		}
+		if err := batch.Execute(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		for _, res := range results {
+			if res.Err != nil {
+				t.Error(res.Err)
+			}
+			item := res.ItemResp()
+			if item.Data().ID == 0 {
+				t.Error("can't get batch item add result properly")
+			}
+		}
+	})
+
+	t.Run("MixedOperations", func(t *testing.T) {
+		batch := NewSP(spClient).Batch()
+		upd := batch.UpdateItem(list.Items().GetByID(1), []byte(`{"Title":"Updated"}`))
+		del := batch.DeleteItem(list.Items().GetByID(2))
+		get := batch.Get(list.Items().Select("Id,Title").ToURL())
+		if err := batch.Execute(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if upd.Err != nil {
+			t.Error(upd.Err)
+		}
+		if del.Err != nil {
+			t.Error(del.Err)
+		}
+		if get.Err != nil {
+			t.Error(get.Err)
+		}
+		items := get.ItemsResp()
+		if len(items.Data()) != 4 {
+			t.Errorf("incorrect items number, expected 4, got %d", len(items.Data()))
+		}
+	})
+
+	t.Run("OperationError", func(t *testing.T) {
+		batch := NewSP(spClient).Batch()
+		res := batch.UpdateItem(list.Items().GetByID(1000), []byte(`{"Title":"Updated"}`))
+		if err := batch.Execute(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if res.Err == nil {
+			t.Error("should fail on updating missing item")
+		}
+	})
+
+	if err := list.Delete(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBatchBody(t *testing.T) {
+	batch := NewBatch(nil, "https://contoso.sharepoint.com/sites/site/_api/$batch", nil)
+	batch.Get("https://contoso.sharepoint.com/sites/site/_api/Web")
+	batch.Post("https://contoso.sharepoint.com/sites/site/_api/Web/Lists/GetByTitle('List')/Items", []byte(`{"Title":"Item"}`))
+	batch.Delete("https://contoso.sharepoint.com/sites/site/_api/Web/Lists/GetByTitle('List')/Items(1)")
+	batch.Get("https://contoso.sharepoint.com/sites/site/_api/Web/Title")
+
+	body := string(batch.buildBody(context.Background(), "batch_id", batch.operations))
+
+	if strings.Count(body, "--batch_id\r\n") != 3 {
+		t.Errorf("incorrect batch parts number, expected 3, got %d", strings.Count(body, "--batch_id\r\n"))
+	}
+	if !strings.HasSuffix(body, "--batch_id--\r\n") {
+		t.Error("batch is not closed")
+	}
+	if strings.Count(body, "Content-Type: multipart/mixed; boundary=\"changeset_") != 1 {
+		t.Error("write operations should be grouped into a single changeset")
+	}
+	if !strings.Contains(body, "X-Http-Method: DELETE\r\n") {
+		t.Error("delete operation headers are missing")
+	}
+	if !strings.Contains(body, "{\"Title\":\"Item\"}\r\n") {
+		t.Error("operation body is missing")
+	}
+}
+
+func TestBatchResponse(t *testing.T) {
+	resp := strings.Join([]string{
+		"--batchresponse_1",
+		"Content-Type: application/http",
+		"Content-Transfer-Encoding: binary",
+		"",
+		"HTTP/1.1 200 OK",
+		"CONTENT-TYPE: application/json;odata=verbose;charset=utf-8",
+		"",
+		`{"d":{"Title":"Web"}}`,
+		"--batchresponse_1",
+		"Content-Type: multipart/mixed; boundary=changesetresponse_1",
+		"",
+		"--changesetresponse_1",
+		"Content-Type: application/http",
+		"Content-Transfer-Encoding: binary",
+		"",
+		"HTTP/1.1 204 No Content",
+		"",
+		"",
+		"--changesetresponse_1",
+		"Content-Type: application/http",
+		"Content-Transfer-Encoding: binary",
+		"",
+		"HTTP/1.1 404 Not Found",
+		"CONTENT-TYPE: application/json;odata=verbose;charset=utf-8",
+		"",
+		`{"error":{"code":"-2130575338, Microsoft.SharePoint.SPException","message":{"lang":"en-US","value":"Item does not exist."}}}`,
+		"--changesetresponse_1--",
+		"--batchresponse_1--",
+	}, "\r\n")
+
+	results := parseBatchResponse([]byte(resp))
+	if len(results) != 3 {
+		t.Fatalf("incorrect results number, expected 3, got %d", len(results))
+	}
+
+	web := map[string]map[string]string{}
+	if err := json.Unmarshal(results[0].Body, &web); err != nil {
+		t.Error(err)
+	}
+	if web["d"]["Title"] != "Web" {
+		t.Error("can't parse operation body")
+	}
+	if results[0].Header.Get("Content-Type") != "application/json;odata=verbose;charset=utf-8" {
+		t.Error("can't parse operation headers")
+	}
+	if results[1].StatusCode != 204 || results[1].Err != nil || len(results[1].Body) != 0 {
+		t.Error("can't parse no content operation")
+	}
+	if results[2].StatusCode != 404 || results[2].Err == nil {
+		t.Error("operation error is not populated")
+	}
+	if !gosip.IsNotFound(results[2].Err) {
+		t.Error("operation error should be SharePoint not found error")
+	}
+}
+
+func TestBatchFailedChangeset(t *testing.T) {
+	batch := NewBatch(nil, "https://contoso.sharepoint.com/sites/site/_api/$batch", nil)
+	upd := batch.Post("https://contoso.sharepoint.com/sites/site/_api/Web/Lists/GetByTitle('List')/Items(1000)", []byte(`{"Title":"Item"}`))
+	del := batch.Delete("https://contoso.sharepoint.com/sites/site/_api/Web/Lists/GetByTitle('List')/Items(1)")
+	get := batch.Get("https://contoso.sharepoint.com/sites/site/_api/Web")
+
+	resp := strings.Join([]string{
+		"--batchresponse_1",
+		"Content-Type: application/http",
+		"Content-Transfer-Encoding: binary",
+		"",
+		"HTTP/1.1 404 Not Found",
+		"CONTENT-TYPE: application/json;odata=verbose;charset=utf-8",
+		"",
+		`{"error":{"code":"-2130575338, Microsoft.SharePoint.SPException","message":{"lang":"en-US","value":"Item does not exist."}}}`,
+		"--batchresponse_1",
+		"Content-Type: application/http",
+		"Content-Transfer-Encoding: binary",
+		"",
+		"HTTP/1.1 200 OK",
+		"CONTENT-TYPE: application/json;odata=verbose;charset=utf-8",
+		"",
+		`{"d":{"Title":"Web"}}`,
+		"--batchresponse_1--",
+	}, "\r\n")
+
+	batch.matchResults(batch.operations, parseBatchParts([]byte(resp)))
+
+	if !gosip.IsNotFound(upd.Err) || !gosip.IsNotFound(del.Err) {
+		t.Error("changeset error should be populated to all its operations")
+	}
+	if get.Err != nil {
+		t.Error(get.Err)
+	}
+	if string(get.Body) != `{"d":{"Title":"Web"}}` {
+		t.Errorf("get result is misattributed: %s", get.Body)
+	}
+}
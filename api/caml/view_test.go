@@ -0,0 +1,115 @@
+package caml
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip/api"
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestConditions(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		cond     Cond
+		expected string
+	}{
+		"Text":       {Eq("Title", Text("A & <B>")), `<Eq><FieldRef Name="Title" /><Value Type="Text">A &amp; &lt;B&gt;</Value></Eq>`},
+		"Number":     {Gt("Amount", Number(1.5)), `<Gt><FieldRef Name="Amount" /><Value Type="Number">1.5</Value></Gt>`},
+		"Boolean":    {Neq("Done", Boolean(true)), `<Neq><FieldRef Name="Done" /><Value Type="Boolean">1</Value></Neq>`},
+		"Date":       {Lt("Due", DateTime(date, false)), `<Lt><FieldRef Name="Due" /><Value Type="DateTime" StorageTZ="TRUE">2020-01-02T03:04:05Z</Value></Lt>`},
+		"DateTime":   {Leq("Due", DateTime(date, true)), `<Leq><FieldRef Name="Due" /><Value Type="DateTime" IncludeTimeValue="TRUE" StorageTZ="TRUE">2020-01-02T03:04:05Z</Value></Leq>`},
+		"LocalTime":  {Eq("Due", DateTime(date.In(time.FixedZone("MSK", 3*60*60)), true)), `<Eq><FieldRef Name="Due" /><Value Type="DateTime" IncludeTimeValue="TRUE" StorageTZ="TRUE">2020-01-02T03:04:05Z</Value></Eq>`},
+		"Today":      {Geq("Due", Today(-7)), `<Geq><FieldRef Name="Due" /><Value Type="DateTime"><Today OffsetDays="-7" /></Value></Geq>`},
+		"Lookup":     {Eq("Parent", LookupID(3)), `<Eq><FieldRef Name="Parent" LookupId="TRUE" /><Value Type="Lookup">3</Value></Eq>`},
+		"Me":         {Eq("Author", CurrentUser()), `<Eq><FieldRef Name="Author" /><Value Type="Integer"><UserID Type="Integer" /></Value></Eq>`},
+		"Contains":   {Contains("Title", "x"), `<Contains><FieldRef Name="Title" /><Value Type="Text">x</Value></Contains>`},
+		"BeginsWith": {BeginsWith("Title", "x"), `<BeginsWith><FieldRef Name="Title" /><Value Type="Text">x</Value></BeginsWith>`},
+		"In":         {In("Owner", UserID(1), UserID(2)), `<In><FieldRef Name="Owner" LookupId="TRUE" /><Values><Value Type="User">1</Value><Value Type="User">2</Value></Values></In>`},
+		"IsNull":     {IsNull("Parent"), `<IsNull><FieldRef Name="Parent" /></IsNull>`},
+		"Overlap": {
+			DateRangesOverlap(Now(), "EventDate", "EndDate"),
+			`<DateRangesOverlap><FieldRef Name="EventDate" /><FieldRef Name="EndDate" /><Value Type="DateTime" IncludeTimeValue="TRUE"><Now /></Value></DateRangesOverlap>`,
+		},
+		"Nested": {
+			And(IsNull("A"), Cond{}, Or(IsNull("B"), IsNotNull("C")), IsNull("D")),
+			`<And><IsNull><FieldRef Name="A" /></IsNull><And><Or><IsNull><FieldRef Name="B" /></IsNull><IsNotNull><FieldRef Name="C" /></IsNotNull></Or><IsNull><FieldRef Name="D" /></IsNull></And></And>`,
+		},
+		"Single": {Or(Cond{}, IsNull("A")), `<IsNull><FieldRef Name="A" /></IsNull>`},
+		"Empty":  {And(), ""},
+	}
+
+	for name, c := range cases {
+		if res := c.cond.String(); res != c.expected {
+			t.Errorf("%s: unexpected condition:\n%s\nexpected:\n%s", name, res, c.expected)
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	view := NewView().
+		Where(And(Eq("Title", Text(`"Quoted"`)), Gt("ID", Integer(1)))).
+		OrderBy("Modified", false).
+		OrderBy("ID", true).
+		ViewFields("ID", "Title").
+		RowLimit(10, true).
+		Scope(ScopeRecursiveAll)
+
+	expected := `<View Scope="RecursiveAll"><Query><Where><And>` +
+		`<Eq><FieldRef Name="Title" /><Value Type="Text">&#34;Quoted&#34;</Value></Eq>` +
+		`<Gt><FieldRef Name="ID" /><Value Type="Integer">1</Value></Gt>` +
+		`</And></Where><OrderBy><FieldRef Name="Modified" Ascending="FALSE" /><FieldRef Name="ID" Ascending="TRUE" /></OrderBy></Query>` +
+		`<ViewFields><FieldRef Name="ID" /><FieldRef Name="Title" /></ViewFields>` +
+		`<RowLimit Paged="TRUE">10</RowLimit></View>`
+	if view.String() != expected {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(view.String()))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("view XML is not valid: %s", err)
+			}
+			break
+		}
+	}
+
+	if NewView().String() != "<View><Query></Query></View>" {
+		t.Errorf("unexpected empty view: %s", NewView())
+	}
+}
+
+func TestViewQuery(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range []string{"Alpha", "Beta", "Alpha & Omega"} {
+		if _, err := srv.AddItem("Tasks", map[string]interface{}{"Title": title, "Priority": i + 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view := NewView().
+		Where(Or(BeginsWith("Title", "Alpha"), Eq("Priority", Number(2)))).
+		OrderBy("Priority", false).
+		ViewFields("Title").
+		RowLimit(2, false)
+
+	resp, err := api.NewSP(srv.Client()).Web().Lists().GetByTitle("Tasks").Items().GetByCAML(context.Background(), view.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := resp.Data()
+	if len(items) != 2 || items[0].Data().Title != "Alpha & Omega" || items[1].Data().Title != "Beta" {
+		t.Errorf("unexpected items: %s", resp)
+	}
+}
@@ -0,0 +1,234 @@
+package caml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cond is CAML Where condition, zero value is an empty condition which is skipped in And and Or
+type Cond struct {
+	xml string
+}
+
+// String renders condition XML
+func (c Cond) String() string {
+	return c.xml
+}
+
+// IsEmpty checks if the condition is empty
+func (c Cond) IsEmpty() bool {
+	return c.xml == ""
+}
+
+// Value is typed CAML value
+type Value struct {
+	typ      string
+	attrs    string
+	inner    string // escaped text or nested elements
+	lookupID bool   // compare lookup or user field by ID
+}
+
+// String renders value XML
+func (v Value) String() string {
+	return "<Value Type=\"" + v.typ + "\"" + v.attrs + ">" + v.inner + "</Value>"
+}
+
+// Text is Text value
+func Text(s string) Value {
+	return Value{typ: "Text", inner: escape(s)}
+}
+
+// Note is Note (multiline text) value
+func Note(s string) Value {
+	return Value{typ: "Note", inner: escape(s)}
+}
+
+// Choice is Choice value
+func Choice(s string) Value {
+	return Value{typ: "Choice", inner: escape(s)}
+}
+
+// Number is Number value
+func Number(n float64) Value {
+	return Value{typ: "Number", inner: strconv.FormatFloat(n, 'f', -1, 64)}
+}
+
+// Integer is Integer value, e.g. to compare ID field
+func Integer(n int) Value {
+	return Value{typ: "Integer", inner: strconv.Itoa(n)}
+}
+
+// Boolean is Boolean value
+func Boolean(b bool) Value {
+	if b {
+		return Value{typ: "Boolean", inner: "1"}
+	}
+	return Value{typ: "Boolean", inner: "0"}
+}
+
+// DateTime is DateTime value, includeTime adds IncludeTimeValue to compare with time, otherwise dates only are compared.
+// The value is sent in UTC with StorageTZ so SharePoint doesn't treat it as the site local time
+func DateTime(t time.Time, includeTime bool) Value {
+	v := Value{typ: "DateTime", inner: t.UTC().Format(time.RFC3339)}
+	if includeTime {
+		v.attrs = ` IncludeTimeValue="TRUE"`
+	}
+	v.attrs += ` StorageTZ="TRUE"`
+	return v
+}
+
+// Today is DateTime value of current date shifted by offset days
+func Today(offsetDays int) Value {
+	if offsetDays == 0 {
+		return Value{typ: "DateTime", inner: "<Today />"}
+	}
+	return Value{typ: "DateTime", inner: "<Today OffsetDays=\"" + strconv.Itoa(offsetDays) + "\" />"}
+}
+
+// Now is DateTime value of current date and time
+func Now() Value {
+	return Value{typ: "DateTime", inner: "<Now />", attrs: ` IncludeTimeValue="TRUE"`}
+}
+
+// LookupID is Lookup value comparing lookup field by item ID
+func LookupID(id int) Value {
+	return Value{typ: "Lookup", inner: strconv.Itoa(id), lookupID: true}
+}
+
+// UserID is User value comparing user field by user ID
+func UserID(id int) Value {
+	return Value{typ: "User", inner: strconv.Itoa(id), lookupID: true}
+}
+
+// CurrentUser is user field value matching current user
+func CurrentUser() Value {
+	return Value{typ: "Integer", inner: "<UserID Type=\"Integer\" />"}
+}
+
+// Eq is `field == value` condition
+func Eq(field string, value Value) Cond {
+	return compare("Eq", field, value)
+}
+
+// Neq is `field != value` condition
+func Neq(field string, value Value) Cond {
+	return compare("Neq", field, value)
+}
+
+// Gt is `field > value` condition
+func Gt(field string, value Value) Cond {
+	return compare("Gt", field, value)
+}
+
+// Geq is `field >= value` condition
+func Geq(field string, value Value) Cond {
+	return compare("Geq", field, value)
+}
+
+// Lt is `field < value` condition
+func Lt(field string, value Value) Cond {
+	return compare("Lt", field, value)
+}
+
+// Leq is `field <= value` condition
+func Leq(field string, value Value) Cond {
+	return compare("Leq", field, value)
+}
+
+// Contains is text field contains substring condition
+func Contains(field string, value string) Cond {
+	return compare("Contains", field, Text(value))
+}
+
+// BeginsWith is text field starts with prefix condition
+func BeginsWith(field string, value string) Cond {
+	return compare("BeginsWith", field, Text(value))
+}
+
+// In is field equals any of the values condition
+func In(field string, values ...Value) Cond {
+	lookupID := false
+	b := &strings.Builder{}
+	b.WriteString("<Values>")
+	for _, v := range values {
+		lookupID = lookupID || v.lookupID
+		b.WriteString(v.String())
+	}
+	b.WriteString("</Values>")
+	return Cond{xml: "<In>" + fieldRef(field, lookupID) + b.String() + "</In>"}
+}
+
+// IsNull is field is empty condition
+func IsNull(field string) Cond {
+	return Cond{xml: "<IsNull>" + fieldRef(field, false) + "</IsNull>"}
+}
+
+// IsNotNull is field is not empty condition
+func IsNotNull(field string) Cond {
+	return Cond{xml: "<IsNotNull>" + fieldRef(field, false) + "</IsNotNull>"}
+}
+
+// DateRangesOverlap is calendar events overlapping the value condition,
+// fields are usually EventDate, EndDate and RecurrenceID, value is e.g. Now() or Today(0)
+func DateRangesOverlap(value Value, fields ...string) Cond {
+	b := &strings.Builder{}
+	b.WriteString("<DateRangesOverlap>")
+	for _, f := range fields {
+		b.WriteString(fieldRef(f, false))
+	}
+	b.WriteString(value.String())
+	b.WriteString("</DateRangesOverlap>")
+	return Cond{xml: b.String()}
+}
+
+// And joins conditions with And operator, empty conditions are skipped,
+// more than two conditions are nested as CAML logical operators are binary
+func And(conds ...Cond) Cond {
+	return join("And", conds)
+}
+
+// Or joins conditions with Or operator, empty conditions are skipped,
+// more than two conditions are nested as CAML logical operators are binary
+func Or(conds ...Cond) Cond {
+	return join("Or", conds)
+}
+
+// compare builds comparison condition
+func compare(op string, field string, value Value) Cond {
+	return Cond{xml: "<" + op + ">" + fieldRef(field, value.lookupID) + value.String() + "</" + op + ">"}
+}
+
+// join nests conditions with binary logical operator
+func join(op string, conds []Cond) Cond {
+	var nonEmpty []Cond
+	for _, c := range conds {
+		if !c.IsEmpty() {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return Cond{}
+	}
+	res := nonEmpty[len(nonEmpty)-1]
+	for i := len(nonEmpty) - 2; i >= 0; i-- {
+		res = Cond{xml: "<" + op + ">" + nonEmpty[i].xml + res.xml + "</" + op + ">"}
+	}
+	return res
+}
+
+// fieldRef renders FieldRef element
+func fieldRef(field string, lookupID bool) string {
+	if lookupID {
+		return "<FieldRef Name=\"" + escape(field) + "\" LookupId=\"TRUE\" />"
+	}
+	return "<FieldRef Name=\"" + escape(field) + "\" />"
+}
+
+// escape escapes XML text and attribute values
+func escape(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s))
+	return b.String()
+}
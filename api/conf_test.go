@@ -157,12 +157,16 @@ func getAllConstructors(spClient *gosip.SPClient) map[string]interface{} {
 		"Fields":           NewFields(spClient, "", nil, ""),
 		"File":             NewFile(spClient, "", nil),
 		"Files":            NewFiles(spClient, "", nil),
+		"FileVersion":      NewFileVersion(spClient, "", nil),
+		"FileVersions":     NewFileVersions(spClient, "", nil),
 		"Folder":           NewFolder(spClient, "", nil),
 		"Folders":          NewFolders(spClient, "", nil),
 		"Group":            NewGroup(spClient, "", nil),
 		"Groups":           NewGroups(spClient, "", nil),
 		"Item":             NewItem(spClient, "", nil),
 		"Items":            NewItems(spClient, "", nil),
+		"ItemVersion":      NewItemVersion(spClient, "", nil),
+		"ItemVersions":     NewItemVersions(spClient, "", nil),
 		"Profiles":         NewProfiles(spClient, "", nil),
 		"Properties":       NewProperties(spClient, "", nil, ""),
 		"Records":          NewRecords(NewItem(spClient, "", nil)),
@@ -171,6 +175,7 @@ func getAllConstructors(spClient *gosip.SPClient) map[string]interface{} {
 		"RoleDefinitions":  NewRoleDefinitions(spClient, "", nil),
 		"Roles":            NewRoles(spClient, "", nil),
 		"Search":           NewSearch(spClient, "", nil),
+		"Sharing":          NewSharing(spClient, "", nil),
 		"Site":             NewSite(spClient, "", nil),
 		"Subscription":     NewSubscription(spClient, "", nil),
 		"Subscriptions":    NewSubscriptions(spClient, "", nil),
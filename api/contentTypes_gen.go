@@ -26,6 +26,12 @@ func (contentTypes *ContentTypes) Filter(oDataFilter string) *ContentTypes {
 	return contentTypes
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (contentTypes *ContentTypes) FilterExpr(expr ODataExpr) *ContentTypes {
+	contentTypes.modifiers.AddFilterExpr(expr)
+	return contentTypes
+}
+
 // Top adds $top OData modifier
 func (contentTypes *ContentTypes) Top(oDataTop int) *ContentTypes {
 	contentTypes.modifiers.AddTop(oDataTop)
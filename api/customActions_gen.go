@@ -20,6 +20,12 @@ func (customActions *CustomActions) Filter(oDataFilter string) *CustomActions {
 	return customActions
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (customActions *CustomActions) FilterExpr(expr ODataExpr) *CustomActions {
+	customActions.modifiers.AddFilterExpr(expr)
+	return customActions
+}
+
 // Top adds $top OData modifier
 func (customActions *CustomActions) Top(oDataTop int) *CustomActions {
 	customActions.modifiers.AddTop(oDataTop)
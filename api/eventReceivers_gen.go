@@ -20,6 +20,12 @@ func (eventReceivers *EventReceivers) Filter(oDataFilter string) *EventReceivers
 	return eventReceivers
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (eventReceivers *EventReceivers) FilterExpr(expr ODataExpr) *EventReceivers {
+	eventReceivers.modifiers.AddFilterExpr(expr)
+	return eventReceivers
+}
+
 // Top adds $top OData modifier
 func (eventReceivers *EventReceivers) Top(oDataTop int) *EventReceivers {
 	eventReceivers.modifiers.AddTop(oDataTop)
@@ -20,6 +20,12 @@ func (fieldLinks *FieldLinks) Filter(oDataFilter string) *FieldLinks {
 	return fieldLinks
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (fieldLinks *FieldLinks) FilterExpr(expr ODataExpr) *FieldLinks {
+	fieldLinks.modifiers.AddFilterExpr(expr)
+	return fieldLinks
+}
+
 // Top adds $top OData modifier
 func (fieldLinks *FieldLinks) Top(oDataTop int) *FieldLinks {
 	fieldLinks.modifiers.AddTop(oDataTop)
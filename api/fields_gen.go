@@ -26,6 +26,12 @@ func (fields *Fields) Filter(oDataFilter string) *Fields {
 	return fields
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (fields *Fields) FilterExpr(expr ODataExpr) *Fields {
+	fields.modifiers.AddFilterExpr(expr)
+	return fields
+}
+
 // Top adds $top OData modifier
 func (fields *Fields) Top(oDataTop int) *Fields {
 	fields.modifiers.AddTop(oDataTop)
@@ -262,6 +262,15 @@ func (file *File) ContextInfo(ctx context.Context) (*ContextInfo, error) {
 	return NewContext(file.client, file.ToURL(), file.config).Get(ctx)
 }
 
+// Versions gets file versions API instance queryable collection for this File
+func (file *File) Versions() *FileVersions {
+	return NewFileVersions(
+		file.client,
+		fmt.Sprintf("%s/Versions", file.endpoint),
+		file.config,
+	)
+}
+
 // Props gets Properties API instance queryable collection for this File
 func (file *File) Props() *Properties {
 	return NewProperties(
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/recolabs/gosip"
+)
+
+//go:generate ggen -ent FileVersions -item FileVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized
+//go:generate ggen -ent FileVersion -conf -mods Select,Expand -helpers Data,Normalized
+
+// FileVersions represent SharePoint File Versions API queryable collection struct
+// Always use NewFileVersions constructor instead of &FileVersions{}
+type FileVersions struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// FileVersion represent SharePoint File Version API queryable object struct
+// Always use NewFileVersion constructor instead of &FileVersion{}
+type FileVersion struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// FileVersionInfo - file version API response payload structure
+type FileVersionInfo struct {
+	ID               int       `json:"ID"`
+	VersionLabel     string    `json:"VersionLabel"`
+	Size             int64     `json:"Size"`
+	Created          time.Time `json:"Created"`
+	CheckInComment   string    `json:"CheckInComment"`
+	IsCurrentVersion bool      `json:"IsCurrentVersion"`
+	URL              string    `json:"Url"`
+	CreatedBy        *UserInfo `json:"CreatedBy"` // requires `Expand("CreatedBy")`
+}
+
+// FileVersionsResp - file versions response type with helper processor methods
+type FileVersionsResp []byte
+
+// FileVersionResp - file version response type with helper processor methods
+type FileVersionResp []byte
+
+// NewFileVersions - FileVersions struct constructor function
+func NewFileVersions(client *gosip.SPClient, endpoint string, config *RequestConfig) *FileVersions {
+	return &FileVersions{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// NewFileVersion - FileVersion struct constructor function
+func NewFileVersion(client *gosip.SPClient, endpoint string, config *RequestConfig) *FileVersion {
+	return &FileVersion{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (fileVersions *FileVersions) ToURL() string {
+	return toURL(fileVersions.endpoint, fileVersions.modifiers)
+}
+
+// Get gets file versions collection, the current version is not included
+func (fileVersions *FileVersions) Get(ctx context.Context) (FileVersionsResp, error) {
+	client := NewHTTPClient(fileVersions.client)
+	return client.Get(ctx, fileVersions.ToURL(), fileVersions.config)
+}
+
+// GetByID gets file version API object by version ID, e.g. 512 for version 1.0
+func (fileVersions *FileVersions) GetByID(versionID int) *FileVersion {
+	return NewFileVersion(
+		fileVersions.client,
+		fmt.Sprintf("%s(%d)", fileVersions.endpoint, versionID),
+		fileVersions.config,
+	)
+}
+
+// DeleteByID deletes file version by version ID
+func (fileVersions *FileVersions) DeleteByID(ctx context.Context, versionID int) error {
+	endpoint := fmt.Sprintf("%s/DeleteByID(vid=%d)", fileVersions.endpoint, versionID)
+	client := NewHTTPClient(fileVersions.client)
+	_, err := client.Post(ctx, endpoint, nil, fileVersions.config)
+	return err
+}
+
+// DeleteByLabel deletes file version by version label, e.g. "1.0"
+func (fileVersions *FileVersions) DeleteByLabel(ctx context.Context, versionLabel string) error {
+	endpoint := fmt.Sprintf(
+		"%s/DeleteByLabel(versionlabel='%s')",
+		fileVersions.endpoint,
+		url.PathEscape(strings.ReplaceAll(versionLabel, "'", "''")),
+	)
+	client := NewHTTPClient(fileVersions.client)
+	_, err := client.Post(ctx, endpoint, nil, fileVersions.config)
+	return err
+}
+
+// DeleteAll deletes all file versions except the current one
+func (fileVersions *FileVersions) DeleteAll(ctx context.Context) error {
+	endpoint := fmt.Sprintf("%s/DeleteAll", fileVersions.endpoint)
+	client := NewHTTPClient(fileVersions.client)
+	_, err := client.Post(ctx, endpoint, nil, fileVersions.config)
+	return err
+}
+
+// DeleteMinor deletes all minor (draft) file versions, major versions are kept
+func (fileVersions *FileVersions) DeleteMinor(ctx context.Context) error {
+	resp, err := NewFileVersions(fileVersions.client, fileVersions.endpoint, fileVersions.config).
+		Select("ID,VersionLabel").Get(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range resp.Data() {
+		version := v.Data()
+		if strings.HasSuffix(version.VersionLabel, ".0") {
+			continue
+		}
+		if err := fileVersions.DeleteByID(ctx, version.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreByLabel restores file version by version label, the restored content becomes a new current version
+func (fileVersions *FileVersions) RestoreByLabel(ctx context.Context, versionLabel string) error {
+	endpoint := fmt.Sprintf(
+		"%s/RestoreByLabel(versionlabel='%s')",
+		fileVersions.endpoint,
+		url.PathEscape(strings.ReplaceAll(versionLabel, "'", "''")),
+	)
+	client := NewHTTPClient(fileVersions.client)
+	_, err := client.Post(ctx, endpoint, nil, fileVersions.config)
+	return err
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (fileVersion *FileVersion) ToURL() string {
+	return toURL(fileVersion.endpoint, fileVersion.modifiers)
+}
+
+// Get gets file version metadata
+func (fileVersion *FileVersion) Get(ctx context.Context) (FileVersionResp, error) {
+	client := NewHTTPClient(fileVersion.client)
+	return client.Get(ctx, fileVersion.ToURL(), fileVersion.config)
+}
+
+// GetReader gets file version content io.ReadCloser
+func (fileVersion *FileVersion) GetReader(ctx context.Context) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("%s/$value", fileVersion.endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply context
+	if fileVersion.config != nil && fileVersion.config.Context != nil {
+		req = req.WithContext(fileVersion.config.Context)
+	}
+
+	req.TransferEncoding = []string{"null"}
+	for key, value := range getConfHeaders(fileVersion.config) {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := fileVersion.client.Execute(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Download downloads file version content bytes
+func (fileVersion *FileVersion) Download(ctx context.Context) ([]byte, error) {
+	body, err := fileVersion.GetReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer shut(body)
+
+	return io.ReadAll(body)
+}
@@ -0,0 +1,63 @@
+// Code generated by `ggen -ent FileVersions -item FileVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized`; DO NOT EDIT.
+
+package api
+
+// Conf receives custom request config definition, e.g. custom headers, custom OData mod
+func (fileVersions *FileVersions) Conf(config *RequestConfig) *FileVersions {
+	fileVersions.config = config
+	return fileVersions
+}
+
+// Select adds $select OData modifier
+func (fileVersions *FileVersions) Select(oDataSelect string) *FileVersions {
+	fileVersions.modifiers.AddSelect(oDataSelect)
+	return fileVersions
+}
+
+// Expand adds $expand OData modifier
+func (fileVersions *FileVersions) Expand(oDataExpand string) *FileVersions {
+	fileVersions.modifiers.AddExpand(oDataExpand)
+	return fileVersions
+}
+
+// Filter adds $filter OData modifier
+func (fileVersions *FileVersions) Filter(oDataFilter string) *FileVersions {
+	fileVersions.modifiers.AddFilter(oDataFilter)
+	return fileVersions
+}
+
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (fileVersions *FileVersions) FilterExpr(expr ODataExpr) *FileVersions {
+	fileVersions.modifiers.AddFilterExpr(expr)
+	return fileVersions
+}
+
+// Top adds $top OData modifier
+func (fileVersions *FileVersions) Top(oDataTop int) *FileVersions {
+	fileVersions.modifiers.AddTop(oDataTop)
+	return fileVersions
+}
+
+// OrderBy adds $orderby OData modifier
+func (fileVersions *FileVersions) OrderBy(oDataOrderBy string, ascending bool) *FileVersions {
+	fileVersions.modifiers.AddOrderBy(oDataOrderBy, ascending)
+	return fileVersions
+}
+
+/* Response helpers */
+
+// Data response helper
+func (fileVersionsResp *FileVersionsResp) Data() []FileVersionResp {
+	collection, _ := normalizeODataCollection(*fileVersionsResp)
+	fileVersions := []FileVersionResp{}
+	for _, item := range collection {
+		fileVersions = append(fileVersions, FileVersionResp(item))
+	}
+	return fileVersions
+}
+
+// Normalized returns normalized body
+func (fileVersionsResp *FileVersionsResp) Normalized() []byte {
+	normalized, _ := NormalizeODataCollection(*fileVersionsResp)
+	return normalized
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestFileVersions(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	fileURL := sptest.ServerSitePath + "/Shared Documents/Versions/Doc.txt"
+	for i := 1; i <= 3; i++ {
+		srv.AddFile(fileURL, []byte(fmt.Sprintf("content v%d", i)))
+	}
+
+	ctx := context.Background()
+	file := NewSP(srv.Client()).Web().GetFile(fileURL)
+
+	labels := func() []string {
+		resp, err := file.Versions().Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res []string
+		for _, v := range resp.Data() {
+			res = append(res, v.Data().VersionLabel)
+		}
+		return res
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := file.Versions().Select("ID,VersionLabel,Size,Created").Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions := resp.Data()
+		if len(versions) != 2 {
+			t.Fatalf("expected 2 previous versions, got %d", len(versions))
+		}
+		v := versions[0].Data()
+		if v.ID != 512 || v.VersionLabel != "1.0" || v.Size != int64(len("content v1")) || v.Created.IsZero() {
+			t.Errorf("unexpected version: %+v", v)
+		}
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		data, err := file.Versions().GetByID(1024).Download(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "content v2" {
+			t.Errorf("unexpected version content: %s", data)
+		}
+	})
+
+	t.Run("RestoreByLabel", func(t *testing.T) {
+		if err := file.Versions().RestoreByLabel(ctx, "1.0"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := file.Download(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "content v1" {
+			t.Errorf("unexpected restored content: %s", data)
+		}
+		if fmt.Sprint(labels()) != "[1.0 2.0 3.0]" {
+			t.Errorf("unexpected versions: %v", labels())
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := file.Versions().DeleteByLabel(ctx, "2.0"); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Versions().DeleteByID(ctx, 512); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Versions().DeleteMinor(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(labels()) != "[3.0]" {
+			t.Errorf("unexpected versions: %v", labels())
+		}
+		if err := file.Versions().DeleteByLabel(ctx, "2.0"); err == nil {
+			t.Error("deleting missing version should fail")
+		}
+		if err := file.Versions().DeleteByLabel(ctx, "3.0') or ('1"); !gosip.IsNotFound(err) || !strings.Contains(err.Error(), "3.0') or ('1") {
+			t.Errorf("label should be sent as a single literal, got %v", err)
+		}
+		if err := file.Versions().DeleteAll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(labels()) != 0 {
+			t.Errorf("unexpected versions: %v", labels())
+		}
+	})
+}
@@ -26,6 +26,12 @@ func (files *Files) Filter(oDataFilter string) *Files {
 	return files
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (files *Files) FilterExpr(expr ODataExpr) *Files {
+	files.modifiers.AddFilterExpr(expr)
+	return files
+}
+
 // Top adds $top OData modifier
 func (files *Files) Top(oDataTop int) *Files {
 	files.modifiers.AddTop(oDataTop)
@@ -184,7 +184,6 @@ func ensureFolder(ctx context.Context, web *Web, serverRelativeURL string, curre
 	for key, val := range getConfHeaders(web.config) {
 		headers[key] = val
 	}
-	headers["X-Gosip-NoRetry"] = "true"
 	headers["X-Gosip-NoHooks"] = "true"
 	conf := &RequestConfig{
 		Headers: headers,
@@ -199,7 +198,7 @@ func ensureFolder(ctx context.Context, web *Web, serverRelativeURL string, curre
 		getFolder = web.GetFolder
 	}
 
-	data, err := getFolder(currentRelativeURL).Conf(conf).Get(ctx)
+	data, err := getFolder(currentRelativeURL).Conf(conf).Get(gosip.WithNoRetry(ctx))
 	if err != nil {
 		splitted := strings.Split(currentRelativeURL, "/")
 		if len(splitted) == 1 {
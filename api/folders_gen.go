@@ -26,6 +26,12 @@ func (folders *Folders) Filter(oDataFilter string) *Folders {
 	return folders
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (folders *Folders) FilterExpr(expr ODataExpr) *Folders {
+	folders.modifiers.AddFilterExpr(expr)
+	return folders
+}
+
 // Top adds $top OData modifier
 func (folders *Folders) Top(oDataTop int) *Folders {
 	folders.modifiers.AddTop(oDataTop)
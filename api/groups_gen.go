@@ -26,6 +26,12 @@ func (groups *Groups) Filter(oDataFilter string) *Groups {
 	return groups
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (groups *Groups) FilterExpr(expr ODataExpr) *Groups {
+	groups.modifiers.AddFilterExpr(expr)
+	return groups
+}
+
 // Top adds $top OData modifier
 func (groups *Groups) Top(oDataTop int) *Groups {
 	groups.modifiers.AddTop(oDataTop)
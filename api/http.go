@@ -88,6 +88,40 @@ func (client *HTTPClient) Get(ctx context.Context, endpoint string, conf *Reques
 	return io.ReadAll(resp.Body)
 }
 
+// GetStream - generic GET request wrapper which returns response body reader for streaming processing,
+// the reader must be closed by the caller
+func (client *HTTPClient) GetStream(ctx context.Context, endpoint string, conf *RequestConfig) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a request: %w", err)
+	}
+
+	// Apply context
+	if conf != nil && conf.Context != nil {
+		req = req.WithContext(conf.Context)
+	}
+
+	// Default headers
+	req.Header.Set("Accept", "application/json;odata=verbose") // default to SP2013 for backwards compatibility
+
+	// Apply custom headers
+	if conf != nil && conf.Headers != nil {
+		for key, value := range conf.Headers {
+			req.Header.Set(key, value)
+		}
+	}
+
+	resp, err := client.sp.Execute(req)
+	if err != nil {
+		if resp != nil {
+			shut(resp.Body)
+		}
+		return nil, fmt.Errorf("unable to request api: %w", err)
+	}
+
+	return resp.Body, nil
+}
+
 // Post - generic POST request wrapper
 func (client *HTTPClient) Post(ctx context.Context, endpoint string, body io.Reader, conf *RequestConfig) ([]byte, error) {
 	// req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
@@ -260,13 +294,14 @@ func (client *HTTPClient) ProcessQuery(ctx context.Context, endpoint string, bod
 	}
 
 	if res.ErrorInfo != nil {
-		return data, fmt.Errorf(
-			"%s (Code: %d, %s, Correlation ID: %s)",
-			res.ErrorInfo.ErrorMessage,
-			res.ErrorInfo.ErrorCode,
-			res.ErrorInfo.ErrorTypeName,
-			res.TraceCorrelationID,
-		)
+		return data, &gosip.SPError{
+			StatusCode:    resp.StatusCode,
+			Code:          fmt.Sprintf("%d, %s", res.ErrorInfo.ErrorCode, res.ErrorInfo.ErrorTypeName),
+			Message:       res.ErrorInfo.ErrorMessage,
+			CorrelationID: res.TraceCorrelationID,
+			Retries:       gosip.RetryAttempt(resp.Request),
+			Body:          data,
+		}
 	}
 
 	return data, nil
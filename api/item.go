@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/recolabs/gosip"
@@ -79,15 +78,7 @@ func (item *Item) Recycle(ctx context.Context) error {
 // Update updates item's metadata. `body` parameter is byte array representation of JSON string payload relevant to item metadata object.
 func (item *Item) Update(ctx context.Context, body []byte) (ItemResp, error) {
 	body = patchMetadataTypeCB(body, func() string {
-		endpoint := getPriorEndpoint(item.endpoint, "/Items")
-		cacheKey := strings.ToLower(endpoint + "@entitytype")
-		if oDataType, found := storage.Get(cacheKey); found {
-			return oDataType.(string)
-		}
-		list := NewList(item.client, endpoint, nil)
-		oDataType, _ := list.GetEntityType(ctx)
-		storage.Set(cacheKey, oDataType, 0)
-		return oDataType
+		return getItemEntityType(ctx, item.client, item.endpoint)
 	})
 	client := NewHTTPClient(item.client)
 	return client.Update(ctx, item.endpoint, bytes.NewBuffer(body), item.config)
@@ -107,6 +98,15 @@ func (item *Item) Attachments() *Attachments {
 	)
 }
 
+// Versions gets item versions API instance queryable collection for this Item
+func (item *Item) Versions() *ItemVersions {
+	return NewItemVersions(
+		item.client,
+		fmt.Sprintf("%s/Versions", item.endpoint),
+		item.config,
+	)
+}
+
 // ParentList gets this Item's Lists API object
 func (item *Item) ParentList() *List {
 	return NewList(
@@ -0,0 +1,48 @@
+// Code generated by `ggen -ent ItemVersion -conf -mods Select,Expand -helpers Data,Normalized,ToMap`; DO NOT EDIT.
+
+package api
+
+import (
+	"encoding/json"
+)
+
+// Conf receives custom request config definition, e.g. custom headers, custom OData mod
+func (itemVersion *ItemVersion) Conf(config *RequestConfig) *ItemVersion {
+	itemVersion.config = config
+	return itemVersion
+}
+
+// Select adds $select OData modifier
+func (itemVersion *ItemVersion) Select(oDataSelect string) *ItemVersion {
+	itemVersion.modifiers.AddSelect(oDataSelect)
+	return itemVersion
+}
+
+// Expand adds $expand OData modifier
+func (itemVersion *ItemVersion) Expand(oDataExpand string) *ItemVersion {
+	itemVersion.modifiers.AddExpand(oDataExpand)
+	return itemVersion
+}
+
+/* Response helpers */
+
+// Data response helper
+func (itemVersionResp *ItemVersionResp) Data() *ItemVersionInfo {
+	data := NormalizeODataItem(*itemVersionResp)
+	res := &ItemVersionInfo{}
+	json.Unmarshal(data, &res)
+	return res
+}
+
+// Normalized returns normalized body
+func (itemVersionResp *ItemVersionResp) Normalized() []byte {
+	return NormalizeODataItem(*itemVersionResp)
+}
+
+// ToMap unmarshals response to generic map
+func (itemVersionResp *ItemVersionResp) ToMap() map[string]interface{} {
+	data := NormalizeODataItem(*itemVersionResp)
+	var res map[string]interface{}
+	_ = json.Unmarshal(data, &res)
+	return res
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/recolabs/gosip"
+)
+
+//go:generate ggen -ent ItemVersions -item ItemVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized,ToMap
+//go:generate ggen -ent ItemVersion -conf -mods Select,Expand -helpers Data,Normalized,ToMap
+
+// ItemVersions represent SharePoint List Item Versions API queryable collection struct
+// Always use NewItemVersions constructor instead of &ItemVersions{}
+type ItemVersions struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// ItemVersion represent SharePoint List Item Version API queryable object struct
+// Always use NewItemVersion constructor instead of &ItemVersion{}
+type ItemVersion struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// ItemVersionInfo - item version API response payload structure, use `ToMap` to get version field values
+type ItemVersionInfo struct {
+	VersionID        int       `json:"VersionId"`
+	VersionLabel     string    `json:"VersionLabel"`
+	IsCurrentVersion bool      `json:"IsCurrentVersion"`
+	Created          time.Time `json:"Created"`
+	CreatedBy        *UserInfo `json:"CreatedBy"` // requires `Expand("CreatedBy")`
+}
+
+// ItemVersionsResp - item versions response type with helper processor methods
+type ItemVersionsResp []byte
+
+// ItemVersionResp - item version response type with helper processor methods
+type ItemVersionResp []byte
+
+// NewItemVersions - ItemVersions struct constructor function
+func NewItemVersions(client *gosip.SPClient, endpoint string, config *RequestConfig) *ItemVersions {
+	return &ItemVersions{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// NewItemVersion - ItemVersion struct constructor function
+func NewItemVersion(client *gosip.SPClient, endpoint string, config *RequestConfig) *ItemVersion {
+	return &ItemVersion{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (itemVersions *ItemVersions) ToURL() string {
+	return toURL(itemVersions.endpoint, itemVersions.modifiers)
+}
+
+// Get gets item versions collection including the current version, the latest version goes first
+func (itemVersions *ItemVersions) Get(ctx context.Context) (ItemVersionsResp, error) {
+	client := NewHTTPClient(itemVersions.client)
+	return client.Get(ctx, itemVersions.ToURL(), itemVersions.config)
+}
+
+// GetByID gets item version API object by version ID, e.g. 512 for version 1.0
+func (itemVersions *ItemVersions) GetByID(versionID int) *ItemVersion {
+	return NewItemVersion(
+		itemVersions.client,
+		fmt.Sprintf("%s(%d)", itemVersions.endpoint, versionID),
+		itemVersions.config,
+	)
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (itemVersion *ItemVersion) ToURL() string {
+	return toURL(itemVersion.endpoint, itemVersion.modifiers)
+}
+
+// Get gets item version with the version field values
+func (itemVersion *ItemVersion) Get(ctx context.Context) (ItemVersionResp, error) {
+	client := NewHTTPClient(itemVersion.client)
+	return client.Get(ctx, itemVersion.ToURL(), itemVersion.config)
+}
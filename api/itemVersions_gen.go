@@ -0,0 +1,75 @@
+// Code generated by `ggen -ent ItemVersions -item ItemVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized,ToMap`; DO NOT EDIT.
+
+package api
+
+import (
+	"encoding/json"
+)
+
+// Conf receives custom request config definition, e.g. custom headers, custom OData mod
+func (itemVersions *ItemVersions) Conf(config *RequestConfig) *ItemVersions {
+	itemVersions.config = config
+	return itemVersions
+}
+
+// Select adds $select OData modifier
+func (itemVersions *ItemVersions) Select(oDataSelect string) *ItemVersions {
+	itemVersions.modifiers.AddSelect(oDataSelect)
+	return itemVersions
+}
+
+// Expand adds $expand OData modifier
+func (itemVersions *ItemVersions) Expand(oDataExpand string) *ItemVersions {
+	itemVersions.modifiers.AddExpand(oDataExpand)
+	return itemVersions
+}
+
+// Filter adds $filter OData modifier
+func (itemVersions *ItemVersions) Filter(oDataFilter string) *ItemVersions {
+	itemVersions.modifiers.AddFilter(oDataFilter)
+	return itemVersions
+}
+
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (itemVersions *ItemVersions) FilterExpr(expr ODataExpr) *ItemVersions {
+	itemVersions.modifiers.AddFilterExpr(expr)
+	return itemVersions
+}
+
+// Top adds $top OData modifier
+func (itemVersions *ItemVersions) Top(oDataTop int) *ItemVersions {
+	itemVersions.modifiers.AddTop(oDataTop)
+	return itemVersions
+}
+
+// OrderBy adds $orderby OData modifier
+func (itemVersions *ItemVersions) OrderBy(oDataOrderBy string, ascending bool) *ItemVersions {
+	itemVersions.modifiers.AddOrderBy(oDataOrderBy, ascending)
+	return itemVersions
+}
+
+/* Response helpers */
+
+// Data response helper
+func (itemVersionsResp *ItemVersionsResp) Data() []ItemVersionResp {
+	collection, _ := normalizeODataCollection(*itemVersionsResp)
+	itemVersions := []ItemVersionResp{}
+	for _, item := range collection {
+		itemVersions = append(itemVersions, ItemVersionResp(item))
+	}
+	return itemVersions
+}
+
+// Normalized returns normalized body
+func (itemVersionsResp *ItemVersionsResp) Normalized() []byte {
+	normalized, _ := NormalizeODataCollection(*itemVersionsResp)
+	return normalized
+}
+
+// ToMap unmarshals response to generic map
+func (itemVersionsResp *ItemVersionsResp) ToMap() []map[string]interface{} {
+	data, _ := NormalizeODataCollection(*itemVersionsResp)
+	var res []map[string]interface{}
+	_ = json.Unmarshal(data, &res)
+	return res
+}
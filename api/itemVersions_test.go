@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestItemVersions(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddList("Versioned", 100); err != nil {
+		t.Fatal(err)
+	}
+	id, err := srv.AddItem("Versioned", map[string]interface{}{"Title": "Draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	item := NewSP(srv.Client()).Web().Lists().GetByTitle("Versioned").Items().GetByID(id)
+	for _, title := range []string{"Review", "Final"} {
+		if _, err := item.Update(ctx, []byte(`{"Title":"`+title+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := item.Versions().Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions := resp.Data()
+		if len(versions) != 3 {
+			t.Fatalf("expected 3 versions, got %d", len(versions))
+		}
+		expected := []string{"Final", "Review", "Draft"}
+		for i, v := range versions {
+			if v.ToMap()["Title"] != expected[i] {
+				t.Errorf("unexpected version %d title: %v", i, v.ToMap()["Title"])
+			}
+		}
+		if current := versions[0].Data(); !current.IsCurrentVersion || current.VersionLabel != "3.0" {
+			t.Errorf("unexpected current version: %+v", current)
+		}
+		if first := versions[2].Data(); first.IsCurrentVersion || first.VersionID != 512 {
+			t.Errorf("unexpected first version: %+v", first)
+		}
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		resp, err := item.Versions().GetByID(1024).Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data().VersionLabel != "2.0" || resp.ToMap()["Title"] != "Review" {
+			t.Errorf("unexpected version: %v", resp.ToMap())
+		}
+	})
+}
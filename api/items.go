@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/recolabs/gosip"
 )
@@ -52,7 +53,8 @@ func (items *Items) Get(ctx context.Context) (ItemsResp, error) {
 }
 
 // GetAll gets all items in a list using internal page helper. The use case of the method is getting all the content from large lists.
-// Method ignores custom sorting and filtering as not supported for the large lists due to throttling limitations.
+// Method ignores custom sorting and filtering as not supported for the large lists due to throttling limitations,
+// use `Large()` query to filter and sort large lists client-side.
 func (items *Items) GetAll(ctx context.Context) ([]ItemResp, error) {
 	return getAll(ctx, nil, nil, items)
 }
@@ -60,15 +62,7 @@ func (items *Items) GetAll(ctx context.Context) ([]ItemResp, error) {
 // Add adds new item in this list. `body` parameter is byte array representation of JSON string payload relevant to item metadata object.
 func (items *Items) Add(ctx context.Context, body []byte) (ItemResp, error) {
 	body = patchMetadataTypeCB(body, func() string {
-		endpoint := getPriorEndpoint(items.endpoint, "/Items")
-		cacheKey := strings.ToLower(endpoint + "@entitytype")
-		if oDataType, found := storage.Get(cacheKey); found {
-			return oDataType.(string)
-		}
-		list := NewList(items.client, endpoint, nil)
-		oDataType, _ := list.GetEntityType(ctx)
-		storage.Set(cacheKey, oDataType, 0)
-		return oDataType
+		return getItemEntityType(ctx, items.client, items.endpoint)
 	})
 	client := NewHTTPClient(items.client)
 	return client.Post(ctx, items.endpoint, bytes.NewBuffer(body), items.config)
@@ -111,11 +105,21 @@ func (items *Items) GetByCAML(ctx context.Context, caml string) (ItemsResp, erro
 	return client.Post(ctx, apiURL.String(), bytes.NewBuffer(body), items.config)
 }
 
-// ToDo:
-// Batch
-
 // Helper methods
 
+// getItemEntityType resolves list item entity type name for an items endpoint, the value is cached per list
+func getItemEntityType(ctx context.Context, client *gosip.SPClient, endpoint string) string {
+	listEndpoint := getPriorEndpoint(endpoint, "/Items")
+	cacheKey := strings.ToLower(listEndpoint + "@entitytype")
+	if oDataType, _, found := client.GetCache().Get(cacheKey); found {
+		return oDataType
+	}
+	list := NewList(client, listEndpoint, nil)
+	oDataType, _ := list.GetEntityType(ctx)
+	client.GetCache().Set(cacheKey, oDataType, 5*time.Minute)
+	return oDataType
+}
+
 func getAll(ctx context.Context, res []ItemResp, cur ItemsResp, items *Items) ([]ItemResp, error) {
 	if res == nil && cur == nil {
 		itemsCopy := NewItems(items.client, items.endpoint, items.config)
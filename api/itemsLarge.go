@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/recolabs/gosip/api/odata"
+)
+
+// maxLargeWindowSize is the list view threshold, larger windows are requested in pages
+const maxLargeWindowSize = 5000
+
+// LargeItems represent threshold-safe large list items query. Items are requested by indexed `Id` windows
+// which never exceed the list view threshold, filters and sorts by any fields are applied client-side across windows.
+// Use it for lists with more than 5000 items where `Items.Filter` and `Items.OrderBy` by non-indexed fields fail.
+// Always use NewLargeItems constructor instead of &LargeItems{}
+type LargeItems struct {
+	items       *Items
+	windowSize  int
+	parallelism int
+	filter      odata.Expr
+	orderBy     []largeItemsOrder
+}
+
+type largeItemsOrder struct {
+	field     string
+	ascending bool
+}
+
+// largeItem is an item matched within ID window
+type largeItem struct {
+	resp  ItemResp
+	props map[string]interface{}
+}
+
+// NewLargeItems - LargeItems struct constructor function, `$select` and `$expand` modifiers of the items are used
+// in window requests, other modifiers are ignored
+func NewLargeItems(items *Items) *LargeItems {
+	return &LargeItems{
+		items:       items,
+		windowSize:  maxLargeWindowSize,
+		parallelism: 1,
+	}
+}
+
+// Large gets threshold-safe large list items query
+func (items *Items) Large() *LargeItems {
+	return NewLargeItems(items)
+}
+
+// WindowSize sets ID window size, default and maximum page size is 5000
+func (large *LargeItems) WindowSize(size int) *LargeItems {
+	if size > 0 {
+		large.windowSize = size
+	}
+	return large
+}
+
+// Parallel sets the number of ID windows requested concurrently, items are still passed in ID windows order
+func (large *LargeItems) Parallel(parallelism int) *LargeItems {
+	if parallelism > 0 {
+		large.parallelism = parallelism
+	}
+	return large
+}
+
+// Filter sets client-side filter expression, the fields used in the expression must be selected
+func (large *LargeItems) Filter(expr odata.Expr) *LargeItems {
+	large.filter = expr
+	return large
+}
+
+// OrderBy adds client-side sort field, can be called multiple times. Sorting requires all matched items
+// to be received before the first one is passed, without sorting items are passed in ID order window by window.
+func (large *LargeItems) OrderBy(field string, ascending bool) *LargeItems {
+	large.orderBy = append(large.orderBy, largeItemsOrder{field: field, ascending: ascending})
+	return large
+}
+
+// Get gets all matched items
+func (large *LargeItems) Get(ctx context.Context) ([]ItemResp, error) {
+	var res []ItemResp
+	err := large.Stream(ctx, func(item ItemResp) error {
+		res = append(res, item)
+		return nil
+	})
+	return res, err
+}
+
+// Stream passes matched items to the callback, return ErrStopStream from the callback to stop with no error
+func (large *LargeItems) Stream(ctx context.Context, callback func(item ItemResp) error) error {
+	if err := large.filter.Err(); err != nil {
+		return err
+	}
+
+	maxID, err := large.getMaxID(ctx)
+	if err != nil {
+		return err
+	}
+
+	emit := func(item largeItem) error { return callback(item.resp) }
+	var sorted []largeItem
+	if len(large.orderBy) > 0 {
+		emit = func(item largeItem) error {
+			sorted = append(sorted, item)
+			return nil
+		}
+	}
+
+	if large.parallelism > 1 {
+		err = large.streamParallel(ctx, maxID, emit)
+	} else {
+		for lo := 1; lo <= maxID && err == nil; lo += large.windowSize {
+			err = large.streamWindow(ctx, lo, lo+large.windowSize-1, emit)
+		}
+	}
+
+	if err == nil && len(large.orderBy) > 0 {
+		large.sort(sorted)
+		for _, item := range sorted {
+			if err = callback(item.resp); err != nil {
+				break
+			}
+		}
+	}
+
+	if errors.Is(err, ErrStopStream) {
+		return nil
+	}
+	return err
+}
+
+// streamParallel requests ID windows concurrently passing items in windows order,
+// not more than parallelism windows are buffered at a time
+func (large *LargeItems) streamParallel(ctx context.Context, maxID int, emit func(item largeItem) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type windowResult struct {
+		items []largeItem
+		err   error
+	}
+
+	var windows []chan windowResult
+	for lo := 1; lo <= maxID; lo += large.windowSize {
+		windows = append(windows, make(chan windowResult, 1))
+	}
+
+	slots := make(chan struct{}, large.parallelism)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range windows {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				lo := 1 + i*large.windowSize
+				res := windowResult{}
+				res.err = large.streamWindow(ctx, lo, lo+large.windowSize-1, func(item largeItem) error {
+					res.items = append(res.items, item)
+					return nil
+				})
+				windows[i] <- res
+			}(i)
+		}
+	}()
+
+	var err error
+	for _, window := range windows {
+		var res windowResult
+		select {
+		case res = <-window:
+			<-slots
+		case <-ctx.Done():
+			res.err = ctx.Err()
+		}
+		if err = res.err; err != nil {
+			break
+		}
+		for _, item := range res.items {
+			if err = emit(item); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// streamWindow requests items with IDs in [lo, hi] range passing matched items
+func (large *LargeItems) streamWindow(ctx context.Context, lo int, hi int, emit func(item largeItem) error) error {
+	top := large.windowSize
+	if top > maxLargeWindowSize {
+		top = maxLargeWindowSize
+	}
+	query := large.query().
+		Filter(fmt.Sprintf("Id ge %d and Id le %d", lo, hi)).
+		Top(top)
+
+	decode := !large.filter.IsEmpty() || len(large.orderBy) > 0
+	return streamODataCollection(ctx, large.items.client, query.ToURL(), large.items.config, func(data []byte) error {
+		item := largeItem{resp: ItemResp(data)}
+		if decode {
+			if err := json.Unmarshal(data, &item.props); err != nil {
+				return err
+			}
+			if !large.filter.Match(item.props) {
+				return nil
+			}
+		}
+		return emit(item)
+	})
+}
+
+// getMaxID gets the last item ID in the list, IDs are sorted by the index so the request is threshold-safe
+func (large *LargeItems) getMaxID(ctx context.Context) (int, error) {
+	resp, err := NewItems(large.items.client, large.items.endpoint, large.items.config).
+		Select("Id").OrderBy("Id", false).Top(1).Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	data := resp.Data()
+	if len(data) == 0 {
+		return 0, nil
+	}
+	return data[0].Data().ID, nil
+}
+
+// query creates window items query with the original items projection
+func (large *LargeItems) query() *Items {
+	query := NewItems(large.items.client, large.items.endpoint, large.items.config)
+	mods := large.items.modifiers.Get()
+	if sel, ok := mods["$select"]; ok {
+		query.Select(sel)
+	}
+	if exp, ok := mods["$expand"]; ok {
+		query.Expand(exp)
+	}
+	return query
+}
+
+// sort sorts items by order fields
+func (large *LargeItems) sort(items []largeItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, o := range large.orderBy {
+			c := compareLargeValues(fieldByPath(items[i].props, o.field), fieldByPath(items[j].props, o.field))
+			if c != 0 {
+				return (c < 0) == o.ascending
+			}
+		}
+		return false
+	})
+}
+
+// fieldByPath gets item property by `Field` or `Lookup/Field` path
+func fieldByPath(props map[string]interface{}, path string) interface{} {
+	var cur interface{} = props
+	for _, part := range strings.Split(path, "/") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// compareLargeValues compares JSON values for sorting, empty values go first
+func compareLargeValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return strings.Compare(strconv.FormatBool(av), strconv.FormatBool(bv))
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(strings.ToLower(av), strings.ToLower(bv))
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/recolabs/gosip/api/odata"
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestLargeItems(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddList("Large", 100); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 25; i++ {
+		status := "Closed"
+		if i%3 == 0 {
+			status = "Open"
+		}
+		item := map[string]interface{}{"Title": fmt.Sprintf("Item %02d", i), "Status": status, "Priority": i % 4}
+		if _, err := srv.AddItem("Large", item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	items := NewSP(srv.Client()).Web().Lists().GetByTitle("Large").Items().Select("Id,Title,Status,Priority")
+
+	titles := func(resp []ItemResp) []string {
+		var res []string
+		for _, item := range resp {
+			res = append(res, item.Data().Title)
+		}
+		return res
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		for _, parallelism := range []int{1, 3} {
+			resp, err := items.Large().
+				WindowSize(4).
+				Parallel(parallelism).
+				Filter(odata.Eq("Status", "Open")).
+				Get(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := "[Item 03 Item 06 Item 09 Item 12 Item 15 Item 18 Item 21 Item 24]"
+			if fmt.Sprint(titles(resp)) != expected {
+				t.Errorf("parallelism %d: unexpected items: %v", parallelism, titles(resp))
+			}
+		}
+	})
+
+	t.Run("OrderBy", func(t *testing.T) {
+		resp, err := items.Large().
+			WindowSize(10).
+			Filter(odata.And(odata.Eq("Status", "Open"), odata.Ne("Priority", 0))).
+			OrderBy("Priority", false).
+			OrderBy("Title", true).
+			Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "[Item 03 Item 15 Item 06 Item 18 Item 09 Item 21]"
+		if fmt.Sprint(titles(resp)) != expected {
+			t.Errorf("unexpected items: %v", titles(resp))
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		cnt := 0
+		err := items.Large().WindowSize(3).Parallel(2).Stream(ctx, func(item ItemResp) error {
+			cnt++
+			if cnt == 5 {
+				return ErrStopStream
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		if cnt != 5 {
+			t.Errorf("stream should be stopped after 5 items, got %d", cnt)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if err := srv.AddList("Empty", 100); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := NewSP(srv.Client()).Web().Lists().GetByTitle("Empty").Items().Large().Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp) != 0 {
+			t.Errorf("unexpected items: %v", titles(resp))
+		}
+	})
+}
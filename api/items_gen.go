@@ -30,6 +30,12 @@ func (items *Items) Filter(oDataFilter string) *Items {
 	return items
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (items *Items) FilterExpr(expr ODataExpr) *Items {
+	items.modifiers.AddFilterExpr(expr)
+	return items
+}
+
 // Top adds $top OData modifier
 func (items *Items) Top(oDataTop int) *Items {
 	items.modifiers.AddTop(oDataTop)
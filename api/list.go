@@ -272,9 +272,6 @@ func (list *List) RenderListData(ctx context.Context, viewXML string) (RenderLis
 	return data, nil
 }
 
-// ToDo:
-// RenderListDataAsStream ...
-
 // Roles gets list's Roles API instance queryable collection
 func (list *List) Roles() *Roles {
 	return NewRoles(list.client, list.endpoint, list.config)
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RenderListDataOptions - RenderListDataAsStream RenderOptions flags
+type RenderListDataOptions int
+
+// RenderListDataAsStream RenderOptions flags, can be combined, e.g. `RenderListData | RenderListSchema`
+const (
+	RenderContextInfo                 RenderListDataOptions = 1
+	RenderListData                    RenderListDataOptions = 2
+	RenderListSchema                  RenderListDataOptions = 4
+	RenderMenuView                    RenderListDataOptions = 8
+	RenderListContentType             RenderListDataOptions = 16
+	RenderFileSystemItemID            RenderListDataOptions = 32
+	RenderClientFormSchema            RenderListDataOptions = 64
+	RenderQuickLaunch                 RenderListDataOptions = 128
+	RenderSpotlight                   RenderListDataOptions = 256
+	RenderVisualization               RenderListDataOptions = 512
+	RenderViewMetadata                RenderListDataOptions = 1024
+	RenderDisableAutoHyperlink        RenderListDataOptions = 2048
+	RenderEnableMediaTAUrls           RenderListDataOptions = 4096
+	RenderParentInfo                  RenderListDataOptions = 8192
+	RenderPageContextInfo             RenderListDataOptions = 16384
+	RenderClientSideComponentManifest RenderListDataOptions = 32768
+)
+
+// RenderListDataParams - RenderListDataAsStream parameters
+type RenderListDataParams struct {
+	RenderOptions           RenderListDataOptions `json:"RenderOptions,omitempty"`
+	ViewXML                 string                `json:"ViewXml,omitempty"`
+	FolderServerRelativeURL string                `json:"FolderServerRelativeUrl,omitempty"`
+	OverrideViewXML         string                `json:"OverrideViewXml,omitempty"`
+	Paging                  string                `json:"Paging,omitempty"`
+	DatesInUtc              bool                  `json:"DatesInUtc,omitempty"`
+	AddRequiredFields       bool                  `json:"AddRequiredFields,omitempty"`
+	ReplaceGroup            bool                  `json:"ReplaceGroup,omitempty"`
+
+	// Overrides are sent as `overrideParameters`, e.g. {"ViewId": "...", "SortField": "Title"}
+	Overrides map[string]interface{} `json:"-"`
+	// NextHref is a page token returned by the previous page, e.g. `?Paged=TRUE&p_ID=30&PageFirstRow=31`
+	NextHref string `json:"-"`
+}
+
+// RenderListDataField - RenderListDataAsStream list schema field
+type RenderListDataField struct {
+	ID            string `json:"ID"`
+	Name          string `json:"Name"`
+	RealFieldName string `json:"RealFieldName"`
+	DisplayName   string `json:"DisplayName"`
+	FieldType     string `json:"FieldType"`
+	Type          string `json:"Type"`
+	ReadOnly      string `json:"ReadOnly"`
+	Hidden        string `json:"Hidden"`
+}
+
+// RenderListDataRow - RenderListDataAsStream row with formatted values
+type RenderListDataRow map[string]interface{}
+
+// RenderListDataUser - RenderListDataAsStream user field value
+type RenderListDataUser struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Email      string `json:"email"`
+	Sip        string `json:"sip"`
+	Department string `json:"department"`
+	JobTitle   string `json:"jobTitle"`
+}
+
+// RenderListDataLookup - RenderListDataAsStream lookup field value
+type RenderListDataLookup struct {
+	LookupID    int    `json:"lookupId"`
+	LookupValue string `json:"lookupValue"`
+}
+
+// RenderListDataTerm - RenderListDataAsStream managed metadata field value
+type RenderListDataTerm struct {
+	Label    string `json:"Label"`
+	TermID   string `json:"TermID"`
+	WssID    string `json:"WssId"`
+	TermGUID string `json:"TermGuid"`
+}
+
+// RenderListDataPage - RenderListDataAsStream page with rows and schema
+type RenderListDataPage struct {
+	Rows       []RenderListDataRow
+	Fields     []RenderListDataField
+	FirstRow   int
+	LastRow    int
+	RowLimit   int
+	NextHref   string
+	PrevHref   string
+	FilterLink string
+}
+
+// RenderListDataStreamResp - RenderListDataAsStream method response type with helper processor methods
+type RenderListDataStreamResp []byte
+
+// RenderListDataAsStream renders list data page, the method returns formatted values for user,
+// lookup and managed metadata fields. Use params.NextHref from a previous page to get the next one.
+func (list *List) RenderListDataAsStream(ctx context.Context, params *RenderListDataParams) (RenderListDataStreamResp, error) {
+	if params == nil {
+		params = &RenderListDataParams{}
+	}
+
+	apiURL, err := url.Parse(list.endpoint + "/RenderListDataAsStream")
+	if err != nil {
+		return nil, err
+	}
+	if params.NextHref != "" {
+		nextHref := params.NextHref
+		if i := strings.Index(nextHref, "?"); i >= 0 {
+			nextHref = nextHref[i+1:]
+		}
+		apiURL.RawQuery = nextHref
+	}
+
+	p := *params
+	p.ViewXML = TrimMultiline(p.ViewXML)
+	payload := map[string]interface{}{"parameters": p}
+	if len(params.Overrides) > 0 {
+		payload["overrideParameters"] = params.Overrides
+	}
+	body, _ := json.Marshal(payload)
+
+	client := NewHTTPClient(list.client)
+	return client.Post(ctx, apiURL.String(), bytes.NewBuffer(body), list.config)
+}
+
+// RenderListDataAsStreamPages renders all list data pages following NextHref tokens,
+// return ErrStopStream from the callback to stop with no error
+func (list *List) RenderListDataAsStreamPages(ctx context.Context, params *RenderListDataParams, callback func(page *RenderListDataPage) error) error {
+	p := RenderListDataParams{}
+	if params != nil {
+		p = *params
+	}
+	if p.RenderOptions == 0 {
+		p.RenderOptions = RenderListData
+	}
+	for {
+		resp, err := list.RenderListDataAsStream(ctx, &p)
+		if err != nil {
+			return err
+		}
+		page, err := resp.Page()
+		if err != nil {
+			return err
+		}
+		if err := callback(page); err != nil {
+			if errors.Is(err, ErrStopStream) {
+				return nil
+			}
+			return err
+		}
+		if page.NextHref == "" || page.NextHref == p.NextHref {
+			return nil
+		}
+		p.NextHref = page.NextHref
+	}
+}
+
+/* Response helpers */
+
+// Page parses response rows, paging info and list schema. RenderOptions with ListData only
+// produce a plain list data object, other options wrap it into `ListData` along with `ListSchema`.
+func (resp *RenderListDataStreamResp) Page() (*RenderListDataPage, error) {
+	type listData struct {
+		Row        []RenderListDataRow `json:"Row"`
+		FirstRow   int                 `json:"FirstRow"`
+		LastRow    int                 `json:"LastRow"`
+		RowLimit   int                 `json:"RowLimit"`
+		NextHref   string              `json:"NextHref"`
+		PrevHref   string              `json:"PrevHref"`
+		FilterLink string              `json:"FilterLink"`
+	}
+	res := &struct {
+		listData
+		ListData   *listData `json:"ListData"`
+		ListSchema *struct {
+			Field []RenderListDataField `json:"Field"`
+		} `json:"ListSchema"`
+	}{}
+	if err := json.Unmarshal(*resp, res); err != nil {
+		return nil, err
+	}
+	data := res.listData
+	if res.ListData != nil {
+		data = *res.ListData
+	}
+	page := &RenderListDataPage{
+		Rows:       data.Row,
+		FirstRow:   data.FirstRow,
+		LastRow:    data.LastRow,
+		RowLimit:   data.RowLimit,
+		NextHref:   data.NextHref,
+		PrevHref:   data.PrevHref,
+		FilterLink: data.FilterLink,
+	}
+	if res.ListSchema != nil {
+		page.Fields = res.ListSchema.Field
+	}
+	return page, nil
+}
+
+// ID gets row item ID
+func (row RenderListDataRow) ID() int {
+	id, _ := strconv.Atoi(row.String("ID"))
+	return id
+}
+
+// String gets field value as string, numbers and booleans are formatted
+func (row RenderListDataRow) String(field string) string {
+	switch v := row[field].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Formatted gets field formatted value, e.g. `Created.FriendlyDisplay` or `Amount.`, falls back to raw value
+func (row RenderListDataRow) Formatted(field string) string {
+	for _, key := range []string{field + ".", field + ".FriendlyDisplay"} {
+		if v, ok := row[key]; ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return row.String(field)
+}
+
+// Users gets user or multi-user field values
+func (row RenderListDataRow) Users(field string) []RenderListDataUser {
+	var res []RenderListDataUser
+	row.decode(field, &res)
+	return res
+}
+
+// Lookups gets lookup or multi-lookup field values
+func (row RenderListDataRow) Lookups(field string) []RenderListDataLookup {
+	var res []RenderListDataLookup
+	row.decode(field, &res)
+	return res
+}
+
+// Terms gets managed metadata field values, single value fields are returned as one element slice
+func (row RenderListDataRow) Terms(field string) []RenderListDataTerm {
+	var res []RenderListDataTerm
+	if _, ok := row[field].(map[string]interface{}); ok {
+		term := RenderListDataTerm{}
+		row.decode(field, &term)
+		return []RenderListDataTerm{term}
+	}
+	row.decode(field, &res)
+	return res
+}
+
+// decode converts complex field value to typed value
+func (row RenderListDataRow) decode(field string, v interface{}) {
+	value, ok := row[field]
+	if !ok || value == nil || value == "" {
+		return
+	}
+	data, _ := json.Marshal(value)
+	_ = json.Unmarshal(data, v)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestRenderListDataAsStream(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddList("Render", 100); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 7; i++ {
+		if _, err := srv.AddItem("Render", map[string]interface{}{"Title": fmt.Sprintf("Item %d", i), "Priority": i % 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	list := NewSP(srv.Client()).Web().Lists().GetByTitle("Render")
+	viewXML := `
+		<View>
+			<Query><Where><Eq><FieldRef Name="Priority" /><Value Type="Number">1</Value></Eq></Where></Query>
+			<ViewFields><FieldRef Name="Title" /><FieldRef Name="Priority" /></ViewFields>
+			<RowLimit Paged="TRUE">2</RowLimit>
+		</View>
+	`
+
+	t.Run("Page", func(t *testing.T) {
+		resp, err := list.RenderListDataAsStream(ctx, &RenderListDataParams{
+			RenderOptions: RenderListData | RenderListSchema,
+			ViewXML:       viewXML,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := resp.Page()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Rows) != 2 || page.Rows[0].ID() != 1 || page.Rows[1].String("Title") != "Item 3" {
+			t.Errorf("unexpected rows: %v", page.Rows)
+		}
+		if page.NextHref == "" || page.FirstRow != 1 || page.LastRow != 2 {
+			t.Errorf("unexpected paging: %+v", page)
+		}
+		if len(page.Fields) != 2 || page.Fields[0].Name != "Title" {
+			t.Errorf("unexpected fields: %+v", page.Fields)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		var titles []string
+		pages := 0
+		err := list.RenderListDataAsStreamPages(ctx, &RenderListDataParams{ViewXML: viewXML}, func(page *RenderListDataPage) error {
+			pages++
+			for _, row := range page.Rows {
+				titles = append(titles, row.Formatted("Title"))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pages != 2 || fmt.Sprint(titles) != "[Item 1 Item 3 Item 5 Item 7]" {
+			t.Errorf("unexpected pages: %d, %v", pages, titles)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		pages := 0
+		err := list.RenderListDataAsStreamPages(ctx, &RenderListDataParams{ViewXML: viewXML}, func(page *RenderListDataPage) error {
+			pages++
+			return ErrStopStream
+		})
+		if err != nil || pages != 1 {
+			t.Errorf("stream should be stopped after the first page: %d, %v", pages, err)
+		}
+	})
+}
+
+func TestRenderListDataRow(t *testing.T) {
+	resp := RenderListDataStreamResp(`{
+		"Row": [{
+			"ID": "5",
+			"Created": "1/2/2020 3:04 AM",
+			"Created.FriendlyDisplay": "1|0|2020-01-02",
+			"Amount": "1,234.5",
+			"Amount.": "1234.5",
+			"Editor": [{"id": "7", "title": "John", "email": "john@contoso.com"}],
+			"Category": [{"lookupId": 2, "lookupValue": "Two"}],
+			"Region": {"Label": "EMEA", "TermID": "d1c0c2a5-2b2a-4d6c-8e4c-0b3a1a0c5e1f"},
+			"Tags": [{"Label": "a", "TermID": "1"}, {"Label": "b", "TermID": "2"}]
+		}],
+		"FirstRow": 1,
+		"LastRow": 1
+	}`)
+	page, err := resp.Page()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := page.Rows[0]
+	if row.ID() != 5 {
+		t.Errorf("unexpected ID: %d", row.ID())
+	}
+	if row.Formatted("Amount") != "1234.5" || row.Formatted("Created") != "1|0|2020-01-02" || row.Formatted("ID") != "5" {
+		t.Error("unexpected formatted values")
+	}
+	if users := row.Users("Editor"); len(users) != 1 || users[0].Email != "john@contoso.com" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+	if lookups := row.Lookups("Category"); len(lookups) != 1 || lookups[0].LookupID != 2 {
+		t.Errorf("unexpected lookups: %+v", lookups)
+	}
+	if terms := row.Terms("Region"); len(terms) != 1 || terms[0].Label != "EMEA" {
+		t.Errorf("unexpected term: %+v", terms)
+	}
+	if terms := row.Terms("Tags"); len(terms) != 2 || terms[1].Label != "b" {
+		t.Errorf("unexpected terms: %+v", terms)
+	}
+	if row.Users("Missing") != nil {
+		t.Error("missing field should have no users")
+	}
+}
@@ -26,6 +26,12 @@ func (lists *Lists) Filter(oDataFilter string) *Lists {
 	return lists
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (lists *Lists) FilterExpr(expr ODataExpr) *Lists {
+	lists.modifiers.AddFilterExpr(expr)
+	return lists
+}
+
 // Top adds $top OData modifier
 func (lists *Lists) Top(oDataTop int) *Lists {
 	lists.modifiers.AddTop(oDataTop)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"context"
@@ -69,27 +68,17 @@ func csomResponse(ctx context.Context, httpClient *HTTPClient, siteURL string, c
 		return nil, err
 	}
 
-	jsomResp, err := httpClient.ProcessQuery(ctx, siteURL, bytes.NewBuffer([]byte(csomPkg)), config)
-	if err != nil {
+	var jsomResp []byte
+	for retry := 0; ; retry++ {
+		jsomResp, err = httpClient.ProcessQuery(ctx, siteURL, bytes.NewBuffer([]byte(csomPkg)), config)
 		// Retry Terms update conflicts
-		if strings.Contains(err.Error(), "Term update failed because of save conflict") {
-			if config == nil {
-				config = &RequestConfig{}
-			}
-			if config.Headers == nil {
-				config.Headers = map[string]string{}
-			}
-			retryStr, ok := config.Headers["X-Gosip-Retry"]
-			if !ok {
-				retryStr = "0"
-			}
-			retry, _ := strconv.Atoi(retryStr)
-			config.Headers["X-Gosip-Retry"] = strconv.Itoa(retry + 1)
-			if retry+1 <= 5 {
-				return csomResponse(ctx, httpClient, siteURL, config, csomBuilder)
-			}
+		if err != nil && retry < 5 && strings.Contains(err.Error(), "Term update failed because of save conflict") {
+			continue
 		}
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+		break
 	}
 
 	var jsomRespArr []interface{}
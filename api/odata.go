@@ -58,6 +58,16 @@ func (oData *ODataMods) AddFilter(values string) *ODataMods {
 	return oData
 }
 
+// ODataExpr is a typed OData expression rendering to valid filter syntax, see api/odata package
+type ODataExpr interface {
+	String() string
+}
+
+// AddFilterExpr adds $filter OData modifier from typed expression
+func (oData *ODataMods) AddFilterExpr(expr ODataExpr) *ODataMods {
+	return oData.AddFilter(expr.String())
+}
+
 // AddSkip adds $skiptoken OData modifier
 func (oData *ODataMods) AddSkip(value string) *ODataMods {
 	if oData == nil {
@@ -0,0 +1,263 @@
+// Package odata provides typed OData $filter expressions builder rendering valid SharePoint REST filter syntax.
+// Values are escaped and formatted according to their types, expressions are parenthesized by precedence.
+//
+//	expr := odata.And(
+//		odata.Eq("Status", "Won't fix"),
+//		odata.Or(
+//			odata.Ge("Modified", time.Now().AddDate(0, 0, -7)),
+//			odata.Eq(odata.Lookup("Author").Field("Id"), 7),
+//		),
+//		odata.StartsWith("Title", "Project"),
+//	)
+//	// Status eq 'Won''t fix' and (Modified ge datetime'...' or Author/Id eq 7) and startswith(Title,'Project')
+//	items, err := sp.Web().GetList("Lists/Tasks").Items().FilterExpr(expr).Get(ctx)
+package odata
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldRegExp matches valid field paths, internal names and lookup projections
+var fieldRegExp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_/]*$`)
+
+// Expression precedence levels, lower binds weaker
+const (
+	precOr = iota + 1
+	precAnd
+	precCompare
+	precCall
+)
+
+// Expr is OData filter expression, zero value is an empty expression which is skipped in And and Or
+type Expr struct {
+	expr  string
+	prec  int
+	match func(item map[string]interface{}) bool
+	err   error
+}
+
+// String renders expression to filter string
+func (e Expr) String() string {
+	return e.expr
+}
+
+// IsEmpty checks if the expression is empty
+func (e Expr) IsEmpty() bool {
+	return e.expr == "" && e.err == nil
+}
+
+// Err gets expression validation error, e.g. an invalid field name.
+// Invalid field names are rendered as string literals so they can't alter the expression.
+func (e Expr) Err() error {
+	return e.err
+}
+
+// Field is a field path in a filter expression, e.g. `Title` or `Author/Id`
+type Field string
+
+// Validate checks the field path is a valid field name or lookup projection
+func (f Field) Validate() error {
+	if !fieldRegExp.MatchString(string(f)) {
+		return fmt.Errorf("invalid field name %q", string(f))
+	}
+	return nil
+}
+
+// Lookup is a lookup or user field which projected fields are used in a filter expression
+type Lookup string
+
+// Field gets lookup projected field path, e.g. `Lookup("Author").Field("Id")` is `Author/Id`
+func (l Lookup) Field(name string) Field {
+	return Field(string(l) + "/" + name)
+}
+
+// Literal is a preformatted OData literal which is rendered as is, e.g. `guid'...'`
+type Literal string
+
+// DateTime formats time as OData datetime literal in UTC
+func DateTime(t time.Time) Literal {
+	return Literal("datetime'" + t.UTC().Format(time.RFC3339) + "'")
+}
+
+// String formats string as OData string literal escaping single quotes
+func String(s string) Literal {
+	return Literal("'" + strings.ReplaceAll(s, "'", "''") + "'")
+}
+
+// Eq builds `field eq value` expression
+func Eq(field Field, value interface{}) Expr {
+	return compare(field, "eq", value)
+}
+
+// Ne builds `field ne value` expression
+func Ne(field Field, value interface{}) Expr {
+	return compare(field, "ne", value)
+}
+
+// Gt builds `field gt value` expression
+func Gt(field Field, value interface{}) Expr {
+	return compare(field, "gt", value)
+}
+
+// Ge builds `field ge value` expression
+func Ge(field Field, value interface{}) Expr {
+	return compare(field, "ge", value)
+}
+
+// Lt builds `field lt value` expression
+func Lt(field Field, value interface{}) Expr {
+	return compare(field, "lt", value)
+}
+
+// Le builds `field le value` expression
+func Le(field Field, value interface{}) Expr {
+	return compare(field, "le", value)
+}
+
+// StartsWith builds `startswith(field,'value')` expression
+func StartsWith(field Field, value string) Expr {
+	return Expr{
+		expr:  fmt.Sprintf("startswith(%s,%s)", formatField(field), String(value)),
+		prec:  precCall,
+		match: matchString(field, value, strings.HasPrefix),
+		err:   field.Validate(),
+	}
+}
+
+// SubstringOf builds `substringof('value',field)` expression, matches when field contains the value
+func SubstringOf(field Field, value string) Expr {
+	return Expr{
+		expr:  fmt.Sprintf("substringof(%s,%s)", String(value), formatField(field)),
+		prec:  precCall,
+		match: matchString(field, value, strings.Contains),
+		err:   field.Validate(),
+	}
+}
+
+// And joins expressions with `and` operator, empty expressions are skipped
+func And(exprs ...Expr) Expr {
+	return join("and", precAnd, exprs, false)
+}
+
+// Or joins expressions with `or` operator, empty expressions are skipped
+func Or(exprs ...Expr) Expr {
+	return join("or", precOr, exprs, true)
+}
+
+// Not negates expression
+func Not(expr Expr) Expr {
+	if expr.IsEmpty() {
+		return expr
+	}
+	return Expr{
+		expr:  "not " + wrap(expr, precCall),
+		prec:  precCall,
+		match: func(item map[string]interface{}) bool { return !expr.Match(item) },
+		err:   expr.err,
+	}
+}
+
+// compare builds comparison expression
+func compare(field Field, op string, value interface{}) Expr {
+	err := field.Validate()
+	if ref, ok := value.(Field); ok && err == nil {
+		err = ref.Validate()
+	}
+	return Expr{
+		expr:  fmt.Sprintf("%s %s %s", formatField(field), op, Format(value)),
+		prec:  precCompare,
+		match: matchCompare(field, op, value),
+		err:   err,
+	}
+}
+
+// formatField renders field path, invalid field names are rendered as string literals
+func formatField(field Field) string {
+	if field.Validate() != nil {
+		return string(String(string(field)))
+	}
+	return string(field)
+}
+
+// join joins expressions with logical operator, matchAny defines if any or all expressions should match
+func join(op string, prec int, exprs []Expr, matchAny bool) Expr {
+	var nonEmpty []Expr
+	var err error
+	for _, e := range exprs {
+		if !e.IsEmpty() {
+			nonEmpty = append(nonEmpty, e)
+		}
+		if err == nil {
+			err = e.err
+		}
+	}
+	if len(nonEmpty) == 1 {
+		return nonEmpty[0]
+	}
+	parts := make([]string, len(nonEmpty))
+	for i, e := range nonEmpty {
+		parts[i] = wrap(e, prec)
+	}
+	match := func(item map[string]interface{}) bool {
+		for _, e := range nonEmpty {
+			if e.Match(item) == matchAny {
+				return matchAny
+			}
+		}
+		return !matchAny
+	}
+	return Expr{expr: strings.Join(parts, " "+op+" "), prec: prec, match: match, err: err}
+}
+
+// wrap parenthesizes expression binding weaker than the operator
+func wrap(e Expr, prec int) string {
+	if e.prec < prec {
+		return "(" + e.expr + ")"
+	}
+	return e.expr
+}
+
+// Format formats a value as OData literal: strings are quoted and escaped, time is formatted as datetime,
+// numbers and booleans are rendered as is, nil is null, other values are formatted as strings
+func Format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case Literal:
+		return string(v)
+	case Field:
+		return formatField(v)
+	case time.Time:
+		return string(DateTime(v))
+	case *time.Time:
+		if v == nil {
+			return "null"
+		}
+		return string(DateTime(*v))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return string(String(rv.String()))
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "null"
+		}
+		return Format(rv.Elem().Interface())
+	}
+	return string(String(fmt.Sprint(value)))
+}
@@ -0,0 +1,123 @@
+package odata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+3", 3*60*60))
+	title := "Won't fix"
+
+	cases := map[string]struct {
+		expr     Expr
+		expected string
+	}{
+		"String":      {Eq("Title", "Won't fix"), "Title eq 'Won''t fix'"},
+		"StringPtr":   {Eq("Title", &title), "Title eq 'Won''t fix'"},
+		"Number":      {Gt("Priority", 2), "Priority gt 2"},
+		"Float":       {Le("Amount", 1.5), "Amount le 1.5"},
+		"Bool":        {Ne("Done", true), "Done ne true"},
+		"Null":        {Eq("Parent", nil), "Parent eq null"},
+		"DateTime":    {Ge("Modified", date), "Modified ge datetime'2020-01-02T00:04:05Z'"},
+		"Literal":     {Eq("UniqueId", Literal("guid'1'")), "UniqueId eq guid'1'"},
+		"Lookup":      {Eq(Lookup("Author").Field("Id"), 7), "Author/Id eq 7"},
+		"StartsWith":  {StartsWith("Title", "O'"), "startswith(Title,'O''')"},
+		"SubstringOf": {SubstringOf("Title", "fix"), "substringof('fix',Title)"},
+		"And":         {And(Eq("A", 1), Eq("B", 2), Eq("C", 3)), "A eq 1 and B eq 2 and C eq 3"},
+		"Precedence": {
+			And(Or(Eq("A", 1), Eq("B", 2)), Not(Eq("C", 3)), Or(And(Eq("D", 4), Eq("E", 5)), Eq("F", 6))),
+			"(A eq 1 or B eq 2) and not (C eq 3) and (D eq 4 and E eq 5 or F eq 6)",
+		},
+		"NotCall":   {Not(StartsWith("Title", "a")), "not startswith(Title,'a')"},
+		"SkipEmpty": {And(Expr{}, Or(Eq("A", 1), Expr{}), Expr{}), "A eq 1"},
+		"Empty":     {And(Not(Expr{})), ""},
+	}
+
+	for name, c := range cases {
+		if res := c.expr.String(); res != c.expected {
+			t.Errorf("%s: expected `%s`, got `%s`", name, c.expected, res)
+		}
+	}
+}
+
+func TestFieldValidation(t *testing.T) {
+	for _, field := range []Field{"Title", "_ModerationStatus", "Author/Id", "OData__x0020_Name"} {
+		if err := Eq(field, 1).Err(); err != nil {
+			t.Errorf("%s: %s", field, err)
+		}
+	}
+
+	cases := map[string]struct {
+		expr     Expr
+		expected string
+	}{
+		"Injection":   {Eq("Title eq 'a' or Id", 1), "'Title eq ''a'' or Id' eq 1"},
+		"Digit":       {Gt("1Title", 1), "'1Title' gt 1"},
+		"FieldValue":  {Eq("Title", Field("Id) or (1")), "Title eq 'Id) or (1'"},
+		"StartsWith":  {StartsWith("Title,'a') or startswith(Title", "b"), "startswith('Title,''a'') or startswith(Title','b')"},
+		"SubstringOf": {SubstringOf("", "b"), "substringof('b','')"},
+	}
+	for name, c := range cases {
+		if c.expr.Err() == nil {
+			t.Errorf("%s: invalid field name should fail", name)
+		}
+		if res := c.expr.String(); res != c.expected {
+			t.Errorf("%s: expected `%s`, got `%s`", name, c.expected, res)
+		}
+	}
+
+	expr := And(Eq("Title", "a"), Not(Eq("Bad Field", 1)))
+	if expr.Err() == nil {
+		t.Error("validation error should be propagated to the joined expression")
+	}
+	if expr.Match(map[string]interface{}{"Title": "a"}) {
+		t.Error("invalid expression should not match")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	item := map[string]interface{}{
+		"Title":    "Won't Fix",
+		"Priority": float64(3),
+		"Done":     false,
+		"Modified": "2020-01-02T03:04:05Z",
+		"Parent":   nil,
+		"Author":   map[string]interface{}{"Id": float64(7), "Title": "John"},
+		"EditorId": float64(8),
+	}
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		expr     Expr
+		expected bool
+	}{
+		"Empty":        {Expr{}, true},
+		"EqIgnoreCase": {Eq("Title", "won't fix"), true},
+		"Ne":           {Ne("Title", "Fix"), true},
+		"Number":       {Ge("Priority", 3), true},
+		"NumberFalse":  {Gt("Priority", 3), false},
+		"Bool":         {Eq("Done", false), true},
+		"Null":         {Eq("Parent", nil), true},
+		"NotNull":      {Ne("Title", nil), true},
+		"DateTime":     {Gt("Modified", date), true},
+		"DateLiteral":  {Lt("Modified", DateTime(date)), false},
+		"Expanded":     {Eq(Lookup("Author").Field("Id"), 7), true},
+		"NotExpanded":  {Eq(Lookup("Editor").Field("Id"), 8), true},
+		"Missing":      {Eq("Missing", "x"), false},
+		"TypeMismatch": {Eq("Priority", "3"), false},
+		"StartsWith":   {StartsWith("Title", "WON"), true},
+		"SubstringOf":  {SubstringOf("Title", "fix"), true},
+		"And":          {And(Eq("Done", false), Gt("Priority", 5)), false},
+		"Or":           {Or(Eq("Done", true), Gt("Priority", 2)), true},
+		"Not":          {Not(Eq("Done", true)), true},
+		"FieldToField": {Lt(Lookup("Author").Field("Id"), Field("EditorId")), true},
+		"Nested":       {And(Or(Eq("Done", true), Not(Eq("Parent", nil))), Eq("Priority", 3)), false},
+	}
+
+	for name, c := range cases {
+		if res := c.expr.Match(item); res != c.expected {
+			t.Errorf("%s: `%s` expected to be %v", name, c.expr, c.expected)
+		}
+	}
+}
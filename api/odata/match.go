@@ -0,0 +1,187 @@
+package odata
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Match evaluates expression against decoded item properties client-side, e.g. to filter items by non-indexed
+// fields in large lists. Comparison semantics follow SharePoint: strings are compared ignoring case, dates are
+// compared as time, lookup paths (`Author/Id`) are resolved in expanded objects or `<Lookup>Id` properties.
+// Empty expression matches any item, invalid expression matches none.
+func (e Expr) Match(item map[string]interface{}) bool {
+	if e.err != nil {
+		return false
+	}
+	if e.match == nil {
+		return true
+	}
+	return e.match(item)
+}
+
+// matchCompare creates comparison matcher
+func matchCompare(field Field, op string, value interface{}) func(item map[string]interface{}) bool {
+	expected := matchValue(value)
+	return func(item map[string]interface{}) bool {
+		actual, other := matchValue(fieldValue(item, string(field))), expected
+		if ref, ok := value.(Field); ok {
+			other = matchValue(fieldValue(item, string(ref))) // field to field comparison
+		}
+		c, ok := compareMatchValues(actual, other)
+		switch op {
+		case "eq":
+			return ok && c == 0
+		case "ne":
+			return !ok || c != 0
+		case "gt":
+			return ok && c > 0
+		case "ge":
+			return ok && c >= 0
+		case "lt":
+			return ok && c < 0
+		}
+		return ok && c <= 0 // le
+	}
+}
+
+// matchString creates string function matcher
+func matchString(field Field, value string, fn func(s, sub string) bool) func(item map[string]interface{}) bool {
+	sub := strings.ToLower(value)
+	return func(item map[string]interface{}) bool {
+		s, ok := fieldValue(item, string(field)).(string)
+		return ok && fn(strings.ToLower(s), sub)
+	}
+}
+
+// fieldValue resolves field path value in item properties
+func fieldValue(item map[string]interface{}, path string) interface{} {
+	parts := strings.Split(path, "/")
+	var cur interface{} = item
+	for i, part := range parts {
+		props, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v, found := props[part]
+		if !found && i == len(parts)-2 && strings.EqualFold(parts[i+1], "Id") {
+			return props[part+"Id"] // not expanded lookup, e.g. `AuthorId`
+		}
+		cur = v
+	}
+	return cur
+}
+
+// matchValue normalizes value to nil, string, float64, bool or time.Time
+func matchValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC()
+	case Literal:
+		return literalValue(string(v))
+	case string:
+		return v
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return matchValue(rv.Elem().Interface())
+	}
+	return value
+}
+
+// literalValue converts preformatted literal to comparable value
+func literalValue(lit string) interface{} {
+	if i := strings.Index(lit, "'"); i >= 0 && strings.HasSuffix(lit, "'") && len(lit) > i+1 {
+		prefix, value := strings.ToLower(lit[:i]), strings.ReplaceAll(lit[i+1:len(lit)-1], "''", "'")
+		if prefix == "datetime" {
+			if t, ok := parseTime(value); ok {
+				return t
+			}
+		}
+		return value
+	}
+	if lit == "null" {
+		return nil
+	}
+	return lit
+}
+
+// compareMatchValues compares normalized values, returns false when values are not comparable
+func compareMatchValues(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, a == nil && b == nil
+	}
+	switch bv := b.(type) {
+	case float64:
+		av, ok := a.(float64)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(av < bv, av > bv), true
+	case bool:
+		av, ok := a.(bool)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(!av && bv, av && !bv), true
+	case time.Time:
+		s, ok := a.(string)
+		if !ok {
+			return 0, false
+		}
+		at, ok := parseTime(s)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(at.Before(bv), at.After(bv)), true
+	case string:
+		av, ok := a.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(strings.ToLower(av), strings.ToLower(bv)), true
+	}
+	return 0, false
+}
+
+// compareOrdered converts less/greater flags to comparison result
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// parseTime parses SharePoint date time value
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"fmt"
 	"testing"
+
+	"github.com/recolabs/gosip/api/odata"
 )
 
 func TestOData(t *testing.T) {
@@ -45,6 +47,14 @@ func TestOData(t *testing.T) {
 		}
 	})
 
+	t.Run("addFilterExpr", func(t *testing.T) {
+		modifiers := &ODataMods{}
+		modifiers.AddFilterExpr(odata.And(odata.Eq("Title", "O'Neil"), odata.Gt("Id", 1)))
+		if fmt.Sprintf("%+v", modifiers.Get()) != "map[$filter:Title eq 'O''Neil' and Id gt 1]" {
+			t.Error("incorrect add filter expression result")
+		}
+	})
+
 	t.Run("addSkip", func(t *testing.T) {
 		modifiers := &ODataMods{}
 		modifiers.AddSkip("Value")
@@ -26,6 +26,12 @@ func (recycleBin *RecycleBin) Filter(oDataFilter string) *RecycleBin {
 	return recycleBin
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (recycleBin *RecycleBin) FilterExpr(expr ODataExpr) *RecycleBin {
+	recycleBin.modifiers.AddFilterExpr(expr)
+	return recycleBin
+}
+
 // Top adds $top OData modifier
 func (recycleBin *RecycleBin) Top(oDataTop int) *RecycleBin {
 	recycleBin.modifiers.AddTop(oDataTop)
@@ -0,0 +1,422 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/recolabs/gosip"
+)
+
+// SharingLinkKind - sharing link kind
+type SharingLinkKind int
+
+// Sharing link kinds
+const (
+	SharingLinkUninitialized    SharingLinkKind = 0
+	SharingLinkDirect           SharingLinkKind = 1
+	SharingLinkOrganizationView SharingLinkKind = 2
+	SharingLinkOrganizationEdit SharingLinkKind = 3
+	SharingLinkAnonymousView    SharingLinkKind = 4
+	SharingLinkAnonymousEdit    SharingLinkKind = 5
+	SharingLinkFlexible         SharingLinkKind = 6
+)
+
+// SharingScope - sharing link audience, zero value is not set so anonymous links are only created explicitly
+type SharingScope int
+
+// Sharing link scopes
+const (
+	SharingScopeUnset          SharingScope = 0 // organization scope is used for new links
+	SharingScopeAnyone         SharingScope = 1
+	SharingScopeOrganization   SharingScope = 2
+	SharingScopeSpecificPeople SharingScope = 3
+)
+
+// SharingRole - sharing permission role
+type SharingRole int
+
+// Sharing roles
+const (
+	SharingRoleNone  SharingRole = 0
+	SharingRoleView  SharingRole = 1
+	SharingRoleEdit  SharingRole = 2
+	SharingRoleOwner SharingRole = 3
+)
+
+// sharingRoleValues maps sharing roles to ShareObject role definitions values
+var sharingRoleValues = map[SharingRole]string{
+	SharingRoleView: "role:1073741826",
+	SharingRoleEdit: "role:1073741827",
+}
+
+// Sharing represents SharePoint sharing API for a list item, a file or a folder
+// Always use NewSharing constructor instead of &Sharing{}
+type Sharing struct {
+	client   *gosip.SPClient
+	config   *RequestConfig
+	endpoint string
+}
+
+// SharingLinkOptions - sharing link creation options
+type SharingLinkOptions struct {
+	Scope      SharingScope // link audience, organization by default
+	Role       SharingRole  // view or edit, view by default
+	Expiration time.Time    // link expiration (optional)
+	Password   string       // anonymous link password (optional)
+	Recipients []string     // login names or emails for specific people links
+}
+
+// ShareObjectOptions - share with users options
+type ShareObjectOptions struct {
+	Role                 SharingRole // view or edit, view by default
+	SendEmail            bool        // send invitation email
+	EmailSubject         string      // invitation email subject (optional)
+	EmailBody            string      // invitation email body (optional)
+	IncludeAnonymousLink bool        // include anonymous link into the invitation email
+	PropagateACL         bool        // propagate permissions to the object children
+	GroupID              int         // SharePoint group ID to add the users to instead of direct permissions (optional)
+}
+
+// SharingLinkInfo - sharing link details
+type SharingLinkInfo struct {
+	ShareID                   string          `json:"ShareId"`
+	URL                       string          `json:"Url"`
+	LinkKind                  SharingLinkKind `json:"LinkKind"`
+	Scope                     SharingScope    `json:"Scope"`
+	IsEditLink                bool            `json:"IsEditLink"`
+	IsAnonymous               bool            `json:"IsAnonymous"`
+	IsActive                  bool            `json:"IsActive"`
+	AllowsAnonymousAccess     bool            `json:"AllowsAnonymousAccess"`
+	HasExternalGuestInvitees  bool            `json:"HasExternalGuestInvitees"`
+	RequiresPassword          bool            `json:"RequiresPassword"`
+	RestrictedShareMembership bool            `json:"RestrictedShareMembership"`
+	Created                   string          `json:"Created"`
+	Expiration                string          `json:"Expiration"` // empty when the link never expires
+}
+
+// SharingPrincipal - sharing principal, a user or a group
+type SharingPrincipal struct {
+	ID            int    `json:"id"`
+	LoginName     string `json:"loginName"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	PrincipalType int    `json:"principalType"`
+	IsExternal    bool   `json:"isExternal"`
+}
+
+// SharingLink - sharing link with its members
+type SharingLink struct {
+	Details     SharingLinkInfo    `json:"linkDetails"`
+	Members     []SharingPrincipal `json:"linkMembers"`
+	IsInherited bool               `json:"isInherited"`
+}
+
+// SharingPrincipalRole - principal with direct access to the object
+type SharingPrincipalRole struct {
+	Principal   SharingPrincipal `json:"principal"`
+	Role        SharingRole      `json:"role"`
+	IsInherited bool             `json:"isInherited"`
+}
+
+// SharingInformation - sharing information of the object
+type SharingInformation struct {
+	ItemURL                                string `json:"itemUrl"`
+	CanAddExternalPrincipal                bool   `json:"canAddExternalPrincipal"`
+	CanAddInternalPrincipal                bool   `json:"canAddInternalPrincipal"`
+	CanBeUnshared                          bool   `json:"canBeUnshared"`
+	HasUniquePermissions                   bool   `json:"hasUniquePermissions"`
+	AnonymousLinkExpirationRestrictionDays int    `json:"anonymousLinkExpirationRestrictionDays"`
+	PermissionsInformation                 struct {
+		HasInheritedLinks bool                   `json:"hasInheritedLinks"`
+		Links             []SharingLink          `json:"links"`
+		Principals        []SharingPrincipalRole `json:"principals"`
+	} `json:"permissionsInformation"`
+}
+
+// SharingUserResult - share with user result
+type SharingUserResult struct {
+	User        string `json:"User"`
+	Email       string `json:"Email"`
+	DisplayName string `json:"DisplayName"`
+	Status      bool   `json:"Status"`
+	Message     string `json:"Message"`
+	IsUserKnown bool   `json:"IsUserKnown"`
+}
+
+// SharingResult - ShareObject and UnshareObject result
+type SharingResult struct {
+	StatusCode                int                 `json:"StatusCode"`
+	ErrorMessage              string              `json:"ErrorMessage"`
+	Name                      string              `json:"Name"`
+	URL                       string              `json:"Url"`
+	UniquelyPermissionedUsers []SharingUserResult `json:"UniquelyPermissionedUsers"`
+}
+
+// NewSharing - Sharing struct constructor function, endpoint is a list item endpoint
+func NewSharing(client *gosip.SPClient, endpoint string, config *RequestConfig) *Sharing {
+	return &Sharing{
+		client:   client,
+		endpoint: endpoint,
+		config:   config,
+	}
+}
+
+// Sharing gets sharing API instance for this Item
+func (item *Item) Sharing() *Sharing {
+	return NewSharing(item.client, item.endpoint, item.config)
+}
+
+// Sharing gets sharing API instance for this File
+func (file *File) Sharing() *Sharing {
+	return NewSharing(file.client, fmt.Sprintf("%s/ListItemAllFields", file.endpoint), file.config)
+}
+
+// Sharing gets sharing API instance for this Folder
+func (folder *Folder) Sharing() *Sharing {
+	return NewSharing(folder.client, fmt.Sprintf("%s/ListItemAllFields", folder.endpoint), folder.config)
+}
+
+// CreateLink creates anonymous, organization or specific people sharing link,
+// the existing link is returned when the same kind of link is already created
+func (sharing *Sharing) CreateLink(ctx context.Context, options *SharingLinkOptions) (*SharingLinkInfo, error) {
+	if options == nil {
+		options = &SharingLinkOptions{}
+	}
+	scope := options.Scope
+	if scope == SharingScopeUnset {
+		scope = SharingScopeOrganization
+	}
+	if scope > SharingScopeSpecificPeople {
+		return nil, fmt.Errorf("unsupported sharing scope %d", scope)
+	}
+	role := options.Role
+	if role == SharingRoleNone {
+		role = SharingRoleView
+	}
+
+	settings := map[string]interface{}{
+		"linkKind": sharingLinkKind(scope, role),
+		"scope":    scope,
+		"role":     role,
+	}
+	if !options.Expiration.IsZero() {
+		settings["expiration"] = options.Expiration.UTC().Format(time.RFC3339)
+	}
+	if options.Password != "" {
+		settings["password"] = options.Password
+		settings["updatePassword"] = true
+	}
+	request := map[string]interface{}{
+		"createLink": true,
+		"settings":   settings,
+	}
+	if scope == SharingScopeSpecificPeople {
+		settings["restrictShareMembership"] = true
+		if len(options.Recipients) > 0 {
+			request["peoplePickerInput"] = peoplePickerInput(options.Recipients)
+		}
+	}
+
+	resp, err := sharing.post(ctx, "ShareLink", map[string]interface{}{"request": request})
+	if err != nil {
+		return nil, err
+	}
+	res := &struct {
+		SharingLinkInfo *SharingLinkInfo `json:"sharingLinkInfo"`
+	}{}
+	if err := json.Unmarshal(NormalizeODataItem(resp), res); err != nil {
+		return nil, err
+	}
+	if res.SharingLinkInfo == nil {
+		return nil, fmt.Errorf("no sharing link in the response")
+	}
+	return res.SharingLinkInfo, nil
+}
+
+// GetSharingInformation gets object sharing links and principals with access
+func (sharing *Sharing) GetSharingInformation(ctx context.Context) (*SharingInformation, error) {
+	resp, err := sharing.post(ctx, "GetSharingInformation?$expand=permissionsInformation", map[string]interface{}{
+		"request": map[string]interface{}{
+			"maxPrincipalsToReturn":  0,
+			"maxLinkMembersToReturn": 0,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := &SharingInformation{}
+	if err := json.Unmarshal(NormalizeODataItem(resp), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Links gets object sharing links
+func (sharing *Sharing) Links(ctx context.Context) ([]SharingLink, error) {
+	info, err := sharing.GetSharingInformation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.PermissionsInformation.Links, nil
+}
+
+// UnshareLink removes sharing link by its kind and share ID
+func (sharing *Sharing) UnshareLink(ctx context.Context, kind SharingLinkKind, shareID string) error {
+	_, err := sharing.post(ctx, "UnshareLink", map[string]interface{}{
+		"linkKind": kind,
+		"shareId":  shareID,
+	})
+	return err
+}
+
+// DeleteLinkByKind deletes all sharing links of the kind
+func (sharing *Sharing) DeleteLinkByKind(ctx context.Context, kind SharingLinkKind) error {
+	_, err := sharing.post(ctx, "DeleteLinkByKind", map[string]interface{}{
+		"linkKind": kind,
+	})
+	return err
+}
+
+// ShareWith shares the object with the users or groups by their login names or emails
+func (sharing *Sharing) ShareWith(ctx context.Context, loginNames []string, options *ShareObjectOptions) (*SharingResult, error) {
+	if options == nil {
+		options = &ShareObjectOptions{}
+	}
+	role := options.Role
+	if role == SharingRoleNone {
+		role = SharingRoleView
+	}
+	roleValue, ok := sharingRoleValues[role]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sharing role %d", role)
+	}
+
+	objectURL, err := sharing.objectURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sharing.webAction(ctx, "SP.Web.ShareObject", map[string]interface{}{
+		"url":                         objectURL,
+		"peoplePickerInput":           peoplePickerInput(loginNames),
+		"roleValue":                   roleValue,
+		"groupId":                     options.GroupID,
+		"propagateAcl":                options.PropagateACL,
+		"sendEmail":                   options.SendEmail,
+		"includeAnonymousLinkInEmail": options.IncludeAnonymousLink,
+		"emailSubject":                options.EmailSubject,
+		"emailBody":                   options.EmailBody,
+		"useSimplifiedRoles":          true,
+	})
+}
+
+// Unshare removes all sharing links and direct user permissions of the object
+func (sharing *Sharing) Unshare(ctx context.Context) (*SharingResult, error) {
+	objectURL, err := sharing.objectURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sharing.webAction(ctx, "SP.Web.UnshareObject", map[string]interface{}{
+		"url": objectURL,
+	})
+}
+
+// objectURL gets the object absolute URL
+func (sharing *Sharing) objectURL(ctx context.Context) (string, error) {
+	resp, err := NewItem(sharing.client, sharing.endpoint, sharing.config).Select("EncodedAbsUrl").Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	res := &struct {
+		EncodedAbsURL string `json:"EncodedAbsUrl"`
+	}{}
+	if err := json.Unmarshal(resp.Normalized(), res); err != nil {
+		return "", err
+	}
+	return res.EncodedAbsURL, nil
+}
+
+// webAction calls SP.Web sharing method checking operation status code
+func (sharing *Sharing) webAction(ctx context.Context, method string, payload map[string]interface{}) (*SharingResult, error) {
+	endpoint := fmt.Sprintf("%s/_api/%s", getPriorEndpoint(sharing.endpoint, "/_api"), method)
+	body, _ := json.Marshal(payload)
+	client := NewHTTPClient(sharing.client)
+	resp, err := client.Post(ctx, endpoint, bytes.NewBuffer(body), sharing.conf())
+	if err != nil {
+		return nil, err
+	}
+	res := &SharingResult{}
+	if err := json.Unmarshal(NormalizeODataItem(resp), res); err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 0 {
+		return res, fmt.Errorf("sharing failed with status code %d: %s", res.StatusCode, res.ErrorMessage)
+	}
+	return res, nil
+}
+
+// post calls list item sharing method
+func (sharing *Sharing) post(ctx context.Context, method string, payload map[string]interface{}) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/%s", sharing.endpoint, method)
+	body, _ := json.Marshal(payload)
+	client := NewHTTPClient(sharing.client)
+	return client.Post(ctx, endpoint, bytes.NewBuffer(body), sharing.conf())
+}
+
+// conf gets request config with minimal metadata, sharing responses contain nested collections
+func (sharing *Sharing) conf() *RequestConfig {
+	return patchConfigHeaders(sharing.config, map[string]string{
+		"Accept":       "application/json;odata=minimalmetadata",
+		"Content-Type": "application/json;odata=verbose;charset=utf-8",
+	})
+}
+
+// MarshalJSON writes SharePoint scope value, which is zero based starting from anyone
+func (scope SharingScope) MarshalJSON() ([]byte, error) {
+	if scope == SharingScopeUnset {
+		return nil, fmt.Errorf("sharing scope is not set")
+	}
+	return json.Marshal(int(scope) - 1)
+}
+
+// UnmarshalJSON reads SharePoint scope value
+func (scope *SharingScope) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var value int
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*scope = SharingScope(value + 1)
+	return nil
+}
+
+// sharingLinkKind maps link scope and role to legacy link kind
+func sharingLinkKind(scope SharingScope, role SharingRole) SharingLinkKind {
+	switch scope {
+	case SharingScopeAnyone:
+		if role == SharingRoleEdit {
+			return SharingLinkAnonymousEdit
+		}
+		return SharingLinkAnonymousView
+	case SharingScopeOrganization:
+		if role == SharingRoleEdit {
+			return SharingLinkOrganizationEdit
+		}
+		return SharingLinkOrganizationView
+	}
+	return SharingLinkFlexible
+}
+
+// peoplePickerInput formats people picker input value
+func peoplePickerInput(loginNames []string) string {
+	keys := make([]map[string]string, 0, len(loginNames))
+	for _, loginName := range loginNames {
+		keys = append(keys, map[string]string{"Key": loginName})
+	}
+	data, _ := json.Marshal(keys)
+	return string(data)
+}
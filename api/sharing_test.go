@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestSharing(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	fileURL := sptest.ServerSitePath + "/Shared Documents/Sharing/Report.docx"
+	srv.AddFile(fileURL, []byte("report"))
+
+	ctx := context.Background()
+	sharing := NewSP(srv.Client()).Web().GetFile(fileURL).Sharing()
+
+	t.Run("CreateLink", func(t *testing.T) {
+		link, err := sharing.CreateLink(ctx, &SharingLinkOptions{
+			Scope:      SharingScopeAnyone,
+			Expiration: time.Now().AddDate(0, 0, 7),
+			Password:   "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.LinkKind != SharingLinkAnonymousView || !link.IsAnonymous || !link.RequiresPassword || link.Expiration == "" || link.URL == "" {
+			t.Errorf("unexpected anonymous link: %+v", link)
+		}
+		if link.Scope != SharingScopeAnyone {
+			t.Errorf("unexpected anonymous link scope: %d", link.Scope)
+		}
+
+		link, err = sharing.CreateLink(ctx, &SharingLinkOptions{Scope: SharingScopeOrganization, Role: SharingRoleEdit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.LinkKind != SharingLinkOrganizationEdit || !link.IsEditLink || link.IsAnonymous {
+			t.Errorf("unexpected organization link: %+v", link)
+		}
+
+		link, err = sharing.CreateLink(ctx, &SharingLinkOptions{
+			Scope:      SharingScopeSpecificPeople,
+			Recipients: []string{"i:0#.f|membership|guest_contoso.com#ext#@tenant.onmicrosoft.com"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.LinkKind != SharingLinkFlexible || !link.RestrictedShareMembership || !link.HasExternalGuestInvitees {
+			t.Errorf("unexpected specific people link: %+v", link)
+		}
+	})
+
+	t.Run("ShareWith", func(t *testing.T) {
+		res, err := sharing.ShareWith(ctx, []string{"i:0#.f|membership|user@contoso.com"}, &ShareObjectOptions{Role: SharingRoleEdit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.UniquelyPermissionedUsers) != 1 || res.UniquelyPermissionedUsers[0].Email != "user@contoso.com" {
+			t.Errorf("unexpected sharing result: %+v", res)
+		}
+		if _, err := sharing.ShareWith(ctx, nil, nil); err == nil {
+			t.Error("sharing with no users should fail")
+		}
+	})
+
+	t.Run("GetSharingInformation", func(t *testing.T) {
+		info, err := sharing.GetSharingInformation(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.PermissionsInformation.Links) != 3 {
+			t.Errorf("expected 3 links, got %d", len(info.PermissionsInformation.Links))
+		}
+		principals := info.PermissionsInformation.Principals
+		if len(principals) != 1 || principals[0].Role != SharingRoleEdit || principals[0].Principal.Email != "user@contoso.com" {
+			t.Errorf("unexpected principals: %+v", principals)
+		}
+		external := 0
+		for _, link := range info.PermissionsInformation.Links {
+			if link.Details.IsAnonymous || link.Details.HasExternalGuestInvitees {
+				external++
+			}
+		}
+		if external != 2 {
+			t.Errorf("expected 2 external links, got %d", external)
+		}
+	})
+
+	t.Run("UnshareLink", func(t *testing.T) {
+		links, err := sharing.Links(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sharing.UnshareLink(ctx, links[0].Details.LinkKind, links[0].Details.ShareID); err != nil {
+			t.Fatal(err)
+		}
+		if err := sharing.DeleteLinkByKind(ctx, SharingLinkOrganizationEdit); err != nil {
+			t.Fatal(err)
+		}
+		if links, _ = sharing.Links(ctx); len(links) != 1 || links[0].Details.LinkKind != SharingLinkFlexible {
+			t.Errorf("unexpected links: %+v", links)
+		}
+	})
+
+	t.Run("Unshare", func(t *testing.T) {
+		if _, err := sharing.Unshare(ctx); err != nil {
+			t.Fatal(err)
+		}
+		info, err := sharing.GetSharingInformation(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.PermissionsInformation.Links) != 0 || len(info.PermissionsInformation.Principals) != 0 {
+			t.Errorf("object should be unshared: %+v", info.PermissionsInformation)
+		}
+	})
+
+	t.Run("Item", func(t *testing.T) {
+		if err := srv.AddList("Shared", 100); err != nil {
+			t.Fatal(err)
+		}
+		id, err := srv.AddItem("Shared", map[string]interface{}{"Title": "Item"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := NewSP(srv.Client()).Web().Lists().GetByTitle("Shared").Items().GetByID(id)
+		if _, err := item.Sharing().ShareWith(ctx, []string{"user@contoso.com"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		info, err := item.Sharing().GetSharingInformation(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.PermissionsInformation.Principals) != 1 {
+			t.Errorf("unexpected principals: %+v", info.PermissionsInformation.Principals)
+		}
+	})
+}
+
+func TestSharingDefaultScope(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	fileURL := sptest.ServerSitePath + "/Shared Documents/Sharing/Default.docx"
+	srv.AddFile(fileURL, []byte("default"))
+
+	ctx := context.Background()
+	sharing := NewSP(srv.Client()).Web().GetFile(fileURL).Sharing()
+
+	link, err := sharing.CreateLink(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.IsAnonymous || link.AllowsAnonymousAccess || link.LinkKind != SharingLinkOrganizationView || link.Scope != SharingScopeOrganization {
+		t.Errorf("link with no options should not be anonymous: %+v", link)
+	}
+
+	if _, err := sharing.CreateLink(ctx, &SharingLinkOptions{Scope: SharingScope(10)}); err == nil {
+		t.Error("unsupported scope should fail")
+	}
+}
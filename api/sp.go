@@ -4,14 +4,10 @@ package api
 import (
 	"context"
 	"fmt"
-	"time"
 
-	"github.com/patrickmn/go-cache"
 	"github.com/recolabs/gosip"
 )
 
-var storage = cache.New(5*time.Minute, 10*time.Minute)
-
 //go:generate ggen -ent SP -conf
 
 // SP represents SharePoint REST+ API root struct
@@ -77,6 +73,15 @@ func (sp *SP) Taxonomy() *Taxonomy {
 	return NewTaxonomy(sp.client, sp.ToURL(), sp.config)
 }
 
+// Batch creates new OData $batch requests queue
+func (sp *SP) Batch() *Batch {
+	return NewBatch(
+		sp.client,
+		fmt.Sprintf("%s/_api/$batch", sp.ToURL()),
+		sp.config,
+	)
+}
+
 // ContextInfo gets current Context Info object data
 func (sp *SP) ContextInfo(ctx context.Context) (*ContextInfo, error) {
 	return NewContext(sp.client, sp.ToURL(), sp.config).Get(ctx)
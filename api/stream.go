@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/recolabs/gosip"
+)
+
+// ErrStopStream can be returned from a stream callback to stop the stream with no error
+var ErrStopStream = errors.New("stream is stopped")
+
+// Stream gets Items API queryable collection decoding the response one item at a time,
+// next pages are requested until the collection ends or the callback returns an error.
+// Use the method for large pages (e.g. `Top(5000)`) to keep memory and CPU usage flat:
+// items are not buffered, normalized and remarshaled as a whole as with `Get` and `Data`.
+// Return ErrStopStream from the callback to stop the stream with no error.
+func (items *Items) Stream(ctx context.Context, callback func(item ItemResp) error) error {
+	return streamODataCollection(ctx, items.client, items.ToURL(), items.config, func(item []byte) error {
+		return callback(ItemResp(item))
+	})
+}
+
+// streamODataCollection requests OData collection pages and passes collection elements to the callback
+func streamODataCollection(ctx context.Context, spClient *gosip.SPClient, endpoint string, conf *RequestConfig, callback func(item []byte) error) error {
+	client := NewHTTPClient(spClient)
+	for endpoint != "" {
+		body, err := client.GetStream(ctx, endpoint, conf)
+		if err != nil {
+			return err
+		}
+		endpoint, err = decodeODataCollection(body, callback)
+		shut(body)
+		if errors.Is(err, ErrStopStream) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeODataCollection decodes OData collection response element by element taking care of OData mode,
+// verbose `d.results` and minimal/nometadata `value` collections are supported, returns next page URL
+func decodeODataCollection(r io.Reader, callback func(item []byte) error) (string, error) {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	switch tok {
+	case json.Delim('['): // plain array
+		return "", decodeODataArray(dec, callback)
+	case json.Delim('{'):
+	default:
+		return "", fmt.Errorf("unexpected OData collection token: %v", tok)
+	}
+
+	nextURL := ""
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case "d": // verbose mode
+			next, err := decodeODataVerbose(dec, callback)
+			if err != nil {
+				return "", err
+			}
+			nextURL = next
+		case "value":
+			if err := expectDelim(dec, '['); err != nil {
+				return "", err
+			}
+			if err := decodeODataArray(dec, callback); err != nil {
+				return "", err
+			}
+		case "odata.nextLink", "@odata.nextLink":
+			if err := dec.Decode(&nextURL); err != nil {
+				return "", err
+			}
+		default:
+			if err := dec.Decode(&json.RawMessage{}); err != nil {
+				return "", err
+			}
+		}
+	}
+	return nextURL, nil
+}
+
+// decodeODataVerbose decodes verbose mode `d` object
+func decodeODataVerbose(dec *json.Decoder, callback func(item []byte) error) (string, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", err
+	}
+	nextURL := ""
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case "results":
+			if err := expectDelim(dec, '['); err != nil {
+				return "", err
+			}
+			if err := decodeODataArray(dec, callback); err != nil {
+				return "", err
+			}
+		case "__next":
+			if err := dec.Decode(&nextURL); err != nil {
+				return "", err
+			}
+		default:
+			if err := dec.Decode(&json.RawMessage{}); err != nil {
+				return "", err
+			}
+		}
+	}
+	_, err := dec.Token() // closing `}`
+	return nextURL, err
+}
+
+// decodeODataArray decodes array elements passing them to the callback, the opening `[` must be consumed
+func decodeODataArray(dec *json.Decoder, callback func(item []byte) error) error {
+	for dec.More() {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		// Verbose multi-lookups are wrapped into `results` objects, normalizing only when there are any
+		if bytes.Contains(item, []byte(`"results"`)) {
+			item = normalizeMultiLookups(item)
+		}
+		if err := callback(item); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token() // closing `]`
+	return err
+}
+
+// expectDelim reads next token checking it is the delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected OData collection token: %v, expected %v", tok, delim)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/gosip/sptest"
+)
+
+func TestDecodeODataCollection(t *testing.T) {
+	cases := map[string]string{
+		"Verbose":    `{"d":{"results":[{"Id":1,"Lookup":{"results":[1,2]}},{"Id":2}],"__next":"next"}}`,
+		"Nometadata": `{"odata.metadata":"meta","value":[{"Id":1,"Lookup":[1,2]},{"Id":2}],"odata.nextLink":"next"}`,
+		"Array":      `[{"Id":1,"Lookup":[1,2]},{"Id":2}]`,
+	}
+	for mode, payload := range cases {
+		var items []string
+		nextURL, err := decodeODataCollection(strings.NewReader(payload), func(item []byte) error {
+			items = append(items, string(item))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", mode, err)
+		}
+		if len(items) != 2 || items[0] != `{"Id":1,"Lookup":[1,2]}` || items[1] != `{"Id":2}` {
+			t.Errorf("%s: unexpected items: %v", mode, items)
+		}
+		if mode != "Array" && nextURL != "next" {
+			t.Errorf("%s: unexpected next URL: %s", mode, nextURL)
+		}
+	}
+
+	if _, err := decodeODataCollection(strings.NewReader(`{"value":[{"Id":1}`), func([]byte) error { return nil }); err == nil {
+		t.Error("should fail on truncated payload")
+	}
+}
+
+func TestItemsStream(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddList("Stream", 100); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := srv.AddItem("Stream", map[string]interface{}{"Title": fmt.Sprintf("Item %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	list := NewSP(srv.Client()).Web().Lists().GetByTitle("Stream")
+
+	for _, conf := range []*RequestConfig{HeadersPresets.Verbose, HeadersPresets.Nometadata} {
+		var titles []string
+		err := list.Items().Conf(conf).Select("Id,Title").Top(2).Stream(ctx, func(item ItemResp) error {
+			titles = append(titles, item.Data().Title)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(titles) != 5 || titles[0] != "Item 1" || titles[4] != "Item 5" {
+			t.Errorf("unexpected streamed items: %v", titles)
+		}
+	}
+
+	cnt := 0
+	err := list.Items().Top(2).Stream(ctx, func(item ItemResp) error {
+		cnt++
+		if cnt == 3 {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("stream should be stopped after 3 items, got %d", cnt)
+	}
+
+	if err := NewSP(srv.Client()).Web().Lists().GetByTitle("Missing").Items().Stream(ctx, func(ItemResp) error { return nil }); err == nil {
+		t.Error("should fail for missing list")
+	}
+}
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypedList is a generic typed access to list items, T is a struct which fields are mapped to list fields with `sp` tags:
+//
+//	type Task struct {
+//		ID       int           `sp:"Id"`
+//		Title    string        `sp:"Title"`
+//		Manager  api.UserValue `sp:"Manager,user"`
+//		Category []int         `sp:"Category,lookup"`
+//		Created  time.Time     `sp:"Created,readonly"`
+//	}
+//
+// Tag options: `lookup` and `user` mark lookup and person fields, `readonly` excludes a field from Add/Update payloads,
+// `omitempty` excludes a zero value. Lookup and person fields can be typed as an ID (int), a struct (e.g. LookupValue, UserValue)
+// which JSON fields are selected from the lookup target, a pointer or a slice (multi-value fields) of them.
+// Always use NewTypedList constructor instead of &TypedList{}
+type TypedList[T any] struct {
+	list   *List
+	schema *typedSchema
+	err    error
+}
+
+// TypedItems represents TypedList items queryable collection
+type TypedItems[T any] struct {
+	items  *Items
+	schema *typedSchema
+	err    error
+}
+
+// LookupValue is a lookup field value of typed list items
+type LookupValue struct {
+	ID    int    `json:"Id"`
+	Title string `json:"Title"`
+}
+
+// UserValue is a person or group field value of typed list items
+type UserValue struct {
+	ID    int    `json:"Id"`
+	Title string `json:"Title"`
+	EMail string `json:"EMail"`
+}
+
+// URLValue is a hyperlink or picture field value of typed list items
+type URLValue struct {
+	Description string `json:"Description"`
+	URL         string `json:"Url"`
+}
+
+// MarshalJSON adds verbose mode metadata to the field value payload
+func (v URLValue) MarshalJSON() ([]byte, error) {
+	type value URLValue
+	return json.Marshal(&struct {
+		Metadata map[string]string `json:"__metadata"`
+		value
+	}{map[string]string{"type": "SP.FieldUrlValue"}, value(v)})
+}
+
+// TaxonomyValue is a managed metadata field value of typed list items
+type TaxonomyValue struct {
+	Label    string `json:"Label"`
+	TermGUID string `json:"TermGuid"`
+	WssID    int    `json:"WssId"`
+}
+
+// MarshalJSON adds verbose mode metadata to the field value payload
+func (v TaxonomyValue) MarshalJSON() ([]byte, error) {
+	type value TaxonomyValue
+	return json.Marshal(&struct {
+		Metadata map[string]string `json:"__metadata"`
+		value
+	}{map[string]string{"type": "SP.Taxonomy.TaxonomyFieldValue"}, value(v)})
+}
+
+// typedSchema is T struct to list fields mapping
+type typedSchema struct {
+	fields []*typedField
+}
+
+// typedField is a struct field to list field mapping
+type typedField struct {
+	index     []int
+	name      string // list field internal name
+	lookup    bool   // lookup or person field
+	idOnly    bool   // lookup value is typed as ID, `<name>Id` field is used
+	multi     bool   // multi-value field
+	subFields []string
+	readOnly  bool
+	omitEmpty bool
+}
+
+// NewTypedList - TypedList struct constructor function
+func NewTypedList[T any](list *List) *TypedList[T] {
+	schema, err := newTypedSchema(reflect.TypeOf((*T)(nil)).Elem())
+	return &TypedList[T]{list: list, schema: schema, err: err}
+}
+
+// Items gets typed items queryable collection with the fields of T selected and expanded
+func (tl *TypedList[T]) Items() *TypedItems[T] {
+	items := tl.list.Items()
+	if tl.err == nil {
+		if sel := tl.schema.selects(); sel != "" {
+			items.Select(sel)
+		}
+		if exp := tl.schema.expands(); exp != "" {
+			items.Expand(exp)
+		}
+	}
+	return &TypedItems[T]{items: items, schema: tl.schema, err: tl.err}
+}
+
+// GetByID gets typed item by its ID
+func (tl *TypedList[T]) GetByID(ctx context.Context, itemID int) (*T, error) {
+	if tl.err != nil {
+		return nil, tl.err
+	}
+	item := tl.list.Items().GetByID(itemID)
+	if sel := tl.schema.selects(); sel != "" {
+		item.Select(sel)
+	}
+	if exp := tl.schema.expands(); exp != "" {
+		item.Expand(exp)
+	}
+	data, err := item.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTyped[T](tl.schema, data)
+}
+
+// Add adds new item to the list, returns the created item ID
+func (tl *TypedList[T]) Add(ctx context.Context, item *T) (int, error) {
+	body, err := tl.marshal(item)
+	if err != nil {
+		return 0, err
+	}
+	data, err := tl.list.Items().Add(ctx, body)
+	if err != nil {
+		return 0, err
+	}
+	return data.Data().ID, nil
+}
+
+// Update updates the item by its ID with the values of mapped fields
+func (tl *TypedList[T]) Update(ctx context.Context, itemID int, item *T) error {
+	body, err := tl.marshal(item)
+	if err != nil {
+		return err
+	}
+	_, err = tl.list.Items().GetByID(itemID).Update(ctx, body)
+	return err
+}
+
+// marshal serializes T to list item payload, metadata type is added by Items.Add and Item.Update
+func (tl *TypedList[T]) marshal(item *T) ([]byte, error) {
+	if tl.err != nil {
+		return nil, tl.err
+	}
+	payload, err := tl.schema.encode(reflect.ValueOf(item).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// Conf receives custom request config definition, e.g. custom headers, custom OData mod
+func (ti *TypedItems[T]) Conf(config *RequestConfig) *TypedItems[T] {
+	ti.items.Conf(config)
+	return ti
+}
+
+// Filter adds $filter OData modifier
+func (ti *TypedItems[T]) Filter(oDataFilter string) *TypedItems[T] {
+	ti.items.Filter(oDataFilter)
+	return ti
+}
+
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (ti *TypedItems[T]) FilterExpr(expr ODataExpr) *TypedItems[T] {
+	ti.items.FilterExpr(expr)
+	return ti
+}
+
+// Top adds $top OData modifier
+func (ti *TypedItems[T]) Top(oDataTop int) *TypedItems[T] {
+	ti.items.Top(oDataTop)
+	return ti
+}
+
+// Skip adds $skiptoken OData modifier
+func (ti *TypedItems[T]) Skip(skipToken string) *TypedItems[T] {
+	ti.items.Skip(skipToken)
+	return ti
+}
+
+// OrderBy adds $orderby OData modifier
+func (ti *TypedItems[T]) OrderBy(oDataOrderBy string, ascending bool) *TypedItems[T] {
+	ti.items.OrderBy(oDataOrderBy, ascending)
+	return ti
+}
+
+// Get gets typed items of a page
+func (ti *TypedItems[T]) Get(ctx context.Context) ([]*T, error) {
+	if ti.err != nil {
+		return nil, ti.err
+	}
+	data, err := ti.items.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []*T
+	for _, item := range data.Data() {
+		v, err := decodeTyped[T](ti.schema, item)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// Stream gets typed items decoding the responses one item at a time, next pages are requested until the collection ends,
+// see Items.Stream
+func (ti *TypedItems[T]) Stream(ctx context.Context, callback func(item *T) error) error {
+	if ti.err != nil {
+		return ti.err
+	}
+	return ti.items.Stream(ctx, func(item ItemResp) error {
+		v, err := decodeTyped[T](ti.schema, item)
+		if err != nil {
+			return err
+		}
+		return callback(v)
+	})
+}
+
+// decodeTyped decodes list item response to T
+func decodeTyped[T any](schema *typedSchema, item ItemResp) (*T, error) {
+	v := new(T)
+	if err := schema.decode(item.ToMap(), reflect.ValueOf(v).Elem()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// newTypedSchema parses `sp` tags of the struct type
+func newTypedSchema(t reflect.Type) (*typedSchema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("typed list item must be a struct, got %s", t)
+	}
+	schema := &typedSchema{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("sp")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		f := &typedField{index: sf.Index, name: strings.TrimSpace(opts[0])}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range opts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "lookup", "user":
+				f.lookup = true
+			case "readonly":
+				f.readOnly = true
+			case "omitempty":
+				f.omitEmpty = true
+			default:
+				return nil, fmt.Errorf("unknown sp tag option '%s' of %s field", opt, sf.Name)
+			}
+		}
+		if f.lookup {
+			if err := f.parseLookupType(sf.Type); err != nil {
+				return nil, err
+			}
+		}
+		schema.fields = append(schema.fields, f)
+	}
+	return schema, nil
+}
+
+// parseLookupType checks lookup field Go type, resolves the fields to select from the lookup target
+func (f *typedField) parseLookupType(t reflect.Type) error {
+	if t.Kind() == reflect.Slice {
+		f.multi = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		f.idOnly = true
+		return nil
+	case reflect.Struct:
+		hasID := false
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" || !t.Field(i).IsExported() {
+				continue
+			}
+			hasID = hasID || name == "Id"
+			f.subFields = append(f.subFields, name)
+		}
+		if !hasID {
+			return fmt.Errorf("lookup value type %s of %s field must have a field with `json:\"Id\"` tag", t, f.name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported lookup value type %s of %s field", t, f.name)
+}
+
+// selects gets $select OData modifier value
+func (s *typedSchema) selects() string {
+	var sel []string
+	for _, f := range s.fields {
+		switch {
+		case !f.lookup:
+			sel = append(sel, f.name)
+		case f.idOnly:
+			sel = append(sel, f.name+"Id")
+		default:
+			for _, sub := range f.subFields {
+				sel = append(sel, f.name+"/"+sub)
+			}
+		}
+	}
+	return strings.Join(sel, ",")
+}
+
+// expands gets $expand OData modifier value
+func (s *typedSchema) expands() string {
+	var exp []string
+	for _, f := range s.fields {
+		if f.lookup && !f.idOnly {
+			exp = append(exp, f.name)
+		}
+	}
+	return strings.Join(exp, ",")
+}
+
+// decode copies item values to the struct fields
+func (s *typedSchema) decode(item map[string]interface{}, dst reflect.Value) error {
+	item = normalizeMultiLookupsMap(item)
+	for _, f := range s.fields {
+		key := f.name
+		if f.idOnly {
+			key += "Id"
+		}
+		value, ok := item[key]
+		if !ok || value == nil {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, dst.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return fmt.Errorf("can't decode %s field: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// encode gets list item payload from the struct fields
+func (s *typedSchema) encode(src reflect.Value) (map[string]interface{}, error) {
+	payload := map[string]interface{}{}
+	for _, f := range s.fields {
+		if f.readOnly || strings.EqualFold(f.name, "Id") {
+			continue
+		}
+		v := src.FieldByIndex(f.index)
+		if f.omitEmpty && v.IsZero() {
+			continue
+		}
+		if !f.lookup {
+			payload[f.name] = encodeValue(v)
+			continue
+		}
+		if !f.multi {
+			id, err := lookupID(v)
+			if err != nil {
+				return nil, fmt.Errorf("can't encode %s field: %w", f.name, err)
+			}
+			if id == 0 {
+				payload[f.name+"Id"] = nil // clears lookup value
+			} else {
+				payload[f.name+"Id"] = id
+			}
+			continue
+		}
+		ids := []int{}
+		for i := 0; i < v.Len(); i++ {
+			id, err := lookupID(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("can't encode %s field: %w", f.name, err)
+			}
+			ids = append(ids, id)
+		}
+		payload[f.name+"Id"] = map[string]interface{}{"results": ids}
+	}
+	return payload, nil
+}
+
+// encodeValue gets field value payload, string slices are multi-choice collections in verbose mode
+func encodeValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String {
+		return map[string]interface{}{
+			"__metadata": map[string]string{"type": "Collection(Edm.String)"},
+			"results":    v.Interface(),
+		}
+	}
+	return v.Interface()
+}
+
+// lookupID gets lookup ID from ID or lookup value struct
+func lookupID(v reflect.Value) (int, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return int(v.Int()), nil
+	}
+	raw, err := json.Marshal(v.Interface())
+	if err != nil {
+		return 0, err
+	}
+	value := &struct {
+		ID int `json:"Id"`
+	}{}
+	err = json.Unmarshal(raw, value)
+	return value.ID, err
+}
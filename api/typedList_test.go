@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip/api/odata"
+	"github.com/recolabs/gosip/sptest"
+)
+
+type typedTask struct {
+	ID       int           `sp:"Id"`
+	Title    string        `sp:"Title"`
+	Priority int           `sp:"Priority,omitempty"`
+	Done     bool          `sp:"Done"`
+	Tags     []string      `sp:"Tags,omitempty"`
+	Manager  *UserValue    `sp:"Manager,user"`
+	Category []LookupValue `sp:"Category,lookup"`
+	Parent   int           `sp:"Parent,lookup,omitempty"`
+	Created  time.Time     `sp:"Created,readonly"`
+	Ignored  string        `sp:"-"`
+}
+
+func TestTypedSchema(t *testing.T) {
+	schema, err := newTypedSchema(reflect.TypeOf(typedTask{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		sel := "Id,Title,Priority,Done,Tags,Manager/Id,Manager/Title,Manager/EMail,Category/Id,Category/Title,ParentId,Created"
+		if schema.selects() != sel {
+			t.Errorf("unexpected $select: %s", schema.selects())
+		}
+		if schema.expands() != "Manager,Category" {
+			t.Errorf("unexpected $expand: %s", schema.expands())
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		resp := ItemResp(`{"d":{
+			"__metadata":{"type":"SP.Data.TasksListItem"},
+			"Id":1,"Title":"Task","Done":true,
+			"Tags":{"__metadata":{"type":"Collection(Edm.String)"},"results":["a","b"]},
+			"Manager":{"__metadata":{"type":"SP.Data.UserInfoItem"},"Id":7,"Title":"John","EMail":"john@contoso.com"},
+			"Category":{"results":[{"Id":1,"Title":"One"},{"Id":2,"Title":"Two"}]},
+			"ParentId":3,
+			"Created":"2020-01-02T03:04:05Z"
+		}}`)
+		task, err := decodeTyped[typedTask](schema, resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := &typedTask{
+			ID: 1, Title: "Task", Done: true, Tags: []string{"a", "b"},
+			Manager:  &UserValue{ID: 7, Title: "John", EMail: "john@contoso.com"},
+			Category: []LookupValue{{ID: 1, Title: "One"}, {ID: 2, Title: "Two"}},
+			Parent:   3,
+			Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+		if !reflect.DeepEqual(task, expected) {
+			t.Errorf("unexpected decoded item: %+v", task)
+		}
+	})
+
+	t.Run("Encode", func(t *testing.T) {
+		task := &typedTask{
+			ID: 1, Title: "Task", Tags: []string{"a"},
+			Category: []LookupValue{{ID: 1}, {ID: 2}},
+			Created:  time.Now(),
+		}
+		payload, err := schema.encode(reflect.ValueOf(task).Elem())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(payload)
+		expected := `{"CategoryId":{"results":[1,2]},"Done":false,"ManagerId":null,` +
+			`"Tags":{"__metadata":{"type":"Collection(Edm.String)"},"results":["a"]},"Title":"Task"}`
+		if string(data) != expected {
+			t.Errorf("unexpected payload: %s", data)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := newTypedSchema(reflect.TypeOf(struct {
+			Manager string `sp:"Manager,user"`
+		}{})); err == nil {
+			t.Error("string lookup value should not be supported")
+		}
+		if _, err := newTypedSchema(reflect.TypeOf(struct {
+			Title string `sp:"Title,unknown"`
+		}{})); err == nil {
+			t.Error("unknown tag option should fail")
+		}
+	})
+}
+
+func TestTypedList(t *testing.T) {
+	srv := sptest.NewServer()
+	defer srv.Close()
+	if err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	type task struct {
+		ID       int    `sp:"Id"`
+		Title    string `sp:"Title"`
+		Priority int    `sp:"Priority"`
+	}
+
+	ctx := context.Background()
+	list := NewTypedList[task](NewSP(srv.Client()).Web().Lists().GetByTitle("Tasks"))
+
+	for i, title := range []string{"First", "Second", "Third"} {
+		id, err := list.Add(ctx, &task{Title: title, Priority: i + 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i+1 {
+			t.Errorf("unexpected created item ID: %d", id)
+		}
+	}
+
+	if err := list.Update(ctx, 2, &task{Title: "Second (updated)", Priority: 5}); err != nil {
+		t.Fatal(err)
+	}
+	item, err := list.GetByID(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 2 || item.Title != "Second (updated)" || item.Priority != 5 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	items, err := list.Items().Filter("Priority ge 2").OrderBy("Priority", false).Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Title != "Second (updated)" || items[1].Title != "Third" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	items, err = list.Items().FilterExpr(odata.Or(odata.Eq("Title", "First"), odata.StartsWith("Title", "Third"))).Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Title != "First" || items[1].Title != "Third" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	cnt := 0
+	if err := list.Items().Top(1).Stream(ctx, func(item *task) error {
+		cnt++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 3 {
+		t.Errorf("expected 3 streamed items, got %d", cnt)
+	}
+}
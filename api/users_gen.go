@@ -26,6 +26,12 @@ func (users *Users) Filter(oDataFilter string) *Users {
 	return users
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (users *Users) FilterExpr(expr ODataExpr) *Users {
+	users.modifiers.AddFilterExpr(expr)
+	return users
+}
+
 // Top adds $top OData modifier
 func (users *Users) Top(oDataTop int) *Users {
 	users.modifiers.AddTop(oDataTop)
@@ -26,6 +26,12 @@ func (views *Views) Filter(oDataFilter string) *Views {
 	return views
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (views *Views) FilterExpr(expr ODataExpr) *Views {
+	views.modifiers.AddFilterExpr(expr)
+	return views
+}
+
 // Top adds $top OData modifier
 func (views *Views) Top(oDataTop int) *Views {
 	views.modifiers.AddTop(oDataTop)
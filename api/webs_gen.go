@@ -26,6 +26,12 @@ func (webs *Webs) Filter(oDataFilter string) *Webs {
 	return webs
 }
 
+// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+func (webs *Webs) FilterExpr(expr ODataExpr) *Webs {
+	webs.modifiers.AddFilterExpr(expr)
+	return webs
+}
+
 // Top adds $top OData modifier
 func (webs *Webs) Top(oDataTop int) *Webs {
 	webs.modifiers.AddTop(oDataTop)
@@ -16,6 +16,7 @@ import (
 	"os"
 
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
@@ -34,6 +35,7 @@ type AuthCnfg struct {
 	Realm        string `json:"realm"`        // Your SharePoint Online tenant ID (optional)
 
 	masterKey string
+	cache     gosip.Cache
 	client    *http.Client
 }
 
@@ -51,6 +53,10 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
@@ -84,6 +90,20 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 // SetMasterkey defines custom masterkey
 func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
 
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
 // GetAuth authenticates, receives access token
 func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) { return GetAuth(ctx, c) }
 
@@ -99,7 +119,7 @@ func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error
 	if c.client == nil {
 		c.client = &httpClient.Client
 	}
-	authToken, _, err := c.GetAuth(req.Context())
+	authToken, _, err := getAuth(req.Context(), c, c.getCache(httpClient))
 	if err != nil {
 		return err
 	}
@@ -11,11 +11,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/recolabs/gosip"
 )
 
 var (
-	storage      = cache.New(5*time.Minute, 10*time.Minute)
 	accEndpoints = map[spoEnv]string{
 		spoProd:   "accounts.accesscontrol.windows.net",
 		spoGerman: "login.microsoftonline.de",
@@ -27,6 +26,11 @@ var (
 
 // GetAuth gets authentication
 func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
+	return getAuth(ctx, c, c.getCache(nil))
+}
+
+// getAuth gets authentication using the tokens cache
+func getAuth(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, int64, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -36,18 +40,18 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 		return "", 0, err
 	}
 
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.ClientID + "@" + c.ClientSecret
-	if accessToken, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return accessToken.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + gosip.CacheKeyHash(c.ClientID, c.ClientSecret)
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
 	}
 
-	realm, err := getRealm(ctx, c)
+	realm, err := getRealm(ctx, c, cache)
 	if err != nil {
 		return "", 0, err
 	}
 	c.Realm = realm
 
-	authURL, err := getAuthURL(ctx, c, c.Realm)
+	authURL, err := getAuthURL(ctx, c, cache, c.Realm)
 	if err != nil {
 		return "", 0, err
 	}
@@ -107,12 +111,12 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 	expiry := (results.ExpiresIn - 60) * time.Second
 	exp := time.Now().Add(expiry).Unix()
 
-	storage.Set(cacheKey, results.AccessToken, expiry)
+	cache.Set(cacheKey, results.AccessToken, expiry)
 
 	return results.AccessToken, exp, nil
 }
 
-func getAuthURL(ctx context.Context, c *AuthCnfg, realm string) (string, error) {
+func getAuthURL(ctx context.Context, c *AuthCnfg, cache gosip.Cache, realm string) (string, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -121,8 +125,8 @@ func getAuthURL(ctx context.Context, c *AuthCnfg, realm string) (string, error)
 	endpoint := fmt.Sprintf("https://%s/metadata/json/1?realm=%s", accEndpoint, realm)
 
 	cacheKey := endpoint
-	if authURL, found := storage.Get(cacheKey); found {
-		return authURL.(string), nil
+	if authURL, _, found := cache.Get(cacheKey); found {
+		return authURL, nil
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
@@ -162,7 +166,7 @@ func getAuthURL(ctx context.Context, c *AuthCnfg, realm string) (string, error)
 
 	for _, endpoint := range results.Endpoints {
 		if endpoint.Protocol == "OAuth2" {
-			storage.Set(cacheKey, endpoint.Location, 60*time.Minute)
+			cache.Set(cacheKey, endpoint.Location, 60*time.Minute)
 			return endpoint.Location, nil
 		}
 	}
@@ -170,7 +174,7 @@ func getAuthURL(ctx context.Context, c *AuthCnfg, realm string) (string, error)
 	return "", errors.New("no OAuth2 protocol location found")
 }
 
-func getRealm(ctx context.Context, c *AuthCnfg) (string, error) {
+func getRealm(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -184,9 +188,9 @@ func getRealm(ctx context.Context, c *AuthCnfg) (string, error) {
 		return "", err
 	}
 
-	cacheKey := parsedURL.Host + "@realm" + "@addinonly@" + c.ClientID + "@" + c.ClientSecret
-	if realm, found := storage.Get(cacheKey); found {
-		return realm.(string), nil
+	cacheKey := parsedURL.Host + "@realm" + "@addinonly@" + gosip.CacheKeyHash(c.ClientID, c.ClientSecret)
+	if realm, _, found := cache.Get(cacheKey); found {
+		return realm, nil
 	}
 
 	endpoint := c.SiteURL + "/_vti_bin/client.svc"
@@ -217,7 +221,7 @@ func getRealm(ctx context.Context, c *AuthCnfg) (string, error) {
 	for _, part := range strings.Split(authHeader, `",`) {
 		p := strings.Split(part, `="`)
 		if p[0] == "Bearer realm" {
-			storage.Set(cacheKey, p[0], 60*time.Minute)
+			cache.Set(cacheKey, p[0], 60*time.Minute)
 			return p[1], nil
 		}
 	}
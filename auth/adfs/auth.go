@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
@@ -57,6 +58,7 @@ type AuthCnfg struct {
 	AdfsCookie   string `json:"adfsCookie"`
 
 	masterKey string
+	cache     gosip.Cache
 	client    *http.Client
 }
 
@@ -74,6 +76,10 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
@@ -117,6 +123,20 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 // SetMasterkey defines custom masterkey
 func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
 
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
 // GetAuth authenticates, receives access token
 func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) { return GetAuth(ctx, c) }
 
@@ -132,7 +152,7 @@ func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error
 	if c.client == nil {
 		c.client = &httpClient.Client
 	}
-	authCookie, _, err := c.GetAuth(req.Context())
+	authCookie, _, err := getAuth(req.Context(), c, c.getCache(httpClient))
 	if err != nil {
 		return err
 	}
@@ -12,17 +12,17 @@ import (
 	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
-
+	"github.com/recolabs/gosip"
 	"github.com/recolabs/gosip/templates"
 )
 
-var (
-	storage = cache.New(5*time.Minute, 10*time.Minute)
-)
-
 // GetAuth gets authentication
 func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
+	return getAuth(ctx, c, c.getCache(nil))
+}
+
+// getAuth gets authentication using the tokens cache
+func getAuth(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, int64, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -32,9 +32,9 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 		return "", 0, err
 	}
 
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.Username + "@" + c.Password
-	if authCookie, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return authCookie.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + gosip.CacheKeyHash(c.Username, c.Password)
+	if authCookie, exp, found := cache.Get(cacheKey); found {
+		return authCookie, exp.Unix(), nil
 	}
 
 	var authCookie, expires string
@@ -59,7 +59,7 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 	}
 
 	exp := time.Now().Add(expiry).Unix()
-	storage.Set(cacheKey, authCookie, expiry)
+	cache.Set(cacheKey, authCookie, expiry)
 
 	return authCookie, exp, nil
 }
@@ -377,13 +377,13 @@ func doNotCheckRedirect(_ *http.Request, _ []*http.Request) error {
 	return http.ErrUseLastResponse
 }
 
-// CleanAuthCache removes auth cache
+// CleanAuthCache removes auth cache from the custom or the default cache
 func (c *AuthCnfg) CleanAuthCache() error {
 	parsedURL, err := url.Parse(c.SiteURL)
 	if err != nil {
 		return err
 	}
-	cacheKey := parsedURL.Host + "@adfs@" + c.Username + "@" + c.Password
-	storage.Delete(cacheKey)
+	cacheKey := parsedURL.Host + "@adfs@" + gosip.CacheKeyHash(c.Username, c.Password)
+	c.getCache(nil).Delete(cacheKey)
 	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"testing"
 	"time"
+
+	"github.com/recolabs/gosip"
 )
 
 func TestHelpersEdgeCases(t *testing.T) {
@@ -30,14 +32,14 @@ func TestHelpersEdgeCases(t *testing.T) {
 			Password: "password",
 		}
 		parsedURL, _ := url.Parse(cnfg.SiteURL)
-		cacheKey := parsedURL.Host + "@adfs@" + cnfg.Username + "@" + cnfg.Password
-		storage.Set(cacheKey, "token", 1*time.Minute)
+		cacheKey := parsedURL.Host + "@adfs@" + gosip.CacheKeyHash(cnfg.Username, cnfg.Password)
+		cnfg.getCache(nil).Set(cacheKey, "token", 1*time.Minute)
 
 		if err := cnfg.CleanAuthCache(); err != nil {
 			t.Errorf("can't clean auth cache: %s", err)
 		}
 
-		if _, found := storage.Get(cacheKey); found {
+		if _, _, found := cnfg.getCache(nil).Get(cacheKey); found {
 			t.Error("auth cache was not cleaned")
 		}
 	})
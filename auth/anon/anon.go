@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 )
 
 // AuthCnfg - anonymous config structure
@@ -36,6 +37,10 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, "")
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
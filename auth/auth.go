@@ -5,60 +5,83 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/recolabs/gosip"
 	"github.com/recolabs/gosip/auth/addin"
 	"github.com/recolabs/gosip/auth/adfs"
+	"github.com/recolabs/gosip/auth/anon"
 	"github.com/recolabs/gosip/auth/azurecert"
 	"github.com/recolabs/gosip/auth/azurecreds"
+	"github.com/recolabs/gosip/auth/azureenv"
+	"github.com/recolabs/gosip/auth/azurewif"
 	"github.com/recolabs/gosip/auth/device"
 	"github.com/recolabs/gosip/auth/fba"
+	"github.com/recolabs/gosip/auth/msi"
 	"github.com/recolabs/gosip/auth/ntlm"
 	"github.com/recolabs/gosip/auth/saml"
 	"github.com/recolabs/gosip/auth/tmg"
 )
 
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]func() gosip.AuthCnfg{}
+)
+
+func init() {
+	Register("addin", func() gosip.AuthCnfg { return &addin.AuthCnfg{} })
+	Register("adfs", func() gosip.AuthCnfg { return &adfs.AuthCnfg{} })
+	Register("anonymous", func() gosip.AuthCnfg { return &anon.AuthCnfg{} })
+	Register("azurecert", func() gosip.AuthCnfg { return &azurecert.AuthCnfg{} })
+	Register("azurecreds", func() gosip.AuthCnfg { return &azurecreds.AuthCnfg{} })
+	Register("azureenv", func() gosip.AuthCnfg { return &azureenv.AuthCnfg{} })
+	Register("azurewif", func() gosip.AuthCnfg { return &azurewif.AuthCnfg{} })
+	Register("device", func() gosip.AuthCnfg { return &device.AuthCnfg{} })
+	Register("fba", func() gosip.AuthCnfg { return &fba.AuthCnfg{} })
+	Register("msi", func() gosip.AuthCnfg { return &msi.AuthCnfg{} })
+	Register("ntlm", func() gosip.AuthCnfg { return &ntlm.AuthCnfg{} })
+	Register("saml", func() gosip.AuthCnfg { return &saml.AuthCnfg{} })
+	Register("tmg", func() gosip.AuthCnfg { return &tmg.AuthCnfg{} })
+}
+
+// Register makes auth strategy available by its name in NewAuthByStrategy and NewAuthFromFile,
+// the factory must return a new AuthCnfg instance on each call. Registering an existing name replaces the strategy.
+func Register(name string, factory func() gosip.AuthCnfg) {
+	if factory == nil {
+		panic("auth: Register factory is nil for strategy " + name)
+	}
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+}
+
+// Strategies gets sorted names of the registered strategies
+func Strategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // NewAuthByStrategy resolves AuthCnfg object based on strategy name
 func NewAuthByStrategy(strategy string) (gosip.AuthCnfg, error) {
-	var auth gosip.AuthCnfg
-
-	switch strategy {
-	case "azurecert":
-		auth = &azurecert.AuthCnfg{}
-		break
-	case "azurecreds":
-		auth = &azurecreds.AuthCnfg{}
-		break
-	case "device":
-		auth = &device.AuthCnfg{}
-		break
-	case "addin":
-		auth = &addin.AuthCnfg{}
-		break
-	case "adfs":
-		auth = &adfs.AuthCnfg{}
-		break
-	case "fba":
-		auth = &fba.AuthCnfg{}
-		break
-	case "ntlm":
-		auth = &ntlm.AuthCnfg{}
-		break
-	case "saml":
-		auth = &saml.AuthCnfg{}
-		break
-	case "tmg":
-		auth = &tmg.AuthCnfg{}
-		break
-	default:
+	strategiesMu.RLock()
+	factory, ok := strategies[strategy]
+	strategiesMu.RUnlock()
+	if !ok {
 		return nil, fmt.Errorf("can't resolve the strategy: %s", strategy)
 	}
-
-	return auth, nil
+	return factory(), nil
 }
 
 // NewAuthFromFile resolves AuthCnfg object based on private file
-// private.json must contain "strategy" property along with strategy-specific properties
+// private.json must contain "strategy" property of any registered strategy along with strategy-specific properties
 func NewAuthFromFile(privateFile string) (gosip.AuthCnfg, error) {
 	jsonFile, err := os.Open(privateFile)
 	if err != nil {
@@ -89,3 +112,47 @@ func NewAuthFromFile(privateFile string) (gosip.AuthCnfg, error) {
 
 	return auth, nil
 }
+
+// DefaultEnvPrefix is the environment variables prefix used by NewAuthFromEnv when no prefix is provided
+const DefaultEnvPrefix = "SPAUTH"
+
+// NewAuthFromEnv resolves AuthCnfg object based on environment variables with the prefix, e.g. for "SPAUTH" prefix
+// SPAUTH_STRATEGY defines the strategy and SPAUTH_SITEURL, SPAUTH_CLIENTID or SPAUTH_CLIENT_ID define config properties.
+// Variable names are matched to config properties ignoring case and underscores, values can be secret references.
+func NewAuthFromEnv(prefix string) (gosip.AuthCnfg, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	config := map[string]string{}
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(strings.ToUpper(kv[0]), strings.ToUpper(prefix)) {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(kv[0][len(prefix):], "_", ""))
+		if key != "" {
+			config[key] = kv[1]
+		}
+	}
+
+	strategy := config["strategy"]
+	if strategy == "" {
+		return nil, fmt.Errorf("can't resolve the strategy: %sSTRATEGY environment variable is not set", prefix)
+	}
+	auth, err := NewAuthByStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	byteValue, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.ParseConfig(byteValue); err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
@@ -1,18 +1,32 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/saml"
 )
 
 func TestAuthResolver(t *testing.T) {
 	strategies := []string{
 		"azurecert",
 		"azurecreds",
+		"azureenv",
+		"azurewif",
+		"anonymous",
 		"device",
 		"addin",
 		"adfs",
 		"fba",
+		"msi",
 		"ntlm",
 		"saml",
 		"tmg",
@@ -68,3 +82,148 @@ func TestAuthFileResolver(t *testing.T) {
 		t.Errorf("strategy should be saml, but %s", cnfg.GetStrategy())
 	}
 }
+
+type proxyAuthCnfg struct {
+	SiteURL string `json:"siteUrl"`
+	Token   string `json:"token"`
+}
+
+func (c *proxyAuthCnfg) ReadConfig(privateFile string) error  { return nil }
+func (c *proxyAuthCnfg) WriteConfig(privateFile string) error { return nil }
+func (c *proxyAuthCnfg) ParseConfig(data []byte) error        { return json.Unmarshal(data, c) }
+func (c *proxyAuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
+	return c.Token, 0, nil
+}
+func (c *proxyAuthCnfg) GetSiteURL() string  { return c.SiteURL }
+func (c *proxyAuthCnfg) GetStrategy() string { return "proxy" }
+func (c *proxyAuthCnfg) SetAuth(req *http.Request, client *gosip.SPClient) error {
+	req.Header.Set("X-Proxy-Token", c.Token)
+	return nil
+}
+
+func TestAuthRegister(t *testing.T) {
+	Register("proxy", func() gosip.AuthCnfg { return &proxyAuthCnfg{} })
+
+	file, err := os.CreateTemp("", "private.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write([]byte(`{"strategy": "proxy", "siteUrl": "https://contoso.sharepoint.com", "token": "secret"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	cnfg, err := NewAuthFromFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy, ok := cnfg.(*proxyAuthCnfg)
+	if !ok || proxy.Token != "secret" || proxy.GetSiteURL() != "https://contoso.sharepoint.com" {
+		t.Errorf("unexpected auth config: %+v", cnfg)
+	}
+
+	another, _ := NewAuthByStrategy("proxy")
+	if another == cnfg {
+		t.Error("factory should create new instances")
+	}
+
+	found := false
+	for _, name := range Strategies() {
+		found = found || name == "proxy"
+	}
+	if !found {
+		t.Errorf("proxy strategy is not listed: %v", Strategies())
+	}
+
+	t.Run("NilFactory", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("should panic on nil factory")
+			}
+		}()
+		Register("nil", nil)
+	})
+}
+
+func TestAuthEnvResolver(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOSIP_TEST_STRATEGY", "saml")
+	t.Setenv("GOSIP_TEST_SITE_URL", "https://contoso.sharepoint.com")
+	t.Setenv("GOSIP_TEST_USERNAME", "user@contoso.onmicrosoft.com")
+	t.Setenv("GOSIP_TEST_PASSWORD", "file:"+secretFile)
+
+	cnfg, err := NewAuthFromEnv("GOSIP_TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	samlCnfg, ok := cnfg.(*saml.AuthCnfg)
+	if !ok {
+		t.Fatalf("strategy should be saml, but %s", cnfg.GetStrategy())
+	}
+	if samlCnfg.SiteURL != "https://contoso.sharepoint.com" || samlCnfg.Username != "user@contoso.onmicrosoft.com" || samlCnfg.Password != "secret" {
+		t.Errorf("unexpected config: %+v", samlCnfg)
+	}
+
+	if _, err := NewAuthFromEnv("GOSIP_TEST_MISSING"); err == nil {
+		t.Error("should return an error when strategy is not set")
+	}
+}
+
+// keysCache records cache keys and reports every key as found
+type keysCache struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (c *keysCache) Get(key string) (string, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = append(c.keys, key)
+	return "token", time.Now().Add(time.Hour), true
+}
+func (c *keysCache) Set(key string, value string, ttl time.Duration) {}
+func (c *keysCache) Delete(key string)                               {}
+func (c *keysCache) Flush()                                          {}
+
+func TestAuthCacheKeys(t *testing.T) {
+	secret := "not-a-real-secret"
+	config := []byte(`{
+		"siteUrl": "https://contoso.sharepoint.com",
+		"username": "user@contoso.onmicrosoft.com",
+		"password": "` + secret + `",
+		"clientId": "00000000-0000-0000-0000-000000000000",
+		"clientSecret": "` + secret + `",
+		"tenantId": "contoso.onmicrosoft.com"
+	}`)
+
+	for _, strategy := range []string{"addin", "adfs", "azurecreds", "fba", "saml", "tmg"} {
+		t.Run(strategy, func(t *testing.T) {
+			cnfg, err := NewAuthByStrategy(strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cnfg.ParseConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			// Tokens cache is resolved from the client when no custom cache is set
+			cache := &keysCache{}
+			client := &gosip.SPClient{AuthCnfg: cnfg, Cache: cache}
+			req, _ := http.NewRequest("GET", "https://contoso.sharepoint.com/_api/web", nil)
+			if err := cnfg.SetAuth(req, client); err != nil {
+				t.Fatal(err)
+			}
+			if len(cache.keys) == 0 {
+				t.Fatal("client's cache is not used")
+			}
+			for _, key := range cache.keys {
+				if strings.Contains(key, secret) {
+					t.Errorf("cache key contains the secret: %s", key)
+				}
+			}
+		})
+	}
+}
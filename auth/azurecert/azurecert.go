@@ -21,15 +21,11 @@ import (
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
-	"github.com/patrickmn/go-cache"
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
-var (
-	storage = cache.New(5*time.Minute, 10*time.Minute)
-)
-
 // AuthCnfg - AAD Certificate Auth Flow
 /* Config sample:
 {
@@ -50,6 +46,7 @@ type AuthCnfg struct {
 	authorizer  autorest.Authorizer
 	privateFile string
 	masterKey   string
+	cache       gosip.Cache
 }
 
 // ReadConfig reads private config with auth options
@@ -66,10 +63,16 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
-	c.CertPath = path.Join(path.Dir(c.privateFile), c.CertPath)
+	if !path.IsAbs(c.CertPath) {
+		c.CertPath = path.Join(path.Dir(c.privateFile), c.CertPath)
+	}
 	crypt := cpass.Cpass(c.masterKey)
 	secret, err := crypt.Decode(c.CertPass)
 	if err == nil {
@@ -96,11 +99,34 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 	return os.WriteFile(privateFile, file, 0644)
 }
 
+// SetConfigPath defines config file location, relative paths of the config are resolved against its folder.
+// ReadConfig sets it automatically, use it when the config is parsed from another source, e.g. a profiles file
+func (c *AuthCnfg) SetConfigPath(privateFile string) { c.privateFile = privateFile }
+
 // SetMasterkey defines custom masterkey
 func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
 
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
 // GetAuth authenticates, receives access token
 func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
+	return c.getAuth(ctx, c.getCache(nil))
+}
+
+// getAuth authenticates using the tokens cache
+func (c *AuthCnfg) getAuth(ctx context.Context, cache gosip.Cache) (string, int64, error) {
 	if c.authorizer == nil {
 		u, _ := url.Parse(c.SiteURL)
 		resource := fmt.Sprintf("https://%s", u.Host)
@@ -121,7 +147,7 @@ func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
 	// }
 	// return token.Token().AccessToken, token.Token().Expires().Unix(), nil
 
-	return c.getToken(ctx)
+	return c.getToken(ctx, cache)
 }
 
 // GetSiteURL gets SharePoint siteURL
@@ -133,7 +159,7 @@ func (c *AuthCnfg) GetStrategy() string { return "azurecert" }
 // SetAuth authenticates request
 // noinspection GoUnusedParameter
 func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error {
-	authToken, _, err := c.GetAuth(req.Context())
+	authToken, _, err := c.getAuth(req.Context(), c.getCache(httpClient))
 	if err != nil {
 		return err
 	}
@@ -143,15 +169,15 @@ func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error
 }
 
 // Getting token with prepare for external usage scenarious
-func (c *AuthCnfg) getToken(ctx context.Context) (string, int64, error) {
+func (c *AuthCnfg) getToken(ctx context.Context, cache gosip.Cache) (string, int64, error) {
 	// Get from cache
 	parsedURL, err := url.Parse(c.SiteURL)
 	if err != nil {
 		return "", 0, err
 	}
 	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.TenantID + "@" + c.ClientID
-	if accessToken, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return accessToken.(string), exp.Unix(), nil
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
 	}
 
 	// Get token
@@ -176,7 +202,7 @@ func (c *AuthCnfg) getToken(ctx context.Context) (string, int64, error) {
 
 	// Save to cache
 	exp := time.Unix(j.Exp, 0).Add(-60 * time.Second)
-	storage.Set(cacheKey, token, time.Until(exp))
+	cache.Set(cacheKey, token, time.Until(exp))
 
 	// fmt.Println(time.Until(exp))
 
@@ -20,15 +20,11 @@ import (
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
-	"github.com/patrickmn/go-cache"
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
-var (
-	storage = cache.New(5*time.Minute, 10*time.Minute)
-)
-
 // AuthCnfg - AAD Username/Password Auth Flow
 // To use this strategy public client flows mobile and desktop should be enabled in the app registration
 /* Config sample:
@@ -49,6 +45,7 @@ type AuthCnfg struct {
 
 	authorizer autorest.Authorizer
 	masterKey  string
+	cache      gosip.Cache
 }
 
 // ReadConfig reads private config with auth options
@@ -64,6 +61,10 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
@@ -96,8 +97,27 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 // SetMasterkey defines custom masterkey
 func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
 
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
 // GetAuth authenticates, receives access token
 func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
+	return c.getAuth(ctx, c.getCache(nil))
+}
+
+// getAuth authenticates using the tokens cache
+func (c *AuthCnfg) getAuth(ctx context.Context, cache gosip.Cache) (string, int64, error) {
 	if c.authorizer == nil {
 		u, _ := url.Parse(c.SiteURL)
 		resource := fmt.Sprintf("https://%s", u.Host)
@@ -118,7 +138,7 @@ func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
 	// }
 	// return token.Token().AccessToken, token.Token().Expires().Unix(), nil
 
-	return c.getToken(ctx)
+	return c.getToken(ctx, cache)
 }
 
 // GetSiteURL gets SharePoint siteURL
@@ -130,7 +150,7 @@ func (c *AuthCnfg) GetStrategy() string { return "azurecreds" }
 // SetAuth authenticates request
 // noinspection GoUnusedParameter
 func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error {
-	authToken, _, err := c.GetAuth(req.Context())
+	authToken, _, err := c.getAuth(req.Context(), c.getCache(httpClient))
 	if err != nil {
 		return err
 	}
@@ -140,15 +160,15 @@ func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error
 }
 
 // Getting token with prepare for external usage scenarious
-func (c *AuthCnfg) getToken(ctx context.Context) (string, int64, error) {
+func (c *AuthCnfg) getToken(ctx context.Context, cache gosip.Cache) (string, int64, error) {
 	// Get from cache
 	parsedURL, err := url.Parse(c.SiteURL)
 	if err != nil {
 		return "", 0, err
 	}
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.TenantID + "@" + c.ClientID + "@" + c.Username + "@" + c.Password
-	if accessToken, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return accessToken.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.TenantID + "@" + c.ClientID + "@" + gosip.CacheKeyHash(c.Username, c.Password)
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
 	}
 
 	// Get token
@@ -173,7 +193,7 @@ func (c *AuthCnfg) getToken(ctx context.Context) (string, int64, error) {
 
 	// Save to cache
 	exp := time.Unix(j.Exp, 0).Add(-60 * time.Second)
-	storage.Set(cacheKey, token, time.Until(exp))
+	cache.Set(cacheKey, token, time.Until(exp))
 
 	// fmt.Println(time.Until(exp))
 
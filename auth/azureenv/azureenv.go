@@ -29,6 +29,7 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
@@ -61,12 +62,16 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
 	if err := json.Unmarshal(byteValue, &c); err != nil {
 		return err
 	}
 	crypt := cpass.Cpass(c.masterKey)
 	for key, val := range c.Env {
-		if key == "AZURE_AUTH_LOCATION" || key == "AZURE_CERTIFICATE_PATH" {
+		if (key == "AZURE_AUTH_LOCATION" || key == "AZURE_CERTIFICATE_PATH") && !path.IsAbs(val) {
 			c.Env[key] = path.Join(path.Dir(c.privateFile), val)
 		}
 		if strings.Contains(strings.ToLower(key), "_password") || strings.Contains(strings.ToLower(key), "_secret") {
@@ -86,6 +91,10 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 	return os.WriteFile(privateFile, file, 0644)
 }
 
+// SetConfigPath defines config file location, relative paths of the config are resolved against its folder.
+// ReadConfig sets it automatically, use it when the config is parsed from another source, e.g. a profiles file
+func (c *AuthCnfg) SetConfigPath(privateFile string) { c.privateFile = privateFile }
+
 // SetMasterkey defines custom masterkey
 func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
 
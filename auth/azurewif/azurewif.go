@@ -0,0 +1,245 @@
+// Package azurewif implements AAD Workload Identity Federation Auth Flow,
+// app-only tokens are requested with a client assertion read from a federated token file,
+// e.g. Kubernetes workload identity or GitHub Actions OIDC token
+// See more:
+//   - https://learn.microsoft.com/en-us/entra/workload-id/workload-identity-federation
+//
+// Amongst supported platform versions are:
+//   - SharePoint Online + Azure
+package azurewif
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
+)
+
+// Default authority host, AZURE_AUTHORITY_HOST environment variable or authorityHost config property override it
+const defaultAuthorityHost = "https://login.microsoftonline.com/"
+
+// tokenClient requests tokens from the authority, SharePoint client transport is not used for token endpoints
+var tokenClient = &http.Client{Timeout: 30 * time.Second}
+
+// AuthCnfg - AAD Workload Identity Federation Auth Flow
+/* Config sample:
+{
+	"siteUrl": "https://contoso.sharepoint.com/sites/test",
+	"tenantId": "e4d43069-8ecb-49c4-8178-5bec83c53e9d",
+	"clientId": "628cc712-c9a4-48f0-a059-af64bdbb4be5",
+	"tokenFile": "/var/run/secrets/azure/tokens/azure-identity-token"
+}
+Empty tenantId, clientId, tokenFile and authorityHost are taken from AZURE_TENANT_ID, AZURE_CLIENT_ID,
+AZURE_FEDERATED_TOKEN_FILE and AZURE_AUTHORITY_HOST environment variables, as set by the workload identity webhook.
+*/
+type AuthCnfg struct {
+	SiteURL       string `json:"siteUrl"`       // SPSite or SPWeb URL, which is the context target for the API calls
+	TenantID      string `json:"tenantId"`      // Azure Tenant ID
+	ClientID      string `json:"clientId"`      // Azure Client ID of the app with the federated credential
+	TokenFile     string `json:"tokenFile"`     // Federated token file location, the file is read on each token request as it is rotated
+	AuthorityHost string `json:"authorityHost"` // Azure AD authority host, "https://login.microsoftonline.com/" by default
+
+	masterKey   string
+	privateFile string
+	cache       gosip.Cache
+}
+
+// ReadConfig reads private config with auth options
+func (c *AuthCnfg) ReadConfig(privateFile string) error {
+	c.privateFile = privateFile
+	f, err := os.Open(privateFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	byteValue, _ := io.ReadAll(f)
+	return c.ParseConfig(byteValue)
+}
+
+// ParseConfig parses credentials from a provided JSON byte array content
+func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(byteValue, &c); err != nil {
+		return err
+	}
+	// Token file from the environment is used as is
+	if c.TokenFile != "" && !path.IsAbs(c.TokenFile) {
+		c.TokenFile = path.Join(path.Dir(c.privateFile), c.TokenFile)
+	}
+	return nil
+}
+
+// WriteConfig writes private config with auth options
+func (c *AuthCnfg) WriteConfig(privateFile string) error {
+	config := &AuthCnfg{
+		SiteURL:       c.SiteURL,
+		TenantID:      c.TenantID,
+		ClientID:      c.ClientID,
+		TokenFile:     c.TokenFile,
+		AuthorityHost: c.AuthorityHost,
+	}
+	file, _ := json.MarshalIndent(config, "", "  ")
+	return os.WriteFile(privateFile, file, 0644)
+}
+
+// SetConfigPath defines config file location, relative token file path is resolved against its folder.
+// ReadConfig sets it automatically, use it when the config is parsed from another source, e.g. a profiles file
+func (c *AuthCnfg) SetConfigPath(privateFile string) { c.privateFile = privateFile }
+
+// SetMasterkey defines custom masterkey
+func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
+
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
+// GetAuth authenticates, receives access token
+func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
+	return c.getToken(ctx, c.getCache(nil))
+}
+
+// GetSiteURL gets SharePoint siteURL
+func (c *AuthCnfg) GetSiteURL() string { return c.SiteURL }
+
+// GetStrategy gets auth strategy name
+func (c *AuthCnfg) GetStrategy() string { return "azurewif" }
+
+// SetAuth authenticates request
+// noinspection GoUnusedParameter
+func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error {
+	authToken, _, err := c.getToken(req.Context(), c.getCache(httpClient))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	return nil
+}
+
+// getToken gets cached token or requests a new one with the federated token as client assertion
+func (c *AuthCnfg) getToken(ctx context.Context, cache gosip.Cache) (string, int64, error) {
+	tenantID := valueOrEnv(c.TenantID, "AZURE_TENANT_ID")
+	clientID := valueOrEnv(c.ClientID, "AZURE_CLIENT_ID")
+	tokenFile := valueOrEnv(c.TokenFile, "AZURE_FEDERATED_TOKEN_FILE")
+	authorityHost := valueOrEnv(c.AuthorityHost, "AZURE_AUTHORITY_HOST")
+	if authorityHost == "" {
+		authorityHost = defaultAuthorityHost
+	}
+	if tenantID == "" || clientID == "" || tokenFile == "" {
+		return "", 0, fmt.Errorf("tenantId, clientId and tokenFile are required")
+	}
+
+	// Get from cache
+	parsedURL, err := url.Parse(c.SiteURL)
+	if err != nil {
+		return "", 0, err
+	}
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + strings.TrimSuffix(authorityHost, "/") + "@" + tenantID + "@" + clientID
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
+	}
+
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", 0, fmt.Errorf("can't read federated token: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", "client_credentials")
+	params.Set("client_id", clientID)
+	params.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	params.Set("client_assertion", strings.TrimSpace(string(assertion)))
+	params.Set("scope", fmt.Sprintf("https://%s/.default", parsedURL.Host))
+
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(authorityHost, "/"), tenantID)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := tokenClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	results := &struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+		Error       string      `json:"error"`
+		Description string      `json:"error_description"`
+	}{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return "", 0, fmt.Errorf("can't parse token response, status %d: %w", resp.StatusCode, err)
+	}
+	if results.Error != "" || results.AccessToken == "" {
+		return "", 0, fmt.Errorf("can't get token, status %d: %s %s", resp.StatusCode, results.Error, results.Description)
+	}
+	token := results.AccessToken
+	expiration, err := tokenExpiration(token, results.ExpiresIn)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Save to cache, tokens which are about to expire are not cached
+	exp := expiration.Add(-60 * time.Second)
+	if ttl := time.Until(exp); ttl > 0 {
+		cache.Set(cacheKey, token, ttl)
+	}
+
+	return token, exp.Unix(), nil
+}
+
+// tokenExpiration gets token expiration from JWT exp claim falling back to expires_in of the token response
+func tokenExpiration(token string, expiresIn json.Number) (time.Time, error) {
+	if tt := strings.Split(token, "."); len(tt) == 3 {
+		if jsonBytes, err := base64.RawURLEncoding.DecodeString(tt[1]); err == nil {
+			j := struct {
+				Exp int64 `json:"exp"`
+			}{}
+			if err := json.Unmarshal(jsonBytes, &j); err == nil && j.Exp > 0 {
+				return time.Unix(j.Exp, 0), nil
+			}
+		}
+	}
+	if seconds, err := expiresIn.Int64(); err == nil && seconds > 0 {
+		return time.Now().Add(time.Duration(seconds) * time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("can't get token expiration, no exp claim and expires_in in the response")
+}
+
+// valueOrEnv gets config value falling back to the environment variable
+func valueOrEnv(value string, envName string) string {
+	if value == "" {
+		return os.Getenv(envName)
+	}
+	return value
+}
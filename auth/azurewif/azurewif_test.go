@@ -0,0 +1,243 @@
+package azurewif
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/cpass"
+)
+
+// testJWT creates unsigned JWT with the expiration and the token ID
+func testJWT(exp time.Time, id int) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d,"jti":"token-%d"}`, exp.Unix(), id))) + ".sig"
+}
+
+func TestGettingAuthToken(t *testing.T) {
+	tokenExp := time.Now().Add(time.Hour).Truncate(time.Second)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = r.ParseForm()
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" {
+			w.WriteHeader(404)
+			return
+		}
+		if r.Form.Get("client_assertion") != fmt.Sprintf("federated-%d", requests) ||
+			r.Form.Get("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" ||
+			r.Form.Get("client_id") != "client" ||
+			r.Form.Get("scope") != "https://contoso.sharepoint.com/.default" {
+			w.WriteHeader(400)
+			_, _ = fmt.Fprintf(w, `{"error":"invalid_client","error_description":"unexpected request %s"}`, r.Form.Encode())
+			return
+		}
+		// expiration is taken from the token, expires_in is not required
+		_, _ = fmt.Fprintf(w, `{"token_type":"Bearer","access_token":"%s"}`, testJWT(tokenExp, requests))
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeToken := func(token string) {
+		if err := os.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeToken("federated-1")
+
+	cnfg := &AuthCnfg{}
+	err := cnfg.ParseConfig([]byte(fmt.Sprintf(`{
+		"siteUrl": "https://contoso.sharepoint.com/sites/test",
+		"tenantId": "tenant",
+		"clientId": "client",
+		"tokenFile": "%s",
+		"authorityHost": "%s/"
+	}`, filepath.ToSlash(tokenFile), srv.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := gosip.NewMemoryCache()
+	cnfg.SetCache(cache)
+
+	token, exp, err := cnfg.GetAuth(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != testJWT(tokenExp, 1) || exp != tokenExp.Add(-60*time.Second).Unix() {
+		t.Errorf("unexpected token: %s, %d", token, exp)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		if token, _, _ := cnfg.GetAuth(context.Background()); token != testJWT(tokenExp, 1) || requests != 1 {
+			t.Errorf("token should be taken from cache, got %s after %d requests", token, requests)
+		}
+	})
+
+	t.Run("CacheByAuthority", func(t *testing.T) {
+		another := *cnfg
+		another.AuthorityHost = "http://127.0.0.1:1/"
+		if _, _, err := another.GetAuth(context.Background()); err == nil {
+			t.Error("token of another authority should not be taken from cache")
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		cache.Flush()
+		writeToken("federated-2")
+		if token, _, err := cnfg.GetAuth(context.Background()); err != nil || token != testJWT(tokenExp, 2) {
+			t.Errorf("rotated federated token should be used, got %s: %v", token, err)
+		}
+	})
+
+	t.Run("ClientCache", func(t *testing.T) {
+		fresh := &AuthCnfg{SiteURL: cnfg.SiteURL, TenantID: "tenant", ClientID: "client", TokenFile: tokenFile, AuthorityHost: srv.URL}
+		req, _ := http.NewRequest("GET", cnfg.SiteURL+"/_api/web", nil)
+		for i, client := range []*gosip.SPClient{
+			{Cache: gosip.NewMemoryCache()},
+			{Cache: gosip.NewMemoryCache()},
+		} {
+			writeToken(fmt.Sprintf("federated-%d", requests+1))
+			for j := 0; j < 2; j++ {
+				if err := fresh.SetAuth(req, client); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if requests != 3+i {
+				t.Errorf("each client's cache should be used, got %d requests", requests)
+			}
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		cache.Flush()
+		writeToken("wrong")
+		if _, _, err := cnfg.GetAuth(context.Background()); err == nil {
+			t.Error("should fail on token endpoint error")
+		}
+	})
+}
+
+func TestTokenExpiration(t *testing.T) {
+	enc := base64.RawURLEncoding
+	noExpJWT := enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(`{"jti":"token"}`)) + ".sig"
+	response := ""
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, response)
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cnfg := &AuthCnfg{
+		SiteURL:       "https://contoso.sharepoint.com/sites/test",
+		TenantID:      "tenant",
+		ClientID:      "client",
+		TokenFile:     tokenFile,
+		AuthorityHost: srv.URL,
+	}
+	cache := gosip.NewMemoryCache()
+	cnfg.SetCache(cache)
+
+	t.Run("ExpiresIn", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"token_type":"Bearer","access_token":"%s","expires_in":3599}`, noExpJWT)
+		requests = 0
+		_, exp, err := cnfg.GetAuth(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := time.Now().Add(3539 * time.Second).Unix(); exp < expected-5 || exp > expected+5 {
+			t.Errorf("expiration should be taken from expires_in, got %d", exp)
+		}
+		if _, _, err := cnfg.GetAuth(context.Background()); err != nil || requests != 1 {
+			t.Errorf("token should be cached, got %d requests: %v", requests, err)
+		}
+	})
+
+	t.Run("NoExpiration", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"token_type":"Bearer","access_token":"%s"}`, noExpJWT)
+		if _, _, err := cnfg.GetAuth(context.Background()); err == nil {
+			t.Error("should fail on token without expiration")
+		}
+	})
+
+	t.Run("AboutToExpire", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"token_type":"Bearer","access_token":"%s"}`, testJWT(time.Now().Add(30*time.Second), 1))
+		requests = 0
+		for i := 0; i < 2; i++ {
+			if _, _, err := cnfg.GetAuth(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if requests != 2 {
+			t.Errorf("token which is about to expire should not be cached, got %d requests", requests)
+		}
+	})
+}
+
+func TestAuthEdgeCases(t *testing.T) {
+	t.Run("ReadConfig/MissedConfig", func(t *testing.T) {
+		cnfg := &AuthCnfg{}
+		if err := cnfg.ReadConfig("wrong_path.json"); err == nil {
+			t.Error("wrong_path config should not pass")
+		}
+	})
+
+	t.Run("ParseConfig/Masterkey", func(t *testing.T) {
+		encoded, err := cpass.Cpass("key").Encode("client")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cnfg := &AuthCnfg{}
+		cnfg.SetMasterkey("key")
+		if err := cnfg.ParseConfig([]byte(`{"clientId":"cpass:` + encoded + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if cnfg.ClientID != "client" {
+			t.Errorf("cpass reference should be decoded with the masterkey, got %s", cnfg.ClientID)
+		}
+	})
+
+	t.Run("WriteConfig", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "private.azurewif.json")
+		cnfg := &AuthCnfg{SiteURL: "test", TokenFile: "/var/run/token"}
+		if err := cnfg.WriteConfig(filePath); err != nil {
+			t.Fatal(err)
+		}
+		read := &AuthCnfg{}
+		if err := read.ReadConfig(filePath); err != nil || read.TokenFile != "/var/run/token" {
+			t.Errorf("unexpected config: %+v, %v", read, err)
+		}
+	})
+
+	t.Run("EnvDefaults", func(t *testing.T) {
+		t.Setenv("AZURE_TENANT_ID", "")
+		t.Setenv("AZURE_CLIENT_ID", "")
+		t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+		cnfg := &AuthCnfg{SiteURL: "https://contoso.sharepoint.com"}
+		if _, _, err := cnfg.GetAuth(context.Background()); err == nil {
+			t.Error("should fail without tenant, client and token file")
+		}
+	})
+
+	t.Run("GetStrategy", func(t *testing.T) {
+		cnfg := &AuthCnfg{}
+		if cnfg.GetStrategy() != "azurewif" {
+			t.Errorf(`wrong strategy name, expected "azurewif" got "%s"`, cnfg.GetStrategy())
+		}
+	})
+}
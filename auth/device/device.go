@@ -13,16 +13,23 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
 	"github.com/recolabs/gosip/cpass"
 )
 
 var (
-	tokenCache = map[string]*adal.ServicePrincipalToken{} // ToDo: Replace with sync.Map
-	crypter    = cpass.Cpass("")
+	crypter = cpass.Cpass("")
+	// diskCache keeps tokens between runs as device flow is interactive
+	diskCache = gosip.NewFileCache(filepath.Join(os.TempDir(), "gosip"))
+	// tokens keeps deserialized tokens in process by cache key, so the cache is read on the first request only
+	tokens sync.Map
+	// tokenCaches keeps the caches tokens were written to by cache key, for the tokens to be cleaned
+	tokenCaches sync.Map
 )
 
 // AuthCnfg - AAD Device Flow auth config structure
@@ -37,6 +44,8 @@ type AuthCnfg struct {
 	SiteURL  string `json:"siteUrl"`  // SPSite or SPWeb URL, which is the context target for the API calls
 	ClientID string `json:"clientId"` // Azure AD App Registration Client ID
 	TenantID string `json:"tenantId"` // Azure AD App Registration Tenant ID
+
+	cache gosip.Cache
 }
 
 // ReadConfig reads private config with auth options
@@ -52,6 +61,10 @@ func (c *AuthCnfg) ReadConfig(privateFile string) error {
 
 // ParseConfig parses credentials from a provided JSON byte array content
 func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, "")
+	if err != nil {
+		return err
+	}
 	return json.Unmarshal(byteValue, &c)
 }
 
@@ -68,26 +81,23 @@ func (c *AuthCnfg) WriteConfig(privateFile string) error {
 
 // GetAuth authenticates, receives access token
 func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
-	u, _ := url.Parse(c.SiteURL)
-	resource := fmt.Sprintf("https://%s", u.Host)
-
-	// Check cached token per resource
-	token := tokenCache[resource]
+	return c.getAuth(ctx, c.getCache(nil))
+}
 
-	// Check disk cache
-	if token == nil {
-		token, _ = c.getTokenDiskCache()
-	}
+// getAuth authenticates using the tokens cache
+func (c *AuthCnfg) getAuth(ctx context.Context, cache gosip.Cache) (string, int64, error) {
+	cacheKey := c.getCacheKey(c.getResource())
 
-	if token != nil {
+	// Check cached token per resource
+	if token := c.getCachedToken(cache, cacheKey); token != nil {
 		// Return cached token if not expired
 		if !token.Token().IsExpired() {
 			return token.Token().AccessToken, token.Token().Expires().Unix(), nil
 		}
 		// Expired, try to refresh
-		if err := token.Refresh(); err == nil {
+		if err := token.RefreshWithContext(ctx); err == nil {
 			// Cache refreshed token
-			_ = c.cacheTokenToDisk(token)
+			_ = c.cacheToken(cache, cacheKey, token)
 			// Return refreshed token
 			return token.Token().AccessToken, token.Token().Expires().Unix(), nil
 		}
@@ -95,16 +105,15 @@ func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
 	}
 
 	config := auth.NewDeviceFlowConfig(c.ClientID, c.TenantID)
-	config.Resource = resource
+	config.Resource = c.getResource()
 
-	token, err := config.ServicePrincipalToken(ctx)
+	token, err := config.ServicePrincipalToken()
 	if err != nil {
 		return "", 0, err
 	}
 
-	_ = c.cacheTokenToDisk(token)
+	_ = c.cacheToken(cache, cacheKey, token)
 
-	tokenCache[resource] = token
 	return token.Token().AccessToken, token.Token().Expires().Unix(), nil
 }
 
@@ -117,7 +126,7 @@ func (c *AuthCnfg) GetStrategy() string { return "device" }
 // SetAuth authenticates request
 // noinspection GoUnusedParameter
 func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error {
-	accessToken, _, err := c.GetAuth(req.Context())
+	accessToken, _, err := c.getAuth(req.Context(), c.getCache(httpClient))
 	if err != nil {
 		return err
 	}
@@ -125,58 +134,80 @@ func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error
 	return nil
 }
 
-// === File system token caching helpers === //
+// SetCache defines custom tokens cache, the client's cache is used by default,
+// provide a file cache for the tokens to be kept between runs
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// === Token caching helpers === //
 
 // CleanTokenCache removes token information
 func (c *AuthCnfg) CleanTokenCache() error {
-	tokenCachePath := c.getTokenCachePath()
-
-	delete(tokenCache, c.ClientID)
-	if err := os.Remove(tokenCachePath); err != nil {
-		return err
+	cacheKey := c.getCacheKey(c.getResource())
+	tokens.Delete(cacheKey)
+	if cache, ok := tokenCaches.LoadAndDelete(cacheKey); ok {
+		cache.(gosip.Cache).Delete(cacheKey)
 	}
+	c.getCache(nil).Delete(cacheKey)
 	return nil
 }
 
-// cacheTokenToDisk writes serialized token to temporary cache file
-func (c *AuthCnfg) cacheTokenToDisk(token *adal.ServicePrincipalToken) error {
-	tmpDir := filepath.Join(os.TempDir(), "gosip")
-	tokenCachePath := c.getTokenCachePath()
+// getCache gets tokens cache, the custom cache goes first, then the client's cache,
+// the file cache in the temp folder is used when no client is provided
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return diskCache
+}
 
+// cacheToken keeps the token in process and writes encrypted serialized token to the cache,
+// the token is stored with no expiration as its refresh token outlives the access token
+func (c *AuthCnfg) cacheToken(cache gosip.Cache, cacheKey string, token *adal.ServicePrincipalToken) error {
+	tokens.Store(cacheKey, token)
 	tokenCache, err := token.MarshalJSON()
 	if err != nil {
 		return err
 	}
-	tokenCacheE, _ := crypter.Encode(string(tokenCache))
-	tokenCache = []byte(tokenCacheE)
-
-	_ = os.MkdirAll(tmpDir, os.ModePerm)
-	if err := os.WriteFile(tokenCachePath, tokenCache, 0644); err != nil {
+	tokenCacheE, err := crypter.Encode(string(tokenCache))
+	if err != nil {
 		return err
 	}
+	cache.Set(cacheKey, tokenCacheE, 0)
+	tokenCaches.Store(cacheKey, cache)
 	return nil
 }
 
-// getTokenDiskCache reads token from temporary cache file
-func (c *AuthCnfg) getTokenDiskCache() (*adal.ServicePrincipalToken, error) {
-	tokenCachePath := c.getTokenCachePath()
-
-	tokenCache, err := os.ReadFile(tokenCachePath)
+// getCachedToken gets in process token, the cache is read when the token is not loaded yet
+func (c *AuthCnfg) getCachedToken(cache gosip.Cache, cacheKey string) *adal.ServicePrincipalToken {
+	if token, ok := tokens.Load(cacheKey); ok {
+		return token.(*adal.ServicePrincipalToken)
+	}
+	tokenCacheE, _, found := cache.Get(cacheKey)
+	if !found {
+		return nil
+	}
+	tokenCache, err := crypter.Decode(tokenCacheE)
 	if err != nil {
-		return nil, err
+		return nil
 	}
-	tokenCacheD, _ := crypter.Decode(string(tokenCache))
-	tokenCache = []byte(tokenCacheD)
-
 	token := &adal.ServicePrincipalToken{}
-	if err := token.UnmarshalJSON(tokenCache); err != nil {
-		return nil, err
+	if err := token.UnmarshalJSON([]byte(tokenCache)); err != nil {
+		return nil
 	}
-	return token, nil
+	loaded, _ := tokens.LoadOrStore(cacheKey, token)
+	return loaded.(*adal.ServicePrincipalToken)
+}
+
+// getResource gets token resource for the site
+func (c *AuthCnfg) getResource() string {
+	u, _ := url.Parse(c.SiteURL)
+	return fmt.Sprintf("https://%s", u.Host)
 }
 
-// getTokenCachePath gets local file system file path with token cache
-func (c *AuthCnfg) getTokenCachePath() string {
-	tmpDir := filepath.Join(os.TempDir(), "gosip")
-	return filepath.Join(tmpDir, c.GetStrategy()+"_"+c.ClientID)
+// getCacheKey gets token cache key
+func (c *AuthCnfg) getCacheKey(resource string) string {
+	return resource + "@" + c.GetStrategy() + "@" + c.TenantID + "@" + c.ClientID
 }
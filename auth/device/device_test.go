@@ -4,6 +4,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/recolabs/gosip"
 	h "github.com/recolabs/gosip/test/helpers"
 	u "github.com/recolabs/gosip/test/utils"
 )
@@ -108,3 +110,43 @@ func TestCheckTransport(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestTokenCache(t *testing.T) {
+	cnfg := &AuthCnfg{
+		SiteURL:  "https://contoso.sharepoint.com/sites/test",
+		ClientID: "client",
+		TenantID: "tenant",
+	}
+	client := &gosip.SPClient{AuthCnfg: cnfg, Cache: gosip.NewMemoryCache()}
+
+	oauthConfig, err := adal.NewOAuthConfig("https://login.microsoftonline.com", cnfg.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := adal.NewServicePrincipalTokenFromManualToken(*oauthConfig, cnfg.ClientID, cnfg.getResource(), adal.Token{AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := cnfg.getCache(client)
+	if cache != client.Cache {
+		t.Fatal("client's cache should be used")
+	}
+	cacheKey := cnfg.getCacheKey(cnfg.getResource())
+	if err := cnfg.cacheToken(cache, cacheKey, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found := client.Cache.Get(cacheKey); !found {
+		t.Fatal("token is not written to the client's cache")
+	}
+
+	if err := cnfg.CleanTokenCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found := client.Cache.Get(cacheKey); found {
+		t.Error("token should be removed from the client's cache")
+	}
+	if _, found := tokens.Load(cacheKey); found {
+		t.Error("token should be removed from the process")
+	}
+}
@@ -11,17 +11,17 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/patrickmn/go-cache"
-
+	"github.com/recolabs/gosip"
 	"github.com/recolabs/gosip/templates"
 )
 
-var (
-	storage = cache.New(5*time.Minute, 10*time.Minute)
-)
-
 // GetAuth gets authentication
 func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
+	return getAuth(ctx, c, c.getCache(nil))
+}
+
+// getAuth gets authentication using the tokens cache
+func getAuth(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, int64, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -31,9 +31,9 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 		return "", 0, err
 	}
 
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.Username + "@" + c.Password
-	if authCookie, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return authCookie.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + gosip.CacheKeyHash(c.Username, c.Password)
+	if authCookie, exp, found := cache.Get(cacheKey); found {
+		return authCookie, exp.Unix(), nil
 	}
 
 	endpoint := fmt.Sprintf("%s://%s/_vti_bin/authentication.asmx", parsedURL.Scheme, parsedURL.Host)
@@ -91,7 +91,7 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 	expiry := (result.TimeoutSeconds - 60) * time.Second
 	exp := time.Now().Add(expiry).Unix()
 
-	storage.Set(cacheKey, authCookie, expiry)
+	cache.Set(cacheKey, authCookie, expiry)
 
 	return authCookie, exp, nil
 }
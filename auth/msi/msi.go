@@ -0,0 +1,232 @@
+// Package msi implements Azure Managed Identity Auth Flow,
+// tokens are requested from the Instance Metadata Service (VMs, AKS) or from
+// IDENTITY_ENDPOINT with IDENTITY_HEADER (App Service, Functions)
+// See more:
+//   - https://learn.microsoft.com/en-us/entra/identity/managed-identities-azure-resources/how-to-use-vm-token
+//   - https://learn.microsoft.com/en-us/azure/app-service/overview-managed-identity#rest-endpoint-reference
+//
+// Amongst supported platform versions are:
+//   - SharePoint Online + Azure
+package msi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
+)
+
+// Instance Metadata Service token endpoint
+const imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// tokenClient requests tokens from managed identity endpoints, they are local so SharePoint client transport is not used
+var tokenClient = &http.Client{Timeout: 30 * time.Second}
+
+// AuthCnfg - Azure Managed Identity Auth Flow
+/* Config sample:
+{
+	"siteUrl": "https://contoso.sharepoint.com/sites/test",
+	"clientId": "628cc712-c9a4-48f0-a059-af64bdbb4be5"
+}
+Empty clientId stands for the system-assigned identity. When IDENTITY_HEADER environment variable is set,
+App Service protocol is used with IDENTITY_ENDPOINT, otherwise the Instance Metadata Service is requested.
+*/
+type AuthCnfg struct {
+	SiteURL  string `json:"siteUrl"`  // SPSite or SPWeb URL, which is the context target for the API calls
+	ClientID string `json:"clientId"` // User-assigned identity client ID, empty for the system-assigned identity
+	Endpoint string `json:"endpoint"` // Token endpoint override, IDENTITY_ENDPOINT or the Instance Metadata Service by default
+
+	masterKey string
+	cache     gosip.Cache
+}
+
+// ReadConfig reads private config with auth options
+func (c *AuthCnfg) ReadConfig(privateFile string) error {
+	f, err := os.Open(privateFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	byteValue, _ := io.ReadAll(f)
+	return c.ParseConfig(byteValue)
+}
+
+// ParseConfig parses credentials from a provided JSON byte array content
+func (c *AuthCnfg) ParseConfig(byteValue []byte) error {
+	byteValue, err := secrets.ResolveConfig(byteValue, c.masterKey)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(byteValue, &c)
+}
+
+// WriteConfig writes private config with auth options
+func (c *AuthCnfg) WriteConfig(privateFile string) error {
+	config := &AuthCnfg{
+		SiteURL:  c.SiteURL,
+		ClientID: c.ClientID,
+		Endpoint: c.Endpoint,
+	}
+	file, _ := json.MarshalIndent(config, "", "  ")
+	return os.WriteFile(privateFile, file, 0644)
+}
+
+// SetMasterkey defines custom masterkey
+func (c *AuthCnfg) SetMasterkey(masterKey string) { c.masterKey = masterKey }
+
+// SetCache defines custom tokens cache, the client's cache or gosip.DefaultCache is used by default
+func (c *AuthCnfg) SetCache(cache gosip.Cache) { c.cache = cache }
+
+// getCache gets tokens cache, the custom cache goes first, then the client's cache and gosip.DefaultCache
+func (c *AuthCnfg) getCache(httpClient *gosip.SPClient) gosip.Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if httpClient != nil {
+		return httpClient.GetCache()
+	}
+	return gosip.DefaultCache
+}
+
+// GetAuth authenticates, receives access token
+func (c *AuthCnfg) GetAuth(ctx context.Context) (string, int64, error) {
+	return c.getToken(ctx, c.getCache(nil))
+}
+
+// GetSiteURL gets SharePoint siteURL
+func (c *AuthCnfg) GetSiteURL() string { return c.SiteURL }
+
+// GetStrategy gets auth strategy name
+func (c *AuthCnfg) GetStrategy() string { return "msi" }
+
+// SetAuth authenticates request
+// noinspection GoUnusedParameter
+func (c *AuthCnfg) SetAuth(req *http.Request, httpClient *gosip.SPClient) error {
+	authToken, _, err := c.getToken(req.Context(), c.getCache(httpClient))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	return nil
+}
+
+// getToken gets cached token or requests a new one from the managed identity endpoint
+func (c *AuthCnfg) getToken(ctx context.Context, cache gosip.Cache) (string, int64, error) {
+	// Get from cache
+	parsedURL, err := url.Parse(c.SiteURL)
+	if err != nil {
+		return "", 0, err
+	}
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.Endpoint + "@" + c.ClientID
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
+	}
+
+	// Get token
+	req, err := c.tokenRequest(ctx, fmt.Sprintf("https://%s", parsedURL.Host))
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := tokenClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("can't get managed identity token, status %d: %s", resp.StatusCode, data)
+	}
+
+	results := &struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresOn   json.Number `json:"expires_on"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return "", 0, err
+	}
+	token := results.AccessToken
+	if token == "" {
+		return "", 0, fmt.Errorf("no access token in the response")
+	}
+	expiration, err := tokenExpiration(token, results.ExpiresOn, results.ExpiresIn)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Save to cache, tokens which are about to expire are not cached
+	exp := expiration.Add(-60 * time.Second)
+	if ttl := time.Until(exp); ttl > 0 {
+		cache.Set(cacheKey, token, ttl)
+	}
+
+	return token, exp.Unix(), nil
+}
+
+// tokenExpiration gets token expiration from JWT exp claim falling back to expires_on and expires_in of the response
+func tokenExpiration(token string, expiresOn json.Number, expiresIn json.Number) (time.Time, error) {
+	if tt := strings.Split(token, "."); len(tt) == 3 {
+		if jsonBytes, err := base64.RawURLEncoding.DecodeString(tt[1]); err == nil {
+			j := struct {
+				Exp int64 `json:"exp"`
+			}{}
+			if err := json.Unmarshal(jsonBytes, &j); err == nil && j.Exp > 0 {
+				return time.Unix(j.Exp, 0), nil
+			}
+		}
+	}
+	if seconds, err := expiresOn.Int64(); err == nil && seconds > 0 {
+		return time.Unix(seconds, 0), nil
+	}
+	if seconds, err := expiresIn.Int64(); err == nil && seconds > 0 {
+		return time.Now().Add(time.Duration(seconds) * time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("can't get token expiration, no exp claim, expires_on and expires_in in the response")
+}
+
+// tokenRequest creates token request using App Service protocol when IDENTITY_HEADER is set, IMDS protocol otherwise
+func (c *AuthCnfg) tokenRequest(ctx context.Context, resource string) (*http.Request, error) {
+	identityHeader := os.Getenv("IDENTITY_HEADER")
+	endpoint := c.Endpoint
+	if endpoint == "" && identityHeader != "" {
+		endpoint = os.Getenv("IDENTITY_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = imdsEndpoint
+	}
+
+	params := url.Values{}
+	params.Set("resource", resource)
+	if c.ClientID != "" {
+		params.Set("client_id", c.ClientID)
+	}
+	if identityHeader != "" {
+		params.Set("api-version", "2019-08-01")
+	} else {
+		params.Set("api-version", "2018-02-01")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if identityHeader != "" {
+		req.Header.Set("X-IDENTITY-HEADER", identityHeader)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+	return req, nil
+}
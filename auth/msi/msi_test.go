@@ -0,0 +1,213 @@
+package msi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/cpass"
+)
+
+// testJWT creates unsigned JWT with the expiration
+func testJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+func TestGettingAuthToken(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := testJWT(exp)
+
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		q := r.URL.Query()
+		imds := r.Header.Get("Metadata") == "true" && q.Get("api-version") == "2018-02-01"
+		appService := r.Header.Get("X-IDENTITY-HEADER") == "secret" && q.Get("api-version") == "2019-08-01"
+		if (!imds && !appService) || q.Get("resource") != "https://contoso.sharepoint.com" {
+			w.WriteHeader(400)
+			_, _ = fmt.Fprint(w, `{"error":"invalid_request"}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"access_token":"%s","expires_in":"3599","token_type":"Bearer"}`, token)
+	}))
+	defer srv.Close()
+
+	t.Run("IMDS", func(t *testing.T) {
+		t.Setenv("IDENTITY_HEADER", "")
+		requests = nil
+		cnfg := &AuthCnfg{SiteURL: "https://contoso.sharepoint.com/sites/test", Endpoint: srv.URL}
+		cnfg.SetCache(gosip.NewMemoryCache())
+
+		accessToken, tokenExp, err := cnfg.GetAuth(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accessToken != token || tokenExp != exp.Add(-60*time.Second).Unix() {
+			t.Errorf("unexpected token: %s, %d", accessToken, tokenExp)
+		}
+		if _, _, err := cnfg.GetAuth(context.Background()); err != nil || len(requests) != 1 {
+			t.Errorf("token should be taken from cache, %d requests: %v", len(requests), err)
+		}
+		if requests[0].URL.Query().Get("client_id") != "" {
+			t.Error("system-assigned identity should not send client_id")
+		}
+	})
+
+	t.Run("ClientCache", func(t *testing.T) {
+		t.Setenv("IDENTITY_HEADER", "")
+		requests = nil
+		cnfg := &AuthCnfg{SiteURL: "https://contoso.sharepoint.com", Endpoint: srv.URL}
+		req, _ := http.NewRequest("GET", cnfg.SiteURL+"/_api/web", nil)
+		for i, client := range []*gosip.SPClient{
+			{Cache: gosip.NewMemoryCache()},
+			{Cache: gosip.NewMemoryCache()},
+		} {
+			for j := 0; j < 2; j++ {
+				if err := cnfg.SetAuth(req, client); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(requests) != i+1 {
+				t.Errorf("each client's cache should be used, got %d requests", len(requests))
+			}
+		}
+	})
+
+	t.Run("AppService", func(t *testing.T) {
+		t.Setenv("IDENTITY_ENDPOINT", srv.URL)
+		t.Setenv("IDENTITY_HEADER", "secret")
+		requests = nil
+		cnfg := &AuthCnfg{SiteURL: "https://contoso.sharepoint.com", ClientID: "user-assigned"}
+		cnfg.SetCache(gosip.NewMemoryCache())
+
+		if accessToken, _, err := cnfg.GetAuth(context.Background()); err != nil || accessToken != token {
+			t.Fatalf("unexpected token: %s, %v", accessToken, err)
+		}
+		if requests[0].URL.Query().Get("client_id") != "user-assigned" {
+			t.Error("user-assigned identity should send client_id")
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Setenv("IDENTITY_HEADER", "")
+		cnfg := &AuthCnfg{SiteURL: "https://another.sharepoint.com", Endpoint: srv.URL}
+		cnfg.SetCache(gosip.NewMemoryCache())
+		if _, _, err := cnfg.GetAuth(context.Background()); err == nil {
+			t.Error("should fail on token endpoint error")
+		}
+	})
+}
+
+func TestTokenExpiration(t *testing.T) {
+	t.Setenv("IDENTITY_HEADER", "")
+	opaqueToken := "opaque-token"
+	response := ""
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, response)
+	}))
+	defer srv.Close()
+
+	cnfg := &AuthCnfg{SiteURL: "https://contoso.sharepoint.com", Endpoint: srv.URL}
+	cache := gosip.NewMemoryCache()
+	cnfg.SetCache(cache)
+
+	t.Run("ExpiresOn", func(t *testing.T) {
+		cache.Flush()
+		expiresOn := time.Now().Add(time.Hour).Unix()
+		response = fmt.Sprintf(`{"access_token":"%s","expires_on":"%d","expires_in":"60"}`, opaqueToken, expiresOn)
+		if _, exp, err := cnfg.GetAuth(context.Background()); err != nil || exp != expiresOn-60 {
+			t.Errorf("expiration should be taken from expires_on, got %d: %v", exp, err)
+		}
+	})
+
+	t.Run("ExpiresIn", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"access_token":"%s","expires_in":3599}`, opaqueToken)
+		requests = 0
+		_, exp, err := cnfg.GetAuth(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := time.Now().Add(3539 * time.Second).Unix(); exp < expected-5 || exp > expected+5 {
+			t.Errorf("expiration should be taken from expires_in, got %d", exp)
+		}
+		if _, _, err := cnfg.GetAuth(context.Background()); err != nil || requests != 1 {
+			t.Errorf("token should be cached, got %d requests: %v", requests, err)
+		}
+	})
+
+	t.Run("NoExpiration", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"access_token":"%s"}`, opaqueToken)
+		if _, _, err := cnfg.GetAuth(context.Background()); err == nil {
+			t.Error("should fail on token without expiration")
+		}
+	})
+
+	t.Run("AboutToExpire", func(t *testing.T) {
+		cache.Flush()
+		response = fmt.Sprintf(`{"access_token":"%s","expires_in":"3599"}`, testJWT(time.Now().Add(30*time.Second)))
+		requests = 0
+		for i := 0; i < 2; i++ {
+			if _, _, err := cnfg.GetAuth(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if requests != 2 {
+			t.Errorf("token which is about to expire should not be cached, got %d requests", requests)
+		}
+	})
+}
+
+func TestAuthEdgeCases(t *testing.T) {
+	t.Run("ReadConfig/MissedConfig", func(t *testing.T) {
+		cnfg := &AuthCnfg{}
+		if err := cnfg.ReadConfig("wrong_path.json"); err == nil {
+			t.Error("wrong_path config should not pass")
+		}
+	})
+
+	t.Run("ParseConfig/Masterkey", func(t *testing.T) {
+		encoded, err := cpass.Cpass("key").Encode("client")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cnfg := &AuthCnfg{}
+		cnfg.SetMasterkey("key")
+		if err := cnfg.ParseConfig([]byte(`{"clientId":"cpass:` + encoded + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if cnfg.ClientID != "client" {
+			t.Errorf("cpass reference should be decoded with the masterkey, got %s", cnfg.ClientID)
+		}
+	})
+
+	t.Run("WriteConfig", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "private.msi.json")
+		cnfg := &AuthCnfg{SiteURL: "test", ClientID: "client"}
+		if err := cnfg.WriteConfig(filePath); err != nil {
+			t.Fatal(err)
+		}
+		read := &AuthCnfg{}
+		if err := read.ReadConfig(filePath); err != nil || read.ClientID != "client" {
+			t.Errorf("unexpected config: %+v, %v", read, err)
+		}
+	})
+
+	t.Run("GetStrategy", func(t *testing.T) {
+		cnfg := &AuthCnfg{}
+		if cnfg.GetStrategy() != "msi" {
+			t.Errorf(`wrong strategy name, expected "msi" got "%s"`, cnfg.GetStrategy())
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/secrets"
+)
+
+// ProfileEnvVar is the environment variable which selects a profile when no profile name is provided
+const ProfileEnvVar = "GOSIP_PROFILE"
+
+// profilesFile - profiles file structure
+/* Profiles file sample:
+{
+  "default": "spo-prod",
+  "profiles": {
+    "spo-prod": { "strategy": "azurecert", "siteUrl": "https://contoso.sharepoint.com/sites/prod", ... },
+    "farm": { "strategy": "ntlm", "siteUrl": "http://farm/sites/site", "password": "env:FARM_PASSWORD", ... }
+  }
+}
+*/
+type profilesFile struct {
+	Default  string                     `json:"default"`
+	Profiles map[string]json.RawMessage `json:"profiles"`
+}
+
+// configPathSetter is implemented by strategies with file paths in the config, e.g. azurecert
+type configPathSetter interface {
+	SetConfigPath(privateFile string)
+}
+
+// NewAuthFromProfile resolves AuthCnfg object based on a named profile of the profiles file,
+// each profile is a private.json-like config with "strategy" property. When name is empty,
+// the profile is taken from GOSIP_PROFILE environment variable, then from the file's "default" property.
+// Relative paths in profiles, e.g. azurecert "certPath", are resolved against the profiles file folder.
+func NewAuthFromProfile(profilesPath string, name string) (gosip.AuthCnfg, error) {
+	profiles, err := readProfiles(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+	if name == "" {
+		name = profiles.Default
+	}
+	if name == "" && len(profiles.Profiles) == 1 {
+		for n := range profiles.Profiles {
+			name = n
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no profile is selected in %s, provide a name, %s or \"default\"", profilesPath, ProfileEnvVar)
+	}
+
+	byteValue, ok := profiles.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s is not found in %s", name, profilesPath)
+	}
+
+	var cnfg struct {
+		Strategy string `json:"strategy"`
+	}
+	if err := json.Unmarshal(byteValue, &cnfg); err != nil {
+		return nil, err
+	}
+
+	auth, err := NewAuthByStrategy(cnfg.Strategy)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	// Relative paths of a profile are resolved against the profiles file folder
+	if s, ok := auth.(configPathSetter); ok {
+		s.SetConfigPath(profilesPath)
+	}
+	byteValue, err = secrets.RebaseConfig(byteValue, path.Dir(profilesPath))
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	if err := auth.ParseConfig(byteValue); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	return auth, nil
+}
+
+// ProfileNames gets sorted profile names of the profiles file
+func ProfileNames(profilesPath string) ([]string, error) {
+	profiles, err := readProfiles(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(profiles.Profiles))
+	for name := range profiles.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readProfiles reads profiles file
+func readProfiles(profilesPath string) (*profilesFile, error) {
+	byteValue, err := os.ReadFile(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+	profiles := &profilesFile{}
+	if err := json.Unmarshal(byteValue, profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
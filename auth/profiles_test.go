@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolabs/gosip/auth/azurecert"
+	"github.com/recolabs/gosip/auth/azureenv"
+	"github.com/recolabs/gosip/auth/azurewif"
+	"github.com/recolabs/gosip/auth/ntlm"
+)
+
+func TestAuthProfileResolver(t *testing.T) {
+	profilesPath := filepath.Join(t.TempDir(), "profiles.json")
+	err := os.WriteFile(profilesPath, []byte(`{
+		"default": "spo-prod",
+		"profiles": {
+			"spo-prod": {
+				"strategy": "saml",
+				"siteUrl": "https://contoso.sharepoint.com/sites/prod",
+				"username": "user@contoso.onmicrosoft.com",
+				"password": "00000000-0000-0000-0000-000000000000"
+			},
+			"spo-test": {
+				"strategy": "addin",
+				"siteUrl": "https://contoso.sharepoint.com/sites/test",
+				"clientId": "00000000-0000-0000-0000-000000000000",
+				"clientSecret": "secret"
+			},
+			"farm": {
+				"strategy": "ntlm",
+				"siteUrl": "http://farm/sites/site",
+				"domain": "contoso",
+				"username": "user",
+				"password": "env:GOSIP_TEST_FARM_PASSWORD"
+			}
+		}
+	}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOSIP_TEST_FARM_PASSWORD", "farm-secret")
+	t.Setenv(ProfileEnvVar, "")
+
+	t.Run("Default", func(t *testing.T) {
+		cnfg, err := NewAuthFromProfile(profilesPath, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cnfg.GetStrategy() != "saml" || cnfg.GetSiteURL() != "https://contoso.sharepoint.com/sites/prod" {
+			t.Errorf("unexpected default profile: %s %s", cnfg.GetStrategy(), cnfg.GetSiteURL())
+		}
+	})
+
+	t.Run("Named", func(t *testing.T) {
+		cnfg, err := NewAuthFromProfile(profilesPath, "farm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ntlmCnfg, ok := cnfg.(*ntlm.AuthCnfg)
+		if !ok {
+			t.Fatalf("strategy should be ntlm, but %s", cnfg.GetStrategy())
+		}
+		if ntlmCnfg.Username != "contoso\\user" || ntlmCnfg.Password != "farm-secret" {
+			t.Errorf("unexpected farm profile: %+v", ntlmCnfg)
+		}
+	})
+
+	t.Run("EnvOverride", func(t *testing.T) {
+		t.Setenv(ProfileEnvVar, "spo-test")
+		cnfg, err := NewAuthFromProfile(profilesPath, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cnfg.GetStrategy() != "addin" {
+			t.Errorf("strategy should be addin, but %s", cnfg.GetStrategy())
+		}
+		if cnfg, _ := NewAuthFromProfile(profilesPath, "farm"); cnfg == nil || cnfg.GetStrategy() != "ntlm" {
+			t.Error("explicit profile name should take precedence over the environment")
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		if _, err := NewAuthFromProfile(profilesPath, "unknown"); err == nil {
+			t.Error("should return an error")
+		}
+	})
+
+	t.Run("ProfileNames", func(t *testing.T) {
+		names, err := ProfileNames(profilesPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(names) != "[farm spo-prod spo-test]" {
+			t.Errorf("unexpected profile names: %v", names)
+		}
+	})
+}
+
+func TestAuthProfileRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	profilesPath := filepath.Join(dir, "profiles.json")
+	err := os.WriteFile(profilesPath, []byte(`{
+		"profiles": {
+			"cert": {
+				"strategy": "azurecert",
+				"siteUrl": "https://contoso.sharepoint.com",
+				"certPath": "certs/app.pfx"
+			},
+			"env": {
+				"strategy": "azureenv",
+				"siteUrl": "https://contoso.sharepoint.com",
+				"env": { "AZURE_CERTIFICATE_PATH": "certs/app.pfx" }
+			},
+			"wif": {
+				"strategy": "azurewif",
+				"siteUrl": "https://contoso.sharepoint.com",
+				"tokenFile": "tokens/azure-identity-token"
+			},
+			"ntlm": {
+				"strategy": "ntlm",
+				"siteUrl": "http://farm/sites/site",
+				"username": "user",
+				"password": "file:secrets/password"
+			}
+		}
+	}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(dir, "certs", "app.pfx")
+	if err := os.MkdirAll(filepath.Join(dir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secrets", "password"), []byte("p@ssw0rd\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cnfg, err := NewAuthFromProfile(profilesPath, "cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cnfg.(*azurecert.AuthCnfg).CertPath; p != certPath {
+		t.Errorf("cert path should be relative to the profiles file, got %s", p)
+	}
+
+	cnfg, err = NewAuthFromProfile(profilesPath, "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cnfg.(*azureenv.AuthCnfg).Env["AZURE_CERTIFICATE_PATH"]; p != certPath {
+		t.Errorf("cert path should be relative to the profiles file, got %s", p)
+	}
+
+	cnfg, err = NewAuthFromProfile(profilesPath, "wif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cnfg.(*azurewif.AuthCnfg).TokenFile; p != filepath.Join(dir, "tokens", "azure-identity-token") {
+		t.Errorf("token file should be relative to the profiles file, got %s", p)
+	}
+
+	cnfg, err = NewAuthFromProfile(profilesPath, "ntlm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cnfg.(*ntlm.AuthCnfg).Password; p != "p@ssw0rd" {
+		t.Errorf("file reference should be relative to the profiles file, got %s", p)
+	}
+}
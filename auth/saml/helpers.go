@@ -13,13 +13,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
-
+	"github.com/recolabs/gosip"
 	"github.com/recolabs/gosip/templates"
 )
 
 var (
-	storage        = cache.New(5*time.Minute, 10*time.Minute)
 	loginEndpoints = map[spoEnv]string{
 		spoProd:   "login.microsoftonline.com",
 		spoGerman: "login.microsoftonline.de",
@@ -31,6 +29,11 @@ var (
 
 // GetAuth gets authentication
 func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
+	return getAuth(ctx, c, c.getCache(nil))
+}
+
+// getAuth gets authentication using the tokens cache
+func getAuth(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, int64, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -40,9 +43,9 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 		return "", 0, err
 	}
 
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.Username + "@" + c.Password
-	if authToken, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return authToken.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + gosip.CacheKeyHash(c.Username, c.Password)
+	if authToken, exp, found := cache.Get(cacheKey); found {
+		return authToken, exp.Unix(), nil
 	}
 
 	authCookie, notAfter, err := getSecurityToken(ctx, c)
@@ -54,7 +57,7 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 	expiry := time.Until(notAfterTime) - 60*time.Second
 	exp := time.Now().Add(expiry).Unix()
 
-	storage.Set(cacheKey, authCookie, expiry)
+	cache.Set(cacheKey, authCookie, expiry)
 
 	return authCookie, exp, nil
 }
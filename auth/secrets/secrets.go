@@ -0,0 +1,124 @@
+// Package secrets resolves secret references in auth configs,
+// references allow keeping secrets out of private.json, e.g. in mounted files or environment variables
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/recolabs/gosip/cpass"
+)
+
+// Secret reference prefixes
+const (
+	EnvPrefix     = "env:"     // `env:NAME` - environment variable value
+	FilePrefix    = "file:"    // `file:/run/secrets/name` - file content without trailing line breaks
+	CpassPrefix   = "cpass:"   // `cpass:...` - cpass encoded value, decoded with the master key
+	LiteralPrefix = "literal:" // `literal:env:value` - escapes a value which starts with a reference prefix, the rest is used as is
+)
+
+// Resolve resolves secret reference value, values without a reference prefix are returned as is
+func Resolve(value string, masterKey string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, LiteralPrefix):
+		return strings.TrimPrefix(value, LiteralPrefix), nil
+	case strings.HasPrefix(value, EnvPrefix):
+		name := strings.TrimPrefix(value, EnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("can't resolve secret reference, environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(value, FilePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(value, FilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("can't resolve secret reference: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, CpassPrefix):
+		v, err := cpass.Cpass(masterKey).Decode(strings.TrimPrefix(value, CpassPrefix))
+		if err != nil {
+			return "", fmt.Errorf("can't resolve secret reference, cpass decode failed: %w", err)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// ResolveConfig resolves secret references in JSON config string values including nested objects,
+// the config is returned unchanged when it contains no references or is not a JSON object
+func ResolveConfig(config []byte, masterKey string) ([]byte, error) {
+	var props map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	if err := decoder.Decode(&props); err != nil {
+		return config, nil
+	}
+	resolved, err := resolveProps(props, masterKey)
+	if err != nil || !resolved {
+		return config, err
+	}
+	return json.Marshal(props)
+}
+
+// resolveProps resolves references in place, reports whether any reference is resolved
+func resolveProps(props map[string]interface{}, masterKey string) (bool, error) {
+	resolved := false
+	for key, val := range props {
+		switch v := val.(type) {
+		case string:
+			r, err := Resolve(v, masterKey)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+			if r != v {
+				props[key] = r
+				resolved = true
+			}
+		case map[string]interface{}:
+			r, err := resolveProps(v, masterKey)
+			if err != nil {
+				return false, fmt.Errorf("%s.%w", key, err)
+			}
+			resolved = resolved || r
+		}
+	}
+	return resolved, nil
+}
+
+// RebaseConfig resolves relative `file:` references in JSON config string values against the base folder,
+// the config is returned unchanged when it contains no relative file references or is not a JSON object
+func RebaseConfig(config []byte, baseDir string) ([]byte, error) {
+	var props map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	if err := decoder.Decode(&props); err != nil {
+		return config, nil
+	}
+	if !rebaseProps(props, baseDir) {
+		return config, nil
+	}
+	return json.Marshal(props)
+}
+
+// rebaseProps rebases relative file references in place, reports whether any reference is rebased
+func rebaseProps(props map[string]interface{}, baseDir string) bool {
+	rebased := false
+	for key, val := range props {
+		switch v := val.(type) {
+		case string:
+			filePath := strings.TrimPrefix(v, FilePrefix)
+			if filePath != v && !path.IsAbs(filePath) {
+				props[key] = FilePrefix + path.Join(baseDir, filePath)
+				rebased = true
+			}
+		case map[string]interface{}:
+			rebased = rebaseProps(v, baseDir) || rebased
+		}
+	}
+	return rebased
+}
@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolabs/gosip/cpass"
+)
+
+func TestResolve(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOSIP_TEST_SECRET", "from-env")
+	encoded, err := cpass.Cpass("key").Encode("from-cpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"plain":                 "plain",
+		"env:GOSIP_TEST_SECRET": "from-env",
+		"file:" + secretFile:    "from-file",
+		"cpass:" + encoded:      "from-cpass",
+		// escaped values which start with a reference prefix
+		"literal:env:GOSIP_TEST_SECRET": "env:GOSIP_TEST_SECRET",
+		"literal:literal:value":         "literal:value",
+	}
+	for ref, expected := range cases {
+		value, err := Resolve(ref, "key")
+		if err != nil {
+			t.Errorf("%s: %s", ref, err)
+		}
+		if value != expected {
+			t.Errorf("%s: expected %s, got %s", ref, expected, value)
+		}
+	}
+
+	for _, ref := range []string{"env:GOSIP_TEST_MISSING", "file:" + secretFile + ".missing", "cpass:invalid"} {
+		if _, err := Resolve(ref, "key"); err == nil {
+			t.Errorf("%s: should fail", ref)
+		}
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	t.Setenv("GOSIP_TEST_SECRET", "from-env")
+
+	config := []byte(`{"siteUrl":"https://contoso.sharepoint.com","password":"env:GOSIP_TEST_SECRET","env":{"AZURE_CLIENT_SECRET":"env:GOSIP_TEST_SECRET"},"port":8080}`)
+	resolved, err := ResolveConfig(config, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"env":{"AZURE_CLIENT_SECRET":"from-env"},"password":"from-env","port":8080,"siteUrl":"https://contoso.sharepoint.com"}`
+	if string(resolved) != expected {
+		t.Errorf("unexpected config: %s", resolved)
+	}
+
+	plain := []byte(`{ "siteUrl": "https://contoso.sharepoint.com" }`)
+	if resolved, _ := ResolveConfig(plain, ""); string(resolved) != string(plain) {
+		t.Errorf("config without references should not be changed: %s", resolved)
+	}
+
+	escaped := []byte(`{"password":"literal:file:not-a-reference"}`)
+	if resolved, _ := ResolveConfig(escaped, ""); string(resolved) != `{"password":"file:not-a-reference"}` {
+		t.Errorf("escaped value should be used as is: %s", resolved)
+	}
+
+	if _, err := ResolveConfig([]byte(`{"env":{"KEY":"env:GOSIP_TEST_MISSING"}}`), ""); err == nil {
+		t.Error("should fail on missing variable")
+	}
+}
+
+func TestRebaseConfig(t *testing.T) {
+	config := []byte(`{"password":"file:secrets/password","env":{"KEY":"file:../key"},"cert":"file:/run/secrets/cert","port":8080}`)
+	rebased, err := RebaseConfig(config, "/etc/gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"cert":"file:/run/secrets/cert","env":{"KEY":"file:/etc/key"},"password":"file:/etc/gosip/secrets/password","port":8080}`
+	if string(rebased) != expected {
+		t.Errorf("unexpected config: %s", rebased)
+	}
+
+	plain := []byte(`{ "password": "literal:file:secrets/password" }`)
+	if rebased, _ := RebaseConfig(plain, "/etc/gosip"); string(rebased) != string(plain) {
+		t.Errorf("config without relative file references should not be changed: %s", rebased)
+	}
+}
@@ -9,15 +9,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
-)
-
-var (
-	storage = cache.New(5*time.Minute, 10*time.Minute)
+	"github.com/recolabs/gosip"
 )
 
 // GetAuth gets authentication
 func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
+	return getAuth(ctx, c, c.getCache(nil))
+}
+
+// getAuth gets authentication using the tokens cache
+func getAuth(ctx context.Context, c *AuthCnfg, cache gosip.Cache) (string, int64, error) {
 	if c.client == nil {
 		c.client = &http.Client{}
 	}
@@ -27,9 +28,9 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 		return "", 0, err
 	}
 
-	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + c.Username + "@" + c.Password
-	if accessToken, exp, found := storage.GetWithExpiration(cacheKey); found {
-		return accessToken.(string), exp.Unix(), nil
+	cacheKey := parsedURL.Host + "@" + c.GetStrategy() + "@" + gosip.CacheKeyHash(c.Username, c.Password)
+	if accessToken, exp, found := cache.Get(cacheKey); found {
+		return accessToken, exp.Unix(), nil
 	}
 
 	redirect, err := detectCookieAuthURL(ctx, c, c.SiteURL)
@@ -85,7 +86,7 @@ func GetAuth(ctx context.Context, c *AuthCnfg) (string, int64, error) {
 	// TODO: ttl detection
 	expiry := time.Hour
 	exp := time.Now().Add(expiry).Unix()
-	storage.Set(cacheKey, authCookie, expiry)
+	cache.Set(cacheKey, authCookie, expiry)
 
 	return authCookie, exp, nil
 }
@@ -0,0 +1,79 @@
+package gosip
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// streamingCtxKey context key for non-retryable streaming request bodies
+type streamingCtxKey struct{}
+
+// WithStreaming returns a copy of the context which marks request bodies as non-retryable streams.
+// The bodies which can't be rewound (no GetBody and not seekable) are sent as is with no in-memory copy,
+// such requests are not retried. Without the mode these bodies are buffered to be replayed on retries.
+func WithStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingCtxKey{}, true)
+}
+
+// isStreaming checks if the request is sent in non-retryable streaming mode
+func isStreaming(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamingCtxKey{}).(bool)
+	return streaming
+}
+
+// prepareBody makes request body replayable for retries with no full in-memory buffering when possible:
+// http.Request.GetBody is used as is, seekable bodies are rewound to the initial position,
+// other bodies are buffered as they are read unless the request is in streaming mode
+func prepareBody(req *http.Request) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return
+	}
+
+	if seeker, ok := req.Body.(io.ReadSeeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			// Transport closes request body after sending, the body must stay open between attempts
+			req.Body = io.NopCloser(seeker)
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(seeker), nil
+			}
+			return
+		}
+	}
+
+	if isStreaming(req.Context()) {
+		return
+	}
+
+	// Consumed part of the body is replayed from the buffer, the rest is read from the original body
+	// as an attempt might fail before the body is read to the end
+	var buf bytes.Buffer
+	body := req.Body
+	req.Body = io.NopCloser(io.TeeReader(body, &buf))
+	req.GetBody = func() (io.ReadCloser, error) {
+		consumed := bytes.NewReader(buf.Bytes())
+		return io.NopCloser(io.MultiReader(consumed, io.TeeReader(body, &buf))), nil
+	}
+}
+
+// canRewind checks if request body can be sent again
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody resets request body before a retry
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
@@ -0,0 +1,133 @@
+package gosip
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestBody(t *testing.T) {
+	siteURL := "http://localhost:8989"
+	var bodies []string
+	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/_api/ContextInfo" {
+			_, _ = w.Write([]byte(`{"d":{"GetContextWebInformation":{"FormDigestValue":"FAKE","FormDigestTimeoutSeconds":120}}}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		// fail the first attempt
+		if len(bodies) == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	client := &SPClient{
+		AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
+		Cache:         NewMemoryCache(),
+		RetryStrategy: &BackoffRetryStrategy{Policies: map[int]int{503: 1}, BaseDelay: time.Millisecond},
+	}
+
+	send := func(ctx context.Context, body io.Reader) error {
+		bodies = nil
+		req, err := http.NewRequestWithContext(ctx, "POST", siteURL+"/_api/web", body)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Execute(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("GetBody", func(t *testing.T) {
+		if err := send(context.Background(), bytes.NewReader([]byte("payload"))); err != nil {
+			t.Fatal(err)
+		}
+		if len(bodies) != 2 || bodies[1] != "payload" {
+			t.Errorf("unexpected retried bodies: %v", bodies)
+		}
+	})
+
+	t.Run("Seekable", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "payload.txt")
+		if err := os.WriteFile(filePath, []byte("prefix:payload"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = file.Close() }()
+		_, _ = file.Seek(int64(len("prefix:")), io.SeekStart)
+
+		if err := send(context.Background(), file); err != nil {
+			t.Fatal(err)
+		}
+		if len(bodies) != 2 || bodies[0] != "payload" || bodies[1] != "payload" {
+			t.Errorf("unexpected retried bodies: %v", bodies)
+		}
+	})
+
+	t.Run("Buffered", func(t *testing.T) {
+		if err := send(context.Background(), io.MultiReader(strings.NewReader("pay"), strings.NewReader("load"))); err != nil {
+			t.Fatal(err)
+		}
+		if len(bodies) != 2 || bodies[1] != "payload" {
+			t.Errorf("unexpected retried bodies: %v", bodies)
+		}
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		ctx := WithStreaming(context.Background())
+		if err := send(ctx, io.MultiReader(strings.NewReader("payload"))); err == nil {
+			t.Error("streaming request should not be retried")
+		}
+		if len(bodies) != 1 {
+			t.Errorf("expected a single attempt, got %d", len(bodies))
+		}
+
+		if err := send(ctx, strings.NewReader("payload")); err != nil {
+			t.Errorf("rewindable bodies should be retried in streaming mode: %s", err)
+		}
+	})
+}
+
+func TestPrepareBody(t *testing.T) {
+	body := io.MultiReader(strings.NewReader("payload"))
+	req, _ := http.NewRequest("POST", "http://localhost", body)
+	prepareBody(req)
+
+	// an attempt reads only a part of the body
+	part := make([]byte, 3)
+	_, _ = io.ReadFull(req.Body, part)
+
+	if err := rewindBody(req); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(req.Body)
+	if string(data) != "payload" {
+		t.Errorf("partially read body is not replayed: %s", data)
+	}
+
+	if err := rewindBody(req); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = io.ReadAll(req.Body)
+	if string(data) != "payload" {
+		t.Errorf("body is not replayed for the second time: %s", data)
+	}
+}
@@ -0,0 +1,165 @@
+package gosip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Cache is a storage for digests, auth tokens and other short-living values,
+// custom implementations (e.g. Redis backed) allow sharing the values between processes
+type Cache interface {
+	// Get gets a value by key, expiration is zero time for the values with no expiration
+	Get(key string) (value string, expiration time.Time, found bool)
+	// Set stores a value, ttl <= 0 stores the value with no expiration
+	Set(key string, value string, ttl time.Duration)
+	// Delete removes a value by key
+	Delete(key string)
+	// Flush removes all values
+	Flush()
+}
+
+// DefaultCache is the in-memory cache used by the client and auth strategies when no custom cache is provided
+var DefaultCache Cache = NewMemoryCache()
+
+// CacheKeyHash gets hex SHA-256 hash of the key parts, auth strategies use it as the credentials part
+// of the cache keys so no secrets are stored or logged as key names by custom cache implementations
+func CacheKeyHash(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// MemoryCache is an in-process Cache implementation
+// Always use NewMemoryCache constructor instead of &MemoryCache{}
+type MemoryCache struct {
+	storage *cache.Cache
+}
+
+// NewMemoryCache creates in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{storage: cache.New(5*time.Minute, 10*time.Minute)}
+}
+
+// Get gets a value by key
+func (c *MemoryCache) Get(key string) (string, time.Time, bool) {
+	value, exp, found := c.storage.GetWithExpiration(key)
+	if !found {
+		return "", time.Time{}, false
+	}
+	return value.(string), exp, true
+}
+
+// Set stores a value
+func (c *MemoryCache) Set(key string, value string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = cache.NoExpiration
+	}
+	c.storage.Set(key, value, ttl)
+}
+
+// Delete removes a value by key
+func (c *MemoryCache) Delete(key string) { c.storage.Delete(key) }
+
+// Flush removes all values
+func (c *MemoryCache) Flush() { c.storage.Flush() }
+
+// FileCache is a file system backed Cache implementation, each value is stored in a separate file
+// in the cache folder, so the values survive restarts and can be shared between processes on the same host.
+// Values are stored as is, keep the folder private as it contains auth tokens.
+// Write errors are ignored as cache is a best effort storage.
+// Always use NewFileCache constructor instead of &FileCache{}
+type FileCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type fileCacheEntry struct {
+	Key     string    `json:"key"`
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewFileCache creates file system cache in the folder, the folder is created when missing
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// Get gets a value by key, expired values are removed
+func (c *FileCache) Get(key string) (string, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := os.ReadFile(c.filePath(key))
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	entry := &fileCacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil || entry.Key != key {
+		return "", time.Time{}, false
+	}
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		_ = os.Remove(c.filePath(key))
+		return "", time.Time{}, false
+	}
+	return entry.Value, entry.Expires, true
+}
+
+// Set stores a value
+func (c *FileCache) Set(key string, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &fileCacheEntry{Key: key, Value: value}
+	if ttl > 0 {
+		entry.Expires = time.Now().Add(ttl)
+	}
+	data, _ := json.Marshal(entry)
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	// Write to a temporary file and rename it so concurrent readers never see partial content
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.filePath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes a value by key
+func (c *FileCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = os.Remove(c.filePath(key))
+}
+
+// Flush removes all values
+func (c *FileCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	files, _ := os.ReadDir(c.dir)
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			_ = os.Remove(filepath.Join(c.dir, f.Name()))
+		}
+	}
+}
+
+// filePath gets cache file path, keys contain credentials so only their hashes are used as file names
+func (c *FileCache) filePath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,96 @@
+package gosip
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	caches := map[string]Cache{
+		"Memory": NewMemoryCache(),
+		"File":   NewFileCache(t.TempDir()),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			cache.Set("key", "value", time.Minute)
+			value, exp, found := cache.Get("key")
+			if !found || value != "value" {
+				t.Fatalf("value is not cached: %s", value)
+			}
+			if time.Until(exp) <= 0 || time.Until(exp) > time.Minute {
+				t.Errorf("unexpected expiration: %s", exp)
+			}
+
+			cache.Set("permanent", "value", 0)
+			if _, exp, found := cache.Get("permanent"); !found || !exp.IsZero() {
+				t.Errorf("value should have no expiration, got %s", exp)
+			}
+
+			cache.Set("expired", "value", time.Nanosecond)
+			time.Sleep(time.Millisecond)
+			if _, _, found := cache.Get("expired"); found {
+				t.Error("expired value should not be found")
+			}
+
+			cache.Delete("key")
+			if _, _, found := cache.Get("key"); found {
+				t.Error("deleted value should not be found")
+			}
+
+			cache.Flush()
+			if _, _, found := cache.Get("permanent"); found {
+				t.Error("flushed value should not be found")
+			}
+		})
+	}
+
+	t.Run("FileShared", func(t *testing.T) {
+		dir := t.TempDir()
+		NewFileCache(dir).Set("key", "value", time.Minute)
+		if value, _, found := NewFileCache(dir).Get("key"); !found || value != "value" {
+			t.Error("value should be shared between file cache instances")
+		}
+	})
+}
+
+func TestClientCache(t *testing.T) {
+	siteURL := "http://localhost:8990"
+	digestRequests := 0
+	closer, err := startFakeServer(":8990", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/_api/ContextInfo" {
+			digestRequests++
+			_, _ = fmt.Fprintf(w, `{"d":{"GetContextWebInformation":{"FormDigestValue":"FAKE","FormDigestTimeoutSeconds":120,"LibraryVersion":"FAKE"}}}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{ "result": "ok" }`)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	cache := NewFileCache(t.TempDir())
+	for i := 0; i < 2; i++ {
+		// New client instances emulate process restarts
+		client := &SPClient{AuthCnfg: &AnonymousCnfg{SiteURL: siteURL}, Cache: cache}
+		if _, err := GetDigest(context.Background(), client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if digestRequests != 1 {
+		t.Errorf("digest should be requested once, got %d requests", digestRequests)
+	}
+
+	cache.Flush()
+	client := &SPClient{AuthCnfg: &AnonymousCnfg{SiteURL: siteURL}, Cache: cache}
+	if _, err := GetDigest(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if digestRequests != 2 {
+		t.Errorf("digest should be requested after cache flush, got %d requests", digestRequests)
+	}
+}
@@ -150,6 +150,12 @@ func modificatorsGen(c *apiGenCnfg) string {
 					` + ent + `.modifiers.AddFilter(oDataFilter)
 					return ` + ent + `
 				}
+
+				// FilterExpr adds $filter OData modifier from typed expression, see api/odata package
+				func (` + ent + ` *` + Ent + `) FilterExpr(expr ODataExpr) *` + Ent + ` {
+					` + ent + `.modifiers.AddFilterExpr(expr)
+					return ` + ent + `
+				}
 			`
 		case "Top":
 			code += `
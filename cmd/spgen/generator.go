@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/recolabs/gosip/api"
+)
+
+// schema is a source of a generated struct
+type schema struct {
+	Package    string          // Go package name
+	Name       string          // struct name
+	Source     string          // list or content type title for doc comments
+	EntityType string          // list item entity type full name, optional
+	Fields     []api.FieldInfo // fields in generation order
+}
+
+// goField is a generated struct field
+type goField struct {
+	name    string
+	typ     string
+	tag     string
+	comment string
+}
+
+// fieldTypes maps field TypeAsString to Go type and sp tag options,
+// struct values are omitted when empty so unset dates, links and terms don't overwrite item data
+var fieldTypes = map[string]struct{ typ, opts string }{
+	"Text":                   {"string", ""},
+	"Note":                   {"string", ""},
+	"Choice":                 {"string", ""},
+	"MultiChoice":            {"[]string", ""},
+	"Number":                 {"float64", ""},
+	"Currency":               {"float64", ""},
+	"Integer":                {"int", ""},
+	"Counter":                {"int", ",readonly"},
+	"Boolean":                {"bool", ""},
+	"AllDayEvent":            {"bool", ""},
+	"Attachments":            {"bool", ",readonly"},
+	"DateTime":               {"time.Time", ",omitempty"},
+	"Lookup":                 {"api.LookupValue", ",lookup"},
+	"LookupMulti":            {"[]api.LookupValue", ",lookup"},
+	"User":                   {"api.UserValue", ",user"},
+	"UserMulti":              {"[]api.UserValue", ",user"},
+	"URL":                    {"api.URLValue", ",omitempty"},
+	"TaxonomyFieldType":      {"api.TaxonomyValue", ",omitempty"},
+	"TaxonomyFieldTypeMulti": {"[]api.TaxonomyValue", ",readonly"}, // multi-value taxonomy is written via hidden note field
+	"Calculated":             {"string", ",readonly"},
+	"ContentTypeId":          {"string", ",readonly"},
+	"Guid":                   {"string", ",readonly"},
+}
+
+var hexEscape = regexp.MustCompile(`_x([0-9a-fA-F]{4})_`)
+
+// generate renders Go source code for the schema
+func generate(s *schema) ([]byte, error) {
+	var fields []goField
+	names := map[string]int{}
+	usesTime, usesAPI := false, false
+
+	for _, f := range s.Fields {
+		ft, ok := fieldTypes[f.TypeAsString]
+		if !ok {
+			continue // unsupported and computed fields can't be selected and written
+		}
+		opts := ft.opts
+		if f.ReadOnlyField && !strings.Contains(opts, "readonly") {
+			opts += ",readonly"
+		}
+		name := goFieldName(f.InternalName)
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, names[name])
+		}
+		fields = append(fields, goField{
+			name:    name,
+			typ:     ft.typ,
+			tag:     fmt.Sprintf("`sp:\"%s%s\"`", f.InternalName, opts),
+			comment: strings.TrimSpace(f.Title + " (" + f.TypeAsString + ")"),
+		})
+		usesTime = usesTime || strings.Contains(ft.typ, "time.")
+		usesAPI = usesAPI || strings.Contains(ft.typ, "api.")
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by spgen from %s schema; DO NOT EDIT.\n\n", s.Source)
+	fmt.Fprintf(b, "package %s\n\n", s.Package)
+	if usesTime || usesAPI {
+		b.WriteString("import (\n")
+		if usesTime {
+			b.WriteString("\t\"time\"\n\n")
+		}
+		if usesAPI {
+			b.WriteString("\t\"github.com/recolabs/gosip/api\"\n")
+		}
+		b.WriteString(")\n\n")
+	}
+	if s.EntityType != "" {
+		fmt.Fprintf(b, "// %sEntityType is %s item entity type full name\n", s.Name, s.Source)
+		fmt.Fprintf(b, "const %sEntityType = %q\n\n", s.Name, s.EntityType)
+	}
+	fmt.Fprintf(b, "// %s is %s item, use it with api.TypedList\n", s.Name, s.Source)
+	fmt.Fprintf(b, "type %s struct {\n", s.Name)
+	for _, f := range fields {
+		fmt.Fprintf(b, "\t%s %s %s // %s\n", f.name, f.typ, f.tag, f.comment)
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// goFieldName converts field internal name to exported Go identifier
+func goFieldName(internalName string) string {
+	// Decode escaped characters, e.g. `My_x0020_Field`
+	name := hexEscape.ReplaceAllStringFunc(internalName, func(s string) string {
+		var r rune
+		_, _ = fmt.Sscanf(s[2:6], "%x", &r)
+		return string(r)
+	})
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	res := b.String()
+	if res == "" || unicode.IsDigit([]rune(res)[0]) {
+		res = "F" + res
+	}
+	if strings.HasSuffix(res, "Id") && len(res) > 2 {
+		res = strings.TrimSuffix(res, "Id") + "ID"
+	}
+	if res == "Id" {
+		res = "ID"
+	}
+	return res
+}
+
+// goTypeName converts list or content type title to exported Go type name
+func goTypeName(title string) string {
+	return goFieldName(title)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/recolabs/gosip/api"
+)
+
+func TestGenerate(t *testing.T) {
+	code, err := generate(&schema{
+		Package:    "tasks",
+		Name:       "Task",
+		Source:     `"Tasks" list`,
+		EntityType: "SP.Data.TasksListItem",
+		Fields: []api.FieldInfo{
+			{InternalName: "ID", Title: "ID", TypeAsString: "Counter"},
+			{InternalName: "Title", Title: "Title", TypeAsString: "Text"},
+			{InternalName: "Due_x0020_Date", Title: "Due Date", TypeAsString: "DateTime"},
+			{InternalName: "Tags", Title: "Tags", TypeAsString: "MultiChoice"},
+			{InternalName: "Parent", Title: "Parent", TypeAsString: "Lookup"},
+			{InternalName: "AssignedTo", Title: "Assigned To", TypeAsString: "UserMulti"},
+			{InternalName: "Link", Title: "Link", TypeAsString: "URL"},
+			{InternalName: "Region", Title: "Region", TypeAsString: "TaxonomyFieldType"},
+			{InternalName: "Total", Title: "Total", TypeAsString: "Number", ReadOnlyField: true},
+			{InternalName: "LinkTitle", Title: "Title", TypeAsString: "Computed"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := strings.Join(strings.Fields(string(code)), " ")
+	for _, expected := range []string{
+		"package tasks",
+		`"time"`,
+		`"github.com/recolabs/gosip/api"`,
+		`const TaskEntityType = "SP.Data.TasksListItem"`,
+		"ID int `sp:\"ID,readonly\"`",
+		"DueDate time.Time `sp:\"Due_x0020_Date,omitempty\"`",
+		"Tags []string `sp:\"Tags\"`",
+		"Parent api.LookupValue `sp:\"Parent,lookup\"`",
+		"AssignedTo []api.UserValue `sp:\"AssignedTo,user\"`",
+		"Link api.URLValue `sp:\"Link,omitempty\"`",
+		"Region api.TaxonomyValue `sp:\"Region,omitempty\"`",
+		"Total float64 `sp:\"Total,readonly\"`",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("generated code should contain %s:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(src, "LinkTitle") {
+		t.Error("computed fields should be skipped")
+	}
+}
+
+func TestGenerateOmitsEmptyStructs(t *testing.T) {
+	// Zero dates, links and terms must not be sent on Add/Update
+	for typeName, ft := range fieldTypes {
+		structValue := strings.Contains(ft.typ, ".") && !strings.HasPrefix(ft.typ, "[]")
+		writable := !strings.Contains(ft.opts, "readonly") && !strings.Contains(ft.opts, "lookup") && !strings.Contains(ft.opts, "user")
+		if structValue && writable && !strings.Contains(ft.opts, "omitempty") {
+			t.Errorf("%s field should be generated with omitempty option", typeName)
+		}
+	}
+
+	code, err := generate(&schema{
+		Package: "events",
+		Name:    "Event",
+		Source:  `"Events" list`,
+		Fields: []api.FieldInfo{
+			{InternalName: "EventDate", Title: "Start Time", TypeAsString: "DateTime"},
+			{InternalName: "Created", Title: "Created", TypeAsString: "DateTime", ReadOnlyField: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := strings.Join(strings.Fields(string(code)), " ")
+	for _, expected := range []string{
+		"EventDate time.Time `sp:\"EventDate,omitempty\"`",
+		"Created time.Time `sp:\"Created,omitempty,readonly\"`",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("generated code should contain %s:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGoFieldName(t *testing.T) {
+	cases := map[string]string{
+		"Title":          "Title",
+		"Due_x0020_Date": "DueDate",
+		"ParentId":       "ParentID",
+		"_x0031_st":      "F1st",
+		"OData__Comment": "ODataComment",
+		"Id":             "ID",
+	}
+	for name, expected := range cases {
+		if res := goFieldName(name); res != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, res)
+		}
+	}
+}
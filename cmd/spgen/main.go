@@ -0,0 +1,220 @@
+// spgen generates Go structs for api.TypedList from SharePoint list and content type schemas
+//
+// Usage:
+//
+//	spgen -config ./config/private.json -list "Tasks" -pkg tasks -out ./tasks/task_gen.go
+//	spgen -config ./config/private.json -ct 0x0108 -name Task
+//	spgen -config ./config/private.json -list "Tasks" -ct "Task"
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/api"
+	"github.com/recolabs/gosip/auth"
+)
+
+// builtInFields are list fields from base types which are included into list schemas
+var builtInFields = map[string]bool{
+	"ID":       true,
+	"Title":    true,
+	"Created":  true,
+	"Modified": true,
+	"Author":   true,
+	"Editor":   true,
+}
+
+func main() {
+	var (
+		configPath  string
+		strategy    string
+		listTitle   string
+		contentType string
+		structName  string
+		pkgName     string
+		outPath     string
+		hidden      bool
+	)
+
+	flag.StringVar(&configPath, "config", "./config/private.json", "Auth config path")
+	flag.StringVar(&strategy, "strategy", "", "Auth strategy, when not provided it is read from the config's `strategy` property")
+	flag.StringVar(&listTitle, "list", "", "List title")
+	flag.StringVar(&contentType, "ct", "", "Content type ID (0x...) or name, list content type when used with -list")
+	flag.StringVar(&structName, "name", "", "Struct name, defaults to list or content type title")
+	flag.StringVar(&pkgName, "pkg", "main", "Go package name")
+	flag.StringVar(&outPath, "out", "", "Output file, stdout when not provided")
+	flag.BoolVar(&hidden, "hidden", false, "Include hidden fields")
+	flag.Parse()
+
+	if listTitle == "" && contentType == "" {
+		log.Fatal("either -list or -ct should be provided")
+	}
+
+	authCnfg, err := getAuth(configPath, strategy)
+	if err != nil {
+		log.Fatalf("unable to get config: %v", err)
+	}
+
+	sp := api.NewSP(&gosip.SPClient{AuthCnfg: authCnfg})
+	ctx := context.Background()
+
+	var s *schema
+	if contentType != "" {
+		s, err = contentTypeSchema(ctx, sp, listTitle, contentType, hidden)
+	} else {
+		s, err = listSchema(ctx, sp, listTitle, hidden)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s.Package = pkgName
+	if structName != "" {
+		s.Name = structName
+	}
+
+	code, err := generate(s)
+	if err != nil {
+		log.Fatalf("unable to format generated code: %v", err)
+	}
+
+	if outPath == "" {
+		fmt.Print(string(code))
+		return
+	}
+	if err := os.WriteFile(outPath, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// getAuth resolves auth config from private file
+func getAuth(configPath string, strategy string) (gosip.AuthCnfg, error) {
+	if strategy == "" {
+		return auth.NewAuthFromFile(configPath)
+	}
+	authCnfg, err := auth.NewAuthByStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := authCnfg.ReadConfig(configPath); err != nil {
+		return nil, err
+	}
+	return authCnfg, nil
+}
+
+// listSchema reads list fields schema
+func listSchema(ctx context.Context, sp *api.SP, listTitle string, hidden bool) (*schema, error) {
+	list := sp.Web().Lists().GetByTitle(listTitle)
+
+	listResp, err := list.Select("Title,ListItemEntityTypeFullName").Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get list: %w", err)
+	}
+	listInfo := listResp.Data()
+
+	fieldsResp, err := list.Fields().Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get list fields: %w", err)
+	}
+
+	var fields []api.FieldInfo
+	for _, f := range fieldsResp.Data() {
+		field := f.Data()
+		if !builtInFields[field.InternalName] {
+			if field.FromBaseType || (field.Hidden && !hidden) {
+				continue
+			}
+		}
+		fields = append(fields, *field)
+	}
+
+	return &schema{
+		Name:       goTypeName(listInfo.Title),
+		Source:     fmt.Sprintf("%q list", listInfo.Title),
+		EntityType: listInfo.ListItemEntityTypeFullName,
+		Fields:     fields,
+	}, nil
+}
+
+// contentTypeSchema reads web or list content type fields schema, field links define fields order
+func contentTypeSchema(ctx context.Context, sp *api.SP, listTitle string, contentType string, hidden bool) (*schema, error) {
+	cts := sp.Web().ContentTypes()
+	entityType := ""
+	if listTitle != "" {
+		list := sp.Web().Lists().GetByTitle(listTitle)
+		cts = list.ContentTypes()
+		var err error
+		if entityType, err = list.GetEntityType(ctx); err != nil {
+			return nil, fmt.Errorf("unable to get list: %w", err)
+		}
+	}
+
+	ctID := contentType
+	if !strings.HasPrefix(strings.ToLower(contentType), "0x") {
+		ctsResp, err := cts.Select("StringId,Name").Filter(fmt.Sprintf("Name eq '%s'", strings.ReplaceAll(contentType, "'", "''"))).Get(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get content types: %w", err)
+		}
+		data := ctsResp.Data()
+		if len(data) == 0 {
+			return nil, fmt.Errorf("content type %q is not found", contentType)
+		}
+		ctID = data[0].Data().ID
+	}
+
+	ct := cts.GetByID(ctID)
+	ctResp, err := ct.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get content type: %w", err)
+	}
+	ctInfo := ctResp.Data()
+
+	linksResp, err := ct.FieldLinks().Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get field links: %w", err)
+	}
+	fieldsResp, err := ct.FieldLinks().GetFields(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get content type fields: %w", err)
+	}
+
+	byName := map[string]*api.FieldInfo{}
+	for _, f := range fieldsResp.Data() {
+		field := f.Data()
+		byName[field.InternalName] = field
+	}
+
+	fields := []api.FieldInfo{*fieldOrDefault(byName, "ID", "Counter")}
+	for _, l := range linksResp.Data() {
+		link := l.Data()
+		if link.Hidden && !hidden {
+			continue
+		}
+		field, ok := byName[link.Name]
+		if !ok || link.Name == "ID" || link.Name == "ContentType" {
+			continue
+		}
+		fields = append(fields, *field)
+	}
+
+	return &schema{
+		Name:       goTypeName(ctInfo.Name),
+		Source:     fmt.Sprintf("%q content type", ctInfo.Name),
+		EntityType: entityType,
+		Fields:     fields,
+	}, nil
+}
+
+// fieldOrDefault gets field by internal name or a default field definition
+func fieldOrDefault(fields map[string]*api.FieldInfo, name string, typeAsString string) *api.FieldInfo {
+	if f, ok := fields[name]; ok {
+		return f
+	}
+	return &api.FieldInfo{InternalName: name, Title: name, TypeAsString: typeAsString}
+}
@@ -4,16 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
-
-	"github.com/patrickmn/go-cache"
 )
 
-var storage = cache.New(5*time.Minute, 10*time.Minute)
-
 type contextInfoResponse struct {
 	D struct {
 		GetContextWebInformation struct {
@@ -24,16 +21,25 @@ type contextInfoResponse struct {
 	} `json:"d"`
 }
 
-// GetDigest retrieves and caches SharePoint API X-RequestDigest value
+// GetDigest retrieves and caches SharePoint API X-RequestDigest value of the client's site
 func GetDigest(context context.Context, client *SPClient) (string, error) {
-	siteURL := client.AuthCnfg.GetSiteURL()
+	return GetWebDigest(context, client, client.AuthCnfg.GetSiteURL())
+}
 
-	cacheKey := siteURL + "@digest@" + fmt.Sprintf("%#v", client.AuthCnfg)
-	if digestValue, found := storage.Get(cacheKey); found {
-		return digestValue.(string), nil
+// GetWebDigest retrieves and caches SharePoint API X-RequestDigest value of a web,
+// digests are cached per web so a client can write to different sites
+func GetWebDigest(context context.Context, client *SPClient, webURL string) (string, error) {
+	webURL = strings.TrimRight(webURL, "/")
+
+	// Digest is not cached when the auth config can't be identified
+	cacheKey, cacheErr := digestCacheKey(client, webURL)
+	if cacheErr == nil {
+		if digestValue, _, found := client.GetCache().Get(cacheKey); found {
+			return digestValue, nil
+		}
 	}
 
-	contextInfoURL := siteURL + "/_api/ContextInfo"
+	contextInfoURL := webURL + "/_api/ContextInfo"
 	req, err := http.NewRequestWithContext(context, "POST", contextInfoURL, nil)
 	if err != nil {
 		return "", err
@@ -69,11 +75,43 @@ func GetDigest(context context.Context, client *SPClient) (string, error) {
 
 	expiry := (results.D.GetContextWebInformation.FormDigestTimeoutSeconds - 60) * time.Second
 
-	storage.Set(
-		cacheKey,
-		results.D.GetContextWebInformation.FormDigestValue,
-		expiry,
-	)
+	if cacheErr == nil {
+		client.GetCache().Set(
+			cacheKey,
+			results.D.GetContextWebInformation.FormDigestValue,
+			expiry,
+		)
+	}
 
 	return results.D.GetContextWebInformation.FormDigestValue, nil
 }
+
+// digestCacheKey gets web digest cache key, the auth config is identified by the hash of its strategy
+// and the whole serialized config, so neither any config field is left out nor secrets get to the shared cache
+func digestCacheKey(client *SPClient, webURL string) (string, error) {
+	cnfg, err := json.Marshal(client.AuthCnfg)
+	if err != nil {
+		return "", err
+	}
+	// Same web can be addressed with encoded and decoded paths
+	if u, err := url.Parse(webURL); err == nil {
+		webURL = u.Scheme + "://" + u.Host + u.Path
+	}
+	return strings.ToLower(webURL) + "@digest@" + CacheKeyHash(client.AuthCnfg.GetStrategy(), string(cnfg)), nil
+}
+
+// getRequestWebURL resolves target web URL of a REST or CSOM request,
+// the client's site URL is used when the request URL has no service endpoint part
+func getRequestWebURL(req *http.Request, siteURL string) string {
+	path := req.URL.Path + "/"
+	for _, endpoint := range []string{"/_api/", "/_vti_bin/"} {
+		// Case insensitive search keeping byte offsets of the original path
+		for i := 0; i+len(endpoint) <= len(path); i++ {
+			if strings.EqualFold(path[i:i+len(endpoint)], endpoint) {
+				webURL := &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: path[:i]}
+				return webURL.String()
+			}
+		}
+	}
+	return siteURL
+}
@@ -1,8 +1,15 @@
 package gosip
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -43,3 +50,111 @@ func TestDigest(t *testing.T) {
 	})
 
 }
+
+func TestWebDigest(t *testing.T) {
+	siteURL := "http://localhost:8989/sites/a"
+	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(strings.ToLower(r.URL.Path), "/_api/contextinfo") {
+			// digest value is the web path for the test sake
+			webPath := r.URL.Path[:len(r.URL.Path)-len("/_api/ContextInfo")]
+			_, _ = fmt.Fprintf(w, `{"d":{"GetContextWebInformation":{"FormDigestValue":"%s","FormDigestTimeoutSeconds":120}}}`, webPath)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `%s`, r.Header.Get("X-RequestDigest"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	client := &SPClient{
+		AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+		Cache:    NewMemoryCache(),
+	}
+
+	cases := map[string]string{
+		siteURL + "/_api/web/lists":                                      "/sites/a",
+		"http://localhost:8989/sites/b/_api/web/lists":                   "/sites/b",
+		"http://localhost:8989/sites/b/sub/_API/$batch":                  "/sites/b/sub",
+		"http://localhost:8989/sites/c/_vti_bin/client.svc/ProcessQuery": "/sites/c",
+		"http://localhost:8989/sites/d/_api":                             "/sites/d",
+	}
+	for endpoint, expected := range cases {
+		req, err := http.NewRequest("POST", endpoint, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(digest) != expected {
+			t.Errorf("%s: expected digest of %s web, got %s", endpoint, expected, digest)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "http://localhost:8989/custom/endpoint", nil)
+	if webURL := getRequestWebURL(req, siteURL); webURL != siteURL {
+		t.Errorf("site URL should be used for non API endpoints, got %s", webURL)
+	}
+}
+
+func TestDigestCacheKey(t *testing.T) {
+	type credsCnfg struct {
+		AnonymousCnfg
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Endpoint string `json:"endpoint"`
+	}
+	siteURL := "http://localhost:8989/sites/a"
+	john := &SPClient{AuthCnfg: &credsCnfg{AnonymousCnfg{SiteURL: siteURL}, "john@contoso.com", "p@ssw0rd", ""}}
+	jane := &SPClient{AuthCnfg: &credsCnfg{AnonymousCnfg{SiteURL: siteURL}, "jane@contoso.com", "p@ssw0rd", ""}}
+	johnAlt := &SPClient{AuthCnfg: &credsCnfg{AnonymousCnfg{SiteURL: siteURL}, "john@contoso.com", "p@ssw0rd", "https://sts.contoso.com"}}
+
+	key, err := digestCacheKey(john, siteURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("p@ssw0rd"))
+	if strings.Contains(key, "p@ssw0rd") || strings.Contains(key, hex.EncodeToString(hash[:])) {
+		t.Errorf("digest cache key should not contain secrets: %s", key)
+	}
+	if janeKey, _ := digestCacheKey(jane, siteURL); key == janeKey {
+		t.Error("different identities should not share the digest")
+	}
+	if altKey, _ := digestCacheKey(johnAlt, siteURL); key == altKey {
+		t.Error("configs differing in any field should not share the digest")
+	}
+}
+
+func TestDigestCacheKeyMarshalError(t *testing.T) {
+	type badCnfg struct {
+		AnonymousCnfg
+		Callback func() `json:"callback"`
+	}
+	var digestRequests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&digestRequests, 1)
+		_, _ = fmt.Fprintf(w, `{"d":{"GetContextWebInformation":{"FormDigestValue":"FAKE","FormDigestTimeoutSeconds":120,"LibraryVersion":"FAKE"}}}`)
+	}))
+	defer srv.Close()
+	siteURL := srv.URL
+
+	client := &SPClient{
+		AuthCnfg: &badCnfg{AnonymousCnfg: AnonymousCnfg{SiteURL: siteURL}},
+		Cache:    NewMemoryCache(),
+	}
+	if _, err := digestCacheKey(client, siteURL); err == nil {
+		t.Error("should fail on not serializable config")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := GetDigest(context.Background(), client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&digestRequests) != 2 {
+		t.Error("digest should not be cached when the config can't be identified")
+	}
+}
@@ -0,0 +1,121 @@
+package gosip
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SPError is SharePoint API error, returned for non-2xx responses and CSOM error infos.
+// Use errors.As or Is* helpers to inspect an error
+type SPError struct {
+	StatusCode    int    // HTTP response status code
+	Status        string // HTTP response status, e.g. "404 Not Found", empty for CSOM errors
+	Code          string // OData or CSOM error code, e.g. "-2130575338, Microsoft.SharePoint.SPException"
+	Message       string // error message
+	CorrelationID string // SPRequestGuid or CSOM TraceCorrelationId
+	Retries       int    // number of retries made before the error
+	Body          []byte // raw response body
+}
+
+// Error returns error message
+func (e *SPError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("%s (Code: %s, Correlation ID: %s)", e.Message, e.Code, e.CorrelationID)
+	}
+	details := string(e.Body)
+	// Unescape unicode-escaped error messages for non Latin languages
+	if unescaped, err := strconv.Unquote(`"` + strings.Replace(details, `"`, `\"`, -1) + `"`); err == nil {
+		details = unescaped
+	}
+	return fmt.Sprintf("%s :: %s", e.Status, details)
+}
+
+// IsNotFound checks if the error is SharePoint "not found" error
+func IsNotFound(err error) bool {
+	var spErr *SPError
+	if !errors.As(err, &spErr) {
+		return false
+	}
+	return spErr.StatusCode == 404 || strings.Contains(spErr.Code, "FileNotFoundException")
+}
+
+// IsThrottled checks if the error is SharePoint throttling error
+func IsThrottled(err error) bool {
+	var spErr *SPError
+	if !errors.As(err, &spErr) {
+		return false
+	}
+	return spErr.StatusCode == 429 || spErr.StatusCode == 503
+}
+
+// IsAccessDenied checks if the error is SharePoint access denied error
+func IsAccessDenied(err error) bool {
+	var spErr *SPError
+	if !errors.As(err, &spErr) {
+		return false
+	}
+	return spErr.StatusCode == 401 || spErr.StatusCode == 403 || strings.Contains(spErr.Code, "UnauthorizedAccessException")
+}
+
+// IsConflict checks if the error is SharePoint conflict error, e.g. item version (ETag) mismatch
+func IsConflict(err error) bool {
+	var spErr *SPError
+	if !errors.As(err, &spErr) {
+		return false
+	}
+	return spErr.StatusCode == 409 || spErr.StatusCode == 412
+}
+
+// NewSPError creates SharePoint API error from the error state response and its body
+func NewSPError(resp *http.Response, body []byte) *SPError {
+	spErr := &SPError{
+		StatusCode:    resp.StatusCode,
+		Status:        resp.Status,
+		CorrelationID: resp.Header.Get("SPRequestGuid"),
+		Body:          body,
+	}
+	if spErr.CorrelationID == "" {
+		spErr.CorrelationID = resp.Header.Get("request-id")
+	}
+	spErr.Retries = RetryAttempt(resp.Request)
+	spErr.Code, spErr.Message = parseODataError(body)
+	return spErr
+}
+
+// parseODataError parses OData error response payload taking care of OData mode
+func parseODataError(body []byte) (string, string) {
+	type oDataError struct {
+		Code    string          `json:"code"`
+		Message json.RawMessage `json:"message"`
+	}
+	r := &struct {
+		// Verbose OData structure
+		Error *oDataError `json:"error"`
+		// Minimalmatadata/Nometadata OData structure
+		ODataError *oDataError `json:"odata.error"`
+	}{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", ""
+	}
+	e := r.Error
+	if e == nil {
+		e = r.ODataError
+	}
+	if e == nil {
+		return "", ""
+	}
+	// Message is either an object with language and value or a plain string
+	message := &struct {
+		Value string `json:"value"`
+	}{}
+	if err := json.Unmarshal(e.Message, &message); err == nil {
+		return e.Code, message.Value
+	}
+	var value string
+	_ = json.Unmarshal(e.Message, &value)
+	return e.Code, value
+}
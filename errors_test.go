@@ -0,0 +1,135 @@
+package gosip
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	siteURL := "http://localhost:8989"
+	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("SPRequestGuid", "b1a2c3d4-0000-0000-0000-000000000000")
+		if r.RequestURI == "/_api/throttled" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"odata.error":{"code":"-2147024860, Microsoft.SharePoint.SPQueryThrottledException","message":{"lang":"en-US","value":"Throttled"}}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"-2130575338, Microsoft.SharePoint.SPException","message":{"lang":"en-US","value":"Item does not exist."}}}`))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	t.Run("NotFound", func(t *testing.T) {
+		client := &SPClient{
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+		}
+
+		req, err := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/missing", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.Execute(req)
+		var spErr *SPError
+		if !errors.As(err, &spErr) {
+			t.Fatalf("should return SPError, got %T", err)
+		}
+		if spErr.StatusCode != 404 {
+			t.Errorf("incorrect status code, expected 404, got %d", spErr.StatusCode)
+		}
+		if spErr.Code != "-2130575338, Microsoft.SharePoint.SPException" {
+			t.Errorf("incorrect error code: %s", spErr.Code)
+		}
+		if spErr.Message != "Item does not exist." {
+			t.Errorf("incorrect error message: %s", spErr.Message)
+		}
+		if spErr.CorrelationID != "b1a2c3d4-0000-0000-0000-000000000000" {
+			t.Errorf("incorrect correlation ID: %s", spErr.CorrelationID)
+		}
+		if !IsNotFound(fmt.Errorf("wrapped: %w", err)) {
+			t.Error("wrapped error should be detected as not found")
+		}
+		if IsThrottled(err) || IsAccessDenied(err) || IsConflict(err) {
+			t.Error("error kind is detected incorrectly")
+		}
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		client := &SPClient{
+			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
+			RetryPolicies: map[int]int{429: 1},
+		}
+
+		req, err := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/throttled", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.Execute(req)
+		var spErr *SPError
+		if !errors.As(err, &spErr) {
+			t.Fatalf("should return SPError, got %T", err)
+		}
+		if !IsThrottled(err) {
+			t.Error("error should be detected as throttled")
+		}
+		if spErr.Retries != 1 {
+			t.Errorf("incorrect retries number, expected 1, got %d", spErr.Retries)
+		}
+		if spErr.Message != "Throttled" {
+			t.Errorf("incorrect error message: %s", spErr.Message)
+		}
+	})
+}
+
+func TestSPError(t *testing.T) {
+	t.Run("RESTMessage", func(t *testing.T) {
+		err := &SPError{
+			StatusCode: 404,
+			Status:     "404 Not Found",
+			Body:       []byte(`{"error":"Не найдено"}`),
+		}
+		if err.Error() != `404 Not Found :: {"error":"Не найдено"}` {
+			t.Errorf("incorrect error message: %s", err)
+		}
+	})
+
+	t.Run("CSOMMessage", func(t *testing.T) {
+		err := &SPError{
+			StatusCode:    200,
+			Code:          "-2147024894, System.IO.FileNotFoundException",
+			Message:       "File Not Found.",
+			CorrelationID: "guid",
+		}
+		if err.Error() != "File Not Found. (Code: -2147024894, System.IO.FileNotFoundException, Correlation ID: guid)" {
+			t.Errorf("incorrect error message: %s", err)
+		}
+		if !IsNotFound(err) {
+			t.Error("CSOM error should be detected as not found")
+		}
+	})
+
+	t.Run("PlainMessage", func(t *testing.T) {
+		code, message := parseODataError([]byte(`{"error":{"code":"accessDenied","message":"Access denied."}}`))
+		if code != "accessDenied" || message != "Access denied." {
+			t.Errorf("can't parse plain error message, got %s: %s", code, message)
+		}
+	})
+
+	t.Run("Helpers", func(t *testing.T) {
+		if !IsAccessDenied(&SPError{StatusCode: 403}) {
+			t.Error("403 should be detected as access denied")
+		}
+		if !IsConflict(&SPError{StatusCode: 412}) {
+			t.Error("412 should be detected as conflict")
+		}
+		if IsNotFound(errors.New("404 Not Found")) {
+			t.Error("non SPError should not be detected")
+		}
+	})
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -57,17 +56,39 @@ type SPClient struct {
 	AuthCnfg   AuthCnfg // authentication configuration interface
 	ConfigPath string   // private.json location path, optional when AuthCnfg is provided with creds explicitly
 
-	RetryPolicies map[int]int   // allows redefining error state requests retry policies
+	RetryPolicies map[int]int   // allows redefining error state requests retry policies of the default retry strategy
+	RetryStrategy RetryStrategy // custom retry strategy, RetryPolicies are ignored when provided
+	Governor      *Governor     // optional adaptive client-side throttling governor
 	Hooks         *HookHandlers // hook handlers definition
+	Cache         Cache         // digests and metadata cache, also used by auth strategies with no own cache, DefaultCache when not provided
+
+	middlewares []Middleware // custom middlewares, see Use
 }
 
 // Execute : SharePoint HTTP client
 // is a wrapper for standard http.Client' `Do` method, injects authorization tokens, etc.
+// The request is passed through the client's middlewares chain
 func (c *SPClient) Execute(req *http.Request) (*http.Response, error) {
+	return c.chain().Do(req)
+}
+
+// GetCache gets client's cache, DefaultCache is used when no custom cache is provided
+func (c *SPClient) GetCache() Cache {
+	if c.Cache != nil {
+		return c.Cache
+	}
+	return DefaultCache
+}
+
+// execute applies authentication, default headers, retries and sends the request
+func (c *SPClient) execute(req *http.Request) (*http.Response, error) {
 	reqTime := time.Now()
+	req = applyNoRetryHeader(req)
 
 	// Apply authentication flow
-	if res, err := c.applyAuth(req); err != nil {
+	res, err := c.applyAuth(req)
+	c.onAuth(req, reqTime, err)
+	if err != nil {
 		c.onError(req, reqTime, 0, err)
 		return res, err
 	}
@@ -87,56 +108,52 @@ func (c *SPClient) Execute(req *http.Request) (*http.Response, error) {
 	c.onRequest(req, reqTime, 0, nil)
 	reqTime = time.Now() // update request time to exclude auth-related timings
 
-	// Creating backup reader to be able to retry none nil body requests
-	var bodyBackup io.Reader
-	if req.Body != nil {
-		var buf bytes.Buffer
-		tee := io.TeeReader(req.Body, &buf)
-		bodyBackup = &buf
-		req.Body = io.NopCloser(tee)
-	}
+	// Making request body replayable to be able to retry none nil body requests
+	prepareBody(req)
 
-	// Sending actual request to SharePoint API/resource
-	resp, err := c.Do(req)
+	// Wait for the governor's slot, limits are applied per each attempt
+	release, err := c.Governor.acquire(req)
 	if err != nil {
-		// Retry only for NTLM
-		if c.AuthCnfg.GetStrategy() == "ntlm" && c.shouldRetry(req, resp, 5) {
-			statusCode := 400
-			if resp != nil {
-				statusCode = resp.StatusCode
-			}
-			c.onRetry(req, reqTime, statusCode, nil)
-			// Reset body reader closer
-			if bodyBackup != nil {
-				req.Body = io.NopCloser(bodyBackup)
-			}
-			return c.Execute(req)
-		}
 		c.onError(req, reqTime, 0, err)
-		return resp, err
+		return nil, err
 	}
 
-	// Wait and retry after a delay for error state responses, due to retry policies
-	if retries := c.getRetryPolicy(resp.StatusCode); retries > 0 {
+	// Sending actual request to SharePoint API/resource
+	resp, err := c.Do(req)
+	c.Governor.observe(req, resp)
+	release()
+	c.onAttempt(req, reqTime, resp, err)
+
+	// Wait and retry after a delay for error state responses, due to retry strategy
+	if retry, delay := c.shouldRetry(req, resp, err); retry {
+		statusCode := 400
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
 		// Register retry in OnError hook
 		// otherwise it only called in OnRetry after timeout right before the next call
-		if resp.StatusCode == 429 {
-			noRetry := req.Header.Get("X-Gosip-NoRetry")
-			retry, _ := strconv.Atoi(req.Header.Get("X-Gosip-Retry"))
-			if retry < retries && noRetry != "true" {
-				c.onError(req, reqTime, resp.StatusCode, nil)
-			}
+		if statusCode == 429 {
+			c.onError(req, reqTime, statusCode, nil)
 		}
-
-		// When it should, shouldRetry not only checks but waits before a retry
-		if c.shouldRetry(req, resp, retries) {
-			c.onRetry(req, reqTime, resp.StatusCode, nil)
-			// Reset body reader closer
-			if bodyBackup != nil {
-				req.Body = io.NopCloser(bodyBackup)
-			}
-			return c.Execute(req)
+		// Retry attempt is kept in the request context to not be sent to the server
+		req = withRetryAttempt(req, RetryAttempt(req)+1)
+		// Waits before a retry, stops when the context is canceled
+		if !c.waitRetry(req, resp, delay) {
+			c.onError(req, reqTime, statusCode, req.Context().Err())
+			return resp, req.Context().Err()
+		}
+		c.onRetry(req, reqTime, statusCode, delay)
+		// Reset body reader
+		if err := rewindBody(req); err != nil {
+			c.onError(req, reqTime, statusCode, err)
+			return resp, err
 		}
+		return c.execute(req)
+	}
+
+	if err != nil {
+		c.onError(req, reqTime, 0, err)
+		return resp, err
 	}
 
 	// Return meaningful error message
@@ -144,11 +161,7 @@ func (c *SPClient) Execute(req *http.Request) (*http.Response, error) {
 		var buf bytes.Buffer
 		tee := io.TeeReader(resp.Body, &buf)
 		details, _ := io.ReadAll(tee)
-		err = fmt.Errorf("%s :: %s", resp.Status, details)
-		// Unescape unicode-escaped error messages for non Latin languages
-		if unescaped, e := strconv.Unquote(`"` + strings.Replace(string(details), `"`, `\"`, -1) + `"`); e == nil {
-			err = fmt.Errorf("%s :: %s", resp.Status, unescaped)
-		}
+		err = NewSPError(resp, details)
 		resp.Body = io.NopCloser(&buf)
 		c.onError(req, reqTime, resp.StatusCode, err)
 	}
@@ -196,7 +209,9 @@ func (c *SPClient) applyHeaders(req *http.Request) error {
 		req.Header.Get("X-RequestDigest") == ""
 
 	if digestIsRequired {
-		digest, err := GetDigest(req.Context(), c)
+		// Digest is resolved for the target web so writes to other sites reached from the client are valid
+		webURL := getRequestWebURL(req, c.AuthCnfg.GetSiteURL())
+		digest, err := GetWebDigest(req.Context(), c, webURL)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,244 @@
+package gosip
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// governorGrowthInterval is a minimal interval between limits growth steps
+const governorGrowthInterval = time.Second
+
+// governorShrinkInterval is a minimal interval between limits shrink steps,
+// prevents a burst of concurrent throttled responses from collapsing the limits at once
+const governorShrinkInterval = time.Second
+
+// Governor is an adaptive client-side throttling governor which caps concurrent in-flight requests
+// and requests per second per host. Limits shrink on throttled (429/503) responses and RateLimit-* headers
+// and grow back slowly after a quiet period. Always use NewGovernor constructor instead of &Governor{}
+type Governor struct {
+	MaxConcurrency int           // upper concurrent in-flight requests limit per host, concurrency is not limited when 0
+	MinConcurrency int           // lower concurrent in-flight requests limit per host, at least 1 request is always allowed
+	MaxRate        float64       // upper requests per second limit per host, rate is not limited when 0
+	MinRate        float64       // lower requests per second limit per host
+	RecoveryPeriod time.Duration // quiet period after throttling before the limits start growing back
+
+	mu    sync.Mutex
+	hosts map[string]*hostGovernor
+}
+
+// GovernorStats current per host governor state
+type GovernorStats struct {
+	Concurrency int     // current concurrent in-flight requests limit
+	Rate        float64 // current requests per second limit
+	InFlight    int     // in-flight requests number
+}
+
+// hostGovernor per host limits state
+type hostGovernor struct {
+	mu          sync.Mutex
+	concurrency float64
+	rate        float64
+	inFlight    int
+	next        time.Time     // next request slot due to the rate limit or throttling pause
+	throttledAt time.Time     // last throttling signal time
+	shrunkAt    time.Time     // last limits shrink time
+	grownAt     time.Time     // last limits growth time
+	changed     chan struct{} // closed and replaced when in-flight requests or limits change
+}
+
+// NewGovernor creates throttling governor with concurrency and requests per second limits per host
+func NewGovernor(maxConcurrency int, maxRate float64) *Governor {
+	return &Governor{
+		MaxConcurrency: maxConcurrency,
+		MinConcurrency: 1,
+		MaxRate:        maxRate,
+		MinRate:        1,
+		RecoveryPeriod: 30 * time.Second,
+	}
+}
+
+// Stats gets current governor state for the host
+func (g *Governor) Stats(host string) GovernorStats {
+	h := g.getHost(host)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return GovernorStats{
+		Concurrency: int(h.concurrency),
+		Rate:        h.rate,
+		InFlight:    h.inFlight,
+	}
+}
+
+// acquire waits for a request slot, returned release function must be called after the response is received
+func (g *Governor) acquire(req *http.Request) (func(), error) {
+	if g == nil {
+		return func() {}, nil
+	}
+
+	h := g.getHost(req.URL.Host)
+	ctx := req.Context()
+
+	// Concurrency limit
+	for {
+		h.mu.Lock()
+		if h.inFlight < int(h.concurrency) {
+			h.inFlight++
+			h.mu.Unlock()
+			break
+		}
+		changed := h.changed
+		h.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+
+	release := func() {
+		h.mu.Lock()
+		h.inFlight--
+		h.notify()
+		h.mu.Unlock()
+	}
+
+	// Rate limit and throttling pause
+	h.mu.Lock()
+	now := time.Now()
+	slot := now
+	if h.next.After(slot) {
+		slot = h.next
+	}
+	if h.rate > 0 {
+		h.next = slot.Add(time.Duration(float64(time.Second) / h.rate))
+	}
+	h.mu.Unlock()
+
+	if err := sleepContext(ctx, slot.Sub(now)); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// observe adapts the limits due to the response
+func (g *Governor) observe(req *http.Request, resp *http.Response) {
+	if g == nil || resp == nil {
+		return
+	}
+
+	h := g.getHost(req.URL.Host)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+
+	if resp.StatusCode == 429 || resp.StatusCode == 503 {
+		h.throttledAt = now
+		if now.Sub(h.shrunkAt) >= governorShrinkInterval {
+			h.shrunkAt = now
+			if g.MaxConcurrency > 0 {
+				minConcurrency := math.Max(1, float64(g.MinConcurrency))
+				h.concurrency = math.Max(minConcurrency, math.Floor(h.concurrency/2))
+			}
+			if h.rate > 0 {
+				h.rate = math.Max(g.MinRate, h.rate/2)
+			}
+			h.notify()
+		}
+		// Pause all the host requests for Retry-After period
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			h.pause(now.Add(retryAfter))
+		}
+		return
+	}
+
+	// SPO sends RateLimit-* headers when an app is approaching the limits
+	remaining, errRemaining := strconv.Atoi(resp.Header.Get("RateLimit-Remaining"))
+	reset, errReset := strconv.Atoi(resp.Header.Get("RateLimit-Reset"))
+	if errRemaining == nil && errReset == nil && reset > 0 {
+		h.throttledAt = now
+		if remaining <= 0 {
+			h.pause(now.Add(time.Duration(reset) * time.Second))
+			return
+		}
+		// Spread the remaining quota over the reset window
+		if target := float64(remaining) / float64(reset); h.rate == 0 || target < h.rate {
+			h.rate = math.Max(g.MinRate, target)
+			h.notify()
+		}
+		return
+	}
+
+	// Grow the limits back after the quiet period
+	if now.Sub(h.throttledAt) < g.RecoveryPeriod || now.Sub(h.grownAt) < governorGrowthInterval {
+		return
+	}
+	h.grownAt = now
+	if g.MaxConcurrency > 0 && h.concurrency < float64(g.MaxConcurrency) {
+		h.concurrency++
+		h.notify()
+	}
+	if g.MaxRate == 0 {
+		h.rate = 0 // rate was limited due to RateLimit-* headers only
+	}
+	if g.MaxRate > 0 && h.rate < g.MaxRate {
+		h.rate = math.Min(g.MaxRate, h.rate+math.Max(g.MinRate, g.MaxRate/10))
+	}
+}
+
+// getHost gets or creates host limits state
+func (g *Governor) getHost(host string) *hostGovernor {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.hosts == nil {
+		g.hosts = map[string]*hostGovernor{}
+	}
+	h, ok := g.hosts[host]
+	if !ok {
+		concurrency := g.MaxConcurrency
+		if concurrency <= 0 {
+			concurrency = math.MaxInt32
+		}
+		h = &hostGovernor{
+			concurrency: float64(concurrency),
+			rate:        g.MaxRate,
+			changed:     make(chan struct{}),
+		}
+		g.hosts[host] = h
+	}
+	return h
+}
+
+// pause postpones next host request till the time
+func (h *hostGovernor) pause(till time.Time) {
+	if till.After(h.next) {
+		h.next = till
+	}
+}
+
+// notify wakes up requests waiting for a concurrency slot, must be called under lock
+func (h *hostGovernor) notify() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// sleepContext waits for the duration or the context cancellation
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,158 @@
+package gosip
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGovernor(t *testing.T) {
+	siteURL := "http://localhost:8989"
+	var inFlight, maxInFlight int32
+	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `{ "result": "governed" }`)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	t.Run("ConcurrencyLimit", func(t *testing.T) {
+		client := &SPClient{
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+			Governor: NewGovernor(2, 0),
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/get", nil)
+				resp, err := client.Execute(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_ = resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		if atomic.LoadInt32(&maxInFlight) > 2 {
+			t.Errorf("concurrency limit is ignored, got %d in-flight requests", maxInFlight)
+		}
+		if client.Governor.Stats("localhost:8989").InFlight != 0 {
+			t.Error("in-flight requests are not released")
+		}
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		client := &SPClient{
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+			Governor: NewGovernor(0, 10),
+		}
+
+		beforeReq := time.Now()
+		for i := 0; i < 4; i++ {
+			req, _ := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/get", nil)
+			resp, err := client.Execute(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+		}
+
+		if time.Since(beforeReq) < 300*time.Millisecond {
+			t.Error("rate limit is ignored")
+		}
+	})
+}
+
+func TestGovernorAdaptation(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://contoso.sharepoint.com/_api/web", nil)
+	host := "contoso.sharepoint.com"
+
+	t.Run("ShrinkOnThrottling", func(t *testing.T) {
+		g := NewGovernor(8, 20)
+		g.observe(req, &http.Response{StatusCode: 429, Header: http.Header{}})
+		stats := g.Stats(host)
+		if stats.Concurrency != 4 || stats.Rate != 10 {
+			t.Errorf("limits should be halved, got %+v", stats)
+		}
+		// a burst of throttled responses shrinks the limits once
+		g.observe(req, &http.Response{StatusCode: 503, Header: http.Header{}})
+		if stats := g.Stats(host); stats.Concurrency != 4 {
+			t.Errorf("limits should not collapse on a burst, got %+v", stats)
+		}
+	})
+
+	t.Run("ShrinkKeepsOneSlot", func(t *testing.T) {
+		g := &Governor{MaxConcurrency: 1}
+		g.observe(req, &http.Response{StatusCode: 429, Header: http.Header{}})
+		if stats := g.Stats(host); stats.Concurrency != 1 {
+			t.Errorf("concurrency should not drop below 1, got %+v", stats)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		release, err := g.acquire(req.WithContext(ctx))
+		if err != nil {
+			t.Fatalf("request slot should be available: %s", err)
+		}
+		release()
+	})
+
+	t.Run("RetryAfterPause", func(t *testing.T) {
+		g := NewGovernor(8, 0)
+		g.observe(req, &http.Response{StatusCode: 429, Header: http.Header{"Retry-After": []string{"1"}}})
+		beforeReq := time.Now()
+		release, err := g.acquire(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+		if time.Since(beforeReq) < 900*time.Millisecond {
+			t.Error("requests should be paused for Retry-After period")
+		}
+	})
+
+	t.Run("RateLimitHeaders", func(t *testing.T) {
+		g := NewGovernor(8, 100)
+		g.observe(req, &http.Response{StatusCode: 200, Header: http.Header{
+			"Ratelimit-Limit":     []string{"1200"},
+			"Ratelimit-Remaining": []string{"100"},
+			"Ratelimit-Reset":     []string{"10"},
+		}})
+		if stats := g.Stats(host); stats.Rate != 10 {
+			t.Errorf("rate should follow RateLimit headers, got %+v", stats)
+		}
+	})
+
+	t.Run("Recovery", func(t *testing.T) {
+		g := NewGovernor(8, 20)
+		g.RecoveryPeriod = 0
+		g.observe(req, &http.Response{StatusCode: 429, Header: http.Header{}})
+		g.observe(req, &http.Response{StatusCode: 200, Header: http.Header{}})
+		if stats := g.Stats(host); stats.Concurrency != 5 || stats.Rate != 12 {
+			t.Errorf("limits should grow back after quiet period, got %+v", stats)
+		}
+		// growth is slow
+		g.observe(req, &http.Response{StatusCode: 200, Header: http.Header{}})
+		if stats := g.Stats(host); stats.Concurrency != 5 {
+			t.Errorf("limits should grow once per interval, got %+v", stats)
+		}
+	})
+}
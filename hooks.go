@@ -1,6 +1,7 @@
 package gosip
 
 import (
+	"context"
 	"net/http"
 	"time"
 )
@@ -11,6 +12,8 @@ type HookHandlers struct {
 	OnRetry    func(event *HookEvent) // before retry request
 	OnRequest  func(event *HookEvent) // before request is sent
 	OnResponse func(event *HookEvent) // after response is received
+	OnAuth     func(event *HookEvent) // after authentication is applied to the request
+	OnAttempt  func(event *HookEvent) // after each request attempt, before the retry decision
 }
 
 // HookEvent hook event parameters struct
@@ -19,68 +22,118 @@ type HookEvent struct {
 	StartedAt  time.Time
 	StatusCode int
 	Error      error
+	Attempt    int            // retry attempt number, 0 for the initial request
+	Response   *http.Response // attempt response, only for OnAttempt, nil when no response is received
+	Delay      time.Duration  // waited delay before the retry, only for OnRetry
 }
 
-// onError on error hook handler
-func (c *SPClient) onError(req *http.Request, startAt time.Time, statusCode int, err error) {
+// hooksCtxKey context key for request scoped hook handlers
+type hooksCtxKey struct{}
+
+// WithHooks returns a copy of the context with additional hook handlers for the requests,
+// the handlers are called along with the client's ones
+func WithHooks(ctx context.Context, hooks *HookHandlers) context.Context {
+	if hooks == nil {
+		return ctx
+	}
+	parent, _ := ctx.Value(hooksCtxKey{}).([]*HookHandlers)
+	for _, h := range parent {
+		if h == hooks {
+			return ctx // already applied, e.g. digest request within a parent request context
+		}
+	}
+	handlers := append(append([]*HookHandlers{}, parent...), hooks)
+	return context.WithValue(ctx, hooksCtxKey{}, handlers)
+}
+
+// hooksMiddleware is a built-in middleware which applies client's hook handlers to the request
+func (c *SPClient) hooksMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if c.Hooks != nil {
+			req = req.WithContext(WithHooks(req.Context(), c.Hooks))
+		}
+		return next.Do(req)
+	})
+}
+
+// getHooks gets hook handlers applied to the request
+func getHooks(req *http.Request) []*HookHandlers {
 	if req.Header.Get("X-Gosip-NoHooks") == "true" {
-		return
+		return nil
 	}
+	handlers, _ := req.Context().Value(hooksCtxKey{}).([]*HookHandlers)
+	return handlers
+}
 
-	if c.Hooks != nil && c.Hooks.OnError != nil {
-		c.Hooks.OnError(&HookEvent{
-			Request:    req,
-			StartedAt:  startAt,
-			StatusCode: statusCode,
-			Error:      err,
-		})
+// newHookEvent creates hook event for the request
+func newHookEvent(req *http.Request, startAt time.Time, statusCode int, err error) *HookEvent {
+	return &HookEvent{
+		Request:    req,
+		StartedAt:  startAt,
+		StatusCode: statusCode,
+		Error:      err,
+		Attempt:    RetryAttempt(req),
 	}
 }
 
-// onRetry on retry hook handler
-func (c *SPClient) onRetry(req *http.Request, startAt time.Time, statusCode int, err error) {
-	if req.Header.Get("X-Gosip-NoHooks") == "true" {
-		return
+// onError on error hook handler
+func (c *SPClient) onError(req *http.Request, startAt time.Time, statusCode int, err error) {
+	for _, hooks := range getHooks(req) {
+		if hooks.OnError != nil {
+			hooks.OnError(newHookEvent(req, startAt, statusCode, err))
+		}
 	}
+}
 
-	if c.Hooks != nil && c.Hooks.OnRetry != nil {
-		c.Hooks.OnRetry(&HookEvent{
-			Request:    req,
-			StartedAt:  startAt,
-			StatusCode: statusCode,
-			Error:      err,
-		})
+// onRetry on retry hook handler
+func (c *SPClient) onRetry(req *http.Request, startAt time.Time, statusCode int, delay time.Duration) {
+	for _, hooks := range getHooks(req) {
+		if hooks.OnRetry != nil {
+			event := newHookEvent(req, startAt, statusCode, nil)
+			event.Delay = delay
+			hooks.OnRetry(event)
+		}
 	}
 }
 
 // onResponse on response hook handler
 func (c *SPClient) onResponse(req *http.Request, startAt time.Time, statusCode int, err error) {
-	if req.Header.Get("X-Gosip-NoHooks") == "true" {
-		return
-	}
-
-	if c.Hooks != nil && c.Hooks.OnResponse != nil {
-		c.Hooks.OnResponse(&HookEvent{
-			Request:    req,
-			StartedAt:  startAt,
-			StatusCode: statusCode,
-			Error:      err,
-		})
+	for _, hooks := range getHooks(req) {
+		if hooks.OnResponse != nil {
+			hooks.OnResponse(newHookEvent(req, startAt, statusCode, err))
+		}
 	}
 }
 
 // onRequest on response hook handler
 func (c *SPClient) onRequest(req *http.Request, startAt time.Time, statusCode int, err error) {
-	if req.Header.Get("X-Gosip-NoHooks") == "true" {
-		return
+	for _, hooks := range getHooks(req) {
+		if hooks.OnRequest != nil {
+			hooks.OnRequest(newHookEvent(req, startAt, statusCode, err))
+		}
+	}
+}
+
+// onAuth on authentication hook handler
+func (c *SPClient) onAuth(req *http.Request, startAt time.Time, err error) {
+	for _, hooks := range getHooks(req) {
+		if hooks.OnAuth != nil {
+			hooks.OnAuth(newHookEvent(req, startAt, 0, err))
+		}
 	}
+}
 
-	if c.Hooks != nil && c.Hooks.OnRequest != nil {
-		c.Hooks.OnRequest(&HookEvent{
-			Request:    req,
-			StartedAt:  startAt,
-			StatusCode: statusCode,
-			Error:      err,
-		})
+// onAttempt on attempt hook handler
+func (c *SPClient) onAttempt(req *http.Request, startAt time.Time, resp *http.Response, err error) {
+	for _, hooks := range getHooks(req) {
+		if hooks.OnAttempt != nil {
+			statusCode := 0
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+			event := newHookEvent(req, startAt, statusCode, err)
+			event.Response = resp
+			hooks.OnAttempt(event)
+		}
 	}
 }
@@ -23,7 +23,7 @@ func TestHooks(t *testing.T) {
 			return
 		}
 		// backoff after 2 retries
-		if r.Header.Get("X-Gosip-Retry") == "2" {
+		if r.Header.Get("X-Test-Attempt") == "2" {
 			_, _ = fmt.Fprintf(w, `{ "result": "Cool alfter some retries" }`)
 			return
 		}
@@ -51,6 +51,7 @@ func TestHooks(t *testing.T) {
 		}
 
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 3},
 			Hooks: &HookHandlers{
@@ -113,6 +114,7 @@ func TestHooks(t *testing.T) {
 		}
 
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 3},
 			Hooks: &HookHandlers{
@@ -0,0 +1,299 @@
+// Package instrumentation provides OpenTelemetry compatible tracing and metrics for SPClient requests.
+//
+// The package does not depend on OpenTelemetry SDK, Tracer and Meter interfaces mirror OpenTelemetry API
+// and can be adapted to it (or any other telemetry backend) with a few lines of code.
+package instrumentation
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/recolabs/gosip"
+)
+
+// Attribute keys
+const (
+	AttrSite         = "sharepoint.site"              // SharePoint site URL of the client
+	AttrPathTemplate = "sharepoint.api.path_template" // API path with literals replaced with placeholders
+	AttrRequestGUID  = "sharepoint.request_guid"      // SPRequestGuid response header
+	AttrThrottleWait = "sharepoint.throttle.wait_ms"  // total time waited before retries, milliseconds
+	AttrMethod       = "http.request.method"          // HTTP method
+	AttrStatusCode   = "http.response.status_code"    // HTTP response status code
+	AttrResendCount  = "http.request.resend_count"    // retry attempt number
+	AttrStrategy     = "sharepoint.auth.strategy"     // auth strategy name
+	AttrErrorType    = "error.type"                   // error type, status code or error
+	AttrURL          = "url.full"                     // request URL without query
+	AttrServer       = "server.address"               // SharePoint host
+	AttrRetryDelayMS = "sharepoint.retry.wait_ms"     // single retry wait, milliseconds
+)
+
+// Metric names
+const (
+	MetricRequests        = "gosip.client.requests"          // counter, Execute calls
+	MetricRetries         = "gosip.client.retries"           // counter, retries
+	MetricThrottled       = "gosip.client.throttled"         // counter, 429 responses
+	MetricRequestDuration = "gosip.client.request.duration"  // histogram, Execute call duration, seconds
+	MetricAttemptDuration = "gosip.client.attempt.duration"  // histogram, single attempt duration, seconds
+	MetricThrottleWait    = "gosip.client.throttle.duration" // histogram, wait before a retry, seconds
+)
+
+// Attribute key-value pair
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer creates spans, mirrors OpenTelemetry trace.Tracer
+type Tracer interface {
+	// Start creates a span started at the time and a context containing it
+	Start(ctx context.Context, spanName string, startTime time.Time, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single operation within a trace, mirrors OpenTelemetry trace.Span
+type Span interface {
+	SetAttributes(attrs ...Attribute) // sets attributes to the span
+	RecordError(err error)            // records an error and sets the span status to error
+	End()                             // completes the span
+}
+
+// Meter creates metric instruments, mirrors OpenTelemetry metric.Meter
+type Meter interface {
+	Counter(name string) Counter     // gets int64 counter
+	Histogram(name string) Histogram // gets float64 histogram
+}
+
+// Counter is a monotonic metric instrument
+type Counter interface {
+	Add(ctx context.Context, value int64, attrs ...Attribute)
+}
+
+// Histogram is a distribution metric instrument
+type Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Options instrumentation options, nil Tracer or Meter disables tracing or metrics correspondingly
+type Options struct {
+	Tracer Tracer
+	Meter  Meter
+}
+
+// callCtxKey context key for the instrumented Execute call state
+type callCtxKey struct{}
+
+// call instrumented Execute call state
+type call struct {
+	ctx          context.Context
+	opts         *Options
+	attrs        []Attribute
+	attempt      Span
+	attemptStart time.Time
+	throttleWait time.Duration
+}
+
+var (
+	quotedRegExp  = regexp.MustCompile(`'[^']*'`)
+	guidRegExp    = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	numericRegExp = regexp.MustCompile(`\(\d+\)`)
+)
+
+// Instrument adds instrumentation middleware to the client
+func Instrument(client *gosip.SPClient, opts *Options) *gosip.SPClient {
+	return client.Use(Middleware(client, opts))
+}
+
+// Middleware creates instrumentation middleware: a span per Execute call with child spans
+// for authentication, digest fetch, each attempt and retry waits, and request metrics
+func Middleware(client *gosip.SPClient, opts *Options) gosip.Middleware {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return func(next gosip.Doer) gosip.Doer {
+		return gosip.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			startedAt := time.Now()
+			pathTemplate := PathTemplate(req.URL.Path)
+
+			c := &call{
+				opts: opts,
+				attrs: []Attribute{
+					{Key: AttrSite, Value: client.AuthCnfg.GetSiteURL()},
+					{Key: AttrPathTemplate, Value: pathTemplate},
+					{Key: AttrMethod, Value: req.Method},
+					{Key: AttrServer, Value: req.URL.Host},
+				},
+			}
+
+			spanName := "SP " + req.Method + " " + pathTemplate
+			if strings.HasSuffix(strings.ToLower(req.URL.Path), "/_api/contextinfo") {
+				spanName = "gosip.digest"
+			}
+
+			ctx, span := c.startSpan(req.Context(), spanName, startedAt, append(c.attrs,
+				Attribute{Key: AttrURL, Value: req.URL.Scheme + "://" + req.URL.Host + req.URL.Path},
+				Attribute{Key: AttrStrategy, Value: client.AuthCnfg.GetStrategy()},
+			)...)
+			ctx = context.WithValue(ctx, callCtxKey{}, c)
+			ctx = gosip.WithHooks(ctx, c.hooks())
+			c.ctx = ctx
+
+			resp, err := next.Do(req.WithContext(ctx))
+
+			c.endAttempt(nil, nil) // attempt is not finished when a middleware down the chain has failed
+			attrs := c.attrs
+			if resp != nil {
+				attrs = append(attrs, Attribute{Key: AttrStatusCode, Value: resp.StatusCode})
+			}
+			if span != nil {
+				if resp != nil {
+					span.SetAttributes(Attribute{Key: AttrRequestGUID, Value: resp.Header.Get("SPRequestGuid")})
+				}
+				span.SetAttributes(attrs...)
+				if c.throttleWait > 0 {
+					span.SetAttributes(Attribute{Key: AttrThrottleWait, Value: c.throttleWait.Milliseconds()})
+				}
+				if err != nil {
+					span.RecordError(err)
+					span.SetAttributes(Attribute{Key: AttrErrorType, Value: errorType(resp, err)})
+				}
+				span.End()
+			}
+
+			c.count(MetricRequests, 1, attrs...)
+			c.record(MetricRequestDuration, time.Since(startedAt).Seconds(), attrs...)
+
+			return resp, err
+		})
+	}
+}
+
+// PathTemplate converts API path to a low cardinality template replacing literals with placeholders,
+// e.g. "/_api/Web/Lists/GetByTitle('Tasks')/Items(5)" becomes "/_api/Web/Lists/GetByTitle('{}')/Items({id})"
+func PathTemplate(path string) string {
+	path = quotedRegExp.ReplaceAllString(path, "'{}'")
+	path = guidRegExp.ReplaceAllString(path, "{guid}")
+	path = numericRegExp.ReplaceAllString(path, "({id})")
+	if i := strings.Index(strings.ToLower(path), "/_api/"); i > 0 {
+		path = path[i:]
+	}
+	return path
+}
+
+// hooks creates hook handlers reporting the call's inner events
+func (c *call) hooks() *gosip.HookHandlers {
+	return &gosip.HookHandlers{
+		OnAuth: func(e *gosip.HookEvent) {
+			if !c.owns(e) {
+				return
+			}
+			_, span := c.startSpan(c.ctx, "gosip.auth", e.StartedAt, c.attrs...)
+			if span != nil {
+				if e.Error != nil {
+					span.RecordError(e.Error)
+				}
+				span.End()
+			}
+		},
+		OnRequest: func(e *gosip.HookEvent) {
+			if !c.owns(e) {
+				return
+			}
+			c.attemptStart = time.Now()
+			_, c.attempt = c.startSpan(c.ctx, "gosip.attempt", c.attemptStart, append(c.attrs,
+				Attribute{Key: AttrResendCount, Value: e.Attempt},
+			)...)
+		},
+		OnAttempt: func(e *gosip.HookEvent) {
+			if !c.owns(e) {
+				return
+			}
+			c.endAttempt(e.Response, e.Error)
+		},
+		OnRetry: func(e *gosip.HookEvent) {
+			if !c.owns(e) {
+				return
+			}
+			c.throttleWait += e.Delay
+			attrs := append(c.attrs, Attribute{Key: AttrStatusCode, Value: e.StatusCode})
+			_, span := c.startSpan(c.ctx, "gosip.retry.wait", time.Now().Add(-e.Delay), append(attrs,
+				Attribute{Key: AttrRetryDelayMS, Value: e.Delay.Milliseconds()},
+				Attribute{Key: AttrResendCount, Value: e.Attempt},
+			)...)
+			if span != nil {
+				span.End()
+			}
+			c.count(MetricRetries, 1, attrs...)
+			c.record(MetricThrottleWait, e.Delay.Seconds(), attrs...)
+		},
+	}
+}
+
+// errorType gets error type attribute value: status code for error responses or "error"
+func errorType(resp *http.Response, err error) string {
+	if resp != nil && resp.StatusCode >= 400 {
+		return strconv.Itoa(resp.StatusCode)
+	}
+	if err == nil {
+		return ""
+	}
+	return "error"
+}
+
+// owns checks the event belongs to the call and not to a nested one, e.g. digest request
+func (c *call) owns(e *gosip.HookEvent) bool {
+	return e.Request.Context().Value(callCtxKey{}) == c
+}
+
+// endAttempt completes current attempt span
+func (c *call) endAttempt(resp *http.Response, err error) {
+	if c.attemptStart.IsZero() {
+		return
+	}
+	attrs := c.attrs
+	if resp != nil {
+		attrs = append(attrs, Attribute{Key: AttrStatusCode, Value: resp.StatusCode})
+		if resp.StatusCode == 429 {
+			c.count(MetricThrottled, 1, attrs...)
+		}
+	}
+	c.record(MetricAttemptDuration, time.Since(c.attemptStart).Seconds(), attrs...)
+	if c.attempt != nil {
+		c.attempt.SetAttributes(attrs...)
+		if resp != nil {
+			c.attempt.SetAttributes(Attribute{Key: AttrRequestGUID, Value: resp.Header.Get("SPRequestGuid")})
+		}
+		if err != nil {
+			c.attempt.RecordError(err)
+		}
+		c.attempt.End()
+	}
+	c.attempt = nil
+	c.attemptStart = time.Time{}
+}
+
+// startSpan starts a span when tracing is enabled
+func (c *call) startSpan(ctx context.Context, name string, startTime time.Time, attrs ...Attribute) (context.Context, Span) {
+	if c.opts.Tracer == nil {
+		return ctx, nil
+	}
+	return c.opts.Tracer.Start(ctx, name, startTime, attrs...)
+}
+
+// count adds to a counter when metrics are enabled
+func (c *call) count(name string, value int64, attrs ...Attribute) {
+	if c.opts.Meter == nil {
+		return
+	}
+	c.opts.Meter.Counter(name).Add(c.ctx, value, attrs...)
+}
+
+// record records to a histogram when metrics are enabled
+func (c *call) record(name string, value float64, attrs ...Attribute) {
+	if c.opts.Meter == nil {
+		return
+	}
+	c.opts.Meter.Histogram(name).Record(c.ctx, value, attrs...)
+}
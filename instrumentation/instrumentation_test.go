@@ -0,0 +1,192 @@
+package instrumentation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/recolabs/gosip"
+)
+
+type anonymousCnfg struct{ siteURL string }
+
+func (c *anonymousCnfg) ReadConfig(_ string) error                        { return nil }
+func (c *anonymousCnfg) ParseConfig(_ []byte) error                       { return nil }
+func (c *anonymousCnfg) WriteConfig(_ string) error                       { return nil }
+func (c *anonymousCnfg) GetAuth(_ context.Context) (string, int64, error) { return "", 0, nil }
+func (c *anonymousCnfg) GetSiteURL() string                               { return c.siteURL }
+func (c *anonymousCnfg) GetStrategy() string                              { return "anonymous" }
+func (c *anonymousCnfg) SetAuth(_ *http.Request, _ *gosip.SPClient) error {
+	return nil
+}
+
+type spanCtxKey struct{}
+
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+func (s *fakeSpan) RecordError(err error) { s.err = err }
+func (s *fakeSpan) End()                  { s.ended = true }
+
+type fakeTracer struct{ spans []*fakeSpan }
+
+func (t *fakeTracer) Start(ctx context.Context, name string, _ time.Time, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(spanCtxKey{}).(*fakeSpan)
+	span := &fakeSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanCtxKey{}, span), span
+}
+
+func (t *fakeTracer) find(name string, parent *fakeSpan) []*fakeSpan {
+	var spans []*fakeSpan
+	for _, s := range t.spans {
+		if s.name == name && s.parent == parent {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+type fakeMeter struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (m *fakeMeter) Counter(name string) Counter     { return &fakeInstrument{m, name} }
+func (m *fakeMeter) Histogram(name string) Histogram { return &fakeInstrument{m, name} }
+
+type fakeInstrument struct {
+	meter *fakeMeter
+	name  string
+}
+
+func (i *fakeInstrument) Add(_ context.Context, value int64, _ ...Attribute) {
+	i.Record(nil, float64(value))
+}
+
+func (i *fakeInstrument) Record(_ context.Context, value float64, _ ...Attribute) {
+	i.meter.mu.Lock()
+	defer i.meter.mu.Unlock()
+	i.meter.values[i.name] += value
+}
+
+func TestInstrument(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.URL.Path, "/sites/site/_api/contextinfo") {
+			_, _ = w.Write([]byte(`{"d":{"GetContextWebInformation":{"FormDigestValue":"digest","FormDigestTimeoutSeconds":1800}}}`))
+			return
+		}
+		calls++
+		w.Header().Set("SPRequestGuid", "guid")
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"d":{}}`))
+	}))
+	defer srv.Close()
+
+	tracer := &fakeTracer{}
+	meter := &fakeMeter{values: map[string]float64{}}
+	strategy := gosip.NewBackoffRetryStrategy(map[int]int{429: 1})
+	strategy.BaseDelay = time.Millisecond
+
+	client := &gosip.SPClient{
+		AuthCnfg:      &anonymousCnfg{siteURL: srv.URL + "/sites/site"},
+		RetryStrategy: strategy,
+	}
+	Instrument(client, &Options{Tracer: tracer, Meter: meter})
+
+	req, _ := http.NewRequest("POST", srv.URL+"/sites/site/_api/Web/Lists/GetByTitle('Tasks')/Items(5)", nil)
+	resp, err := client.Execute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	roots := tracer.find("SP POST /_api/Web/Lists/GetByTitle('{}')/Items({id})", nil)
+	if len(roots) != 1 {
+		t.Fatalf("expected one call span, got %d", len(roots))
+	}
+	root := roots[0]
+	if !root.ended || root.attrs[AttrStatusCode] != 200 || root.attrs[AttrRequestGUID] != "guid" {
+		t.Errorf("unexpected call span attributes: %v", root.attrs)
+	}
+	if root.attrs[AttrSite] != srv.URL+"/sites/site" {
+		t.Errorf("unexpected site attribute: %v", root.attrs[AttrSite])
+	}
+
+	attempts := tracer.find("gosip.attempt", root)
+	if len(attempts) != 2 {
+		t.Fatalf("expected two attempt spans, got %d", len(attempts))
+	}
+	if attempts[0].attrs[AttrStatusCode] != 429 || attempts[1].attrs[AttrResendCount] != 1 {
+		t.Errorf("unexpected attempt spans attributes: %v, %v", attempts[0].attrs, attempts[1].attrs)
+	}
+
+	digests := tracer.find("gosip.digest", root)
+	if len(digests) != 1 {
+		t.Fatal("digest span should be a child of the call span")
+	}
+	if len(tracer.find("gosip.attempt", digests[0])) != 1 {
+		t.Error("digest attempt span should be a child of the digest span")
+	}
+	for _, name := range []string{"gosip.auth", "gosip.attempt", "gosip.retry.wait"} {
+		if len(tracer.find(name, root)) == 0 {
+			t.Errorf("%s span should be a child of the call span", name)
+		}
+		for _, span := range tracer.find(name, root) {
+			if !span.ended {
+				t.Errorf("%s span is not ended", name)
+			}
+		}
+	}
+	if len(tracer.find("gosip.retry.wait", root)) != 1 {
+		t.Error("expected one retry wait span")
+	}
+
+	if meter.values[MetricRequests] != 2 { // the call and the digest
+		t.Errorf("expected 2 requests, got %v", meter.values[MetricRequests])
+	}
+	if meter.values[MetricRetries] != 1 {
+		t.Errorf("expected 1 retry, got %v", meter.values[MetricRetries])
+	}
+	if meter.values[MetricThrottled] != 1 {
+		t.Errorf("expected 1 throttled response, got %v", meter.values[MetricThrottled])
+	}
+	if meter.values[MetricThrottleWait] <= 0 {
+		t.Error("throttle wait is not recorded")
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	cases := map[string]string{
+		"/sites/site/_api/Web/Lists/GetByTitle('Tasks')/Items(5)":                                  "/_api/Web/Lists/GetByTitle('{}')/Items({id})",
+		"/_api/Web/Lists('0a1b2c3d-0000-1111-2222-333344445555')/Items":                            "/_api/Web/Lists('{}')/Items",
+		"/_api/Web/Lists(guid'0a1b2c3d-0000-1111-2222-333344445555')/Fields":                       "/_api/Web/Lists(guid'{}')/Fields",
+		"/_api/Web/GetFolderByServerRelativeUrl('/sites/site/Shared Documents')/Files":             "/_api/Web/GetFolderByServerRelativeUrl('{}')/Files",
+		"/_api/Web/SiteUsers/GetById(12)/Groups":                                                   "/_api/Web/SiteUsers/GetById({id})/Groups",
+		"/_api/SP.AppContextSite(@target)/Web/Lists/GetById(0a1b2c3d-0000-1111-2222-333344445555)": "/_api/SP.AppContextSite(@target)/Web/Lists/GetById({guid})",
+	}
+	for path, expected := range cases {
+		if tmpl := PathTemplate(path); tmpl != expected {
+			t.Errorf("incorrect template for %s: expected %s, got %s", path, expected, tmpl)
+		}
+	}
+}
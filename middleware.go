@@ -0,0 +1,40 @@
+package gosip
+
+import (
+	"net/http"
+)
+
+// Doer sends HTTP request and returns the response, SPClient.Execute is a Doer
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the next Doer in SPClient.Execute chain,
+// can change the request before calling the next one, the response after, or skip calling it at all
+type Middleware func(next Doer) Doer
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use adds middlewares to the client's Execute chain, the first added middleware is the outermost one.
+// Middlewares wrap the whole request flow including authentication, digest and retries.
+// Use is not safe for concurrent use with Execute, configure the client before sending requests.
+func (c *SPClient) Use(middlewares ...Middleware) *SPClient {
+	c.middlewares = append(c.middlewares, middlewares...)
+	return c
+}
+
+// chain builds Execute chain: custom middlewares, built-in hooks middleware and the request flow
+func (c *SPClient) chain() Doer {
+	var doer Doer = DoerFunc(c.execute)
+	doer = c.hooksMiddleware(doer)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		doer = c.middlewares[i](doer)
+	}
+	return doer
+}
@@ -0,0 +1,129 @@
+package gosip
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	siteURL := "http://localhost:8989"
+	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{ "tenant": "%s" }`, r.Header.Get("X-Tenant"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closer.Close() }()
+
+	t.Run("Order", func(t *testing.T) {
+		var calls []string
+		trace := func(name string) Middleware {
+			return func(next Doer) Doer {
+				return DoerFunc(func(req *http.Request) (*http.Response, error) {
+					calls = append(calls, name+":before")
+					resp, err := next.Do(req)
+					calls = append(calls, name+":after")
+					return resp, err
+				})
+			}
+		}
+
+		client := &SPClient{AuthCnfg: &AnonymousCnfg{SiteURL: siteURL}}
+		client.Use(trace("first"), trace("second"))
+
+		if err := simpleCall(client, "/_api/get", nil); err != nil {
+			t.Error(err)
+		}
+
+		expected := "first:before,second:before,second:after,first:after"
+		if strings.Join(calls, ",") != expected {
+			t.Errorf("incorrect middlewares order, expected %s, got %s", expected, strings.Join(calls, ","))
+		}
+	})
+
+	t.Run("ChangeRequestAndResponse", func(t *testing.T) {
+		client := &SPClient{AuthCnfg: &AnonymousCnfg{SiteURL: siteURL}}
+		client.Use(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Tenant", "contoso")
+				resp, err := next.Do(req)
+				if err == nil {
+					resp.Header.Set("X-Middleware", "true")
+				}
+				return resp, err
+			})
+		})
+
+		req, _ := http.NewRequest("GET", siteURL+"/_api/get", nil)
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		data, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(data), "contoso") {
+			t.Error("request is not changed by middleware")
+		}
+		if resp.Header.Get("X-Middleware") != "true" {
+			t.Error("response is not changed by middleware")
+		}
+	})
+
+	t.Run("ShortCircuit", func(t *testing.T) {
+		client := &SPClient{AuthCnfg: &AnonymousCnfg{SiteURL: "http://unreachable"}}
+		client.Use(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{ "cached": true }`)),
+					Request:    req,
+				}, nil
+			})
+		})
+
+		req, _ := http.NewRequest("GET", "http://unreachable/_api/get", nil)
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		data, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(data), "cached") {
+			t.Error("middleware should be able to skip the request")
+		}
+	})
+
+	t.Run("HooksAsMiddleware", func(t *testing.T) {
+		var requests, responses int
+		client := &SPClient{
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+			Hooks: &HookHandlers{
+				OnRequest: func(e *HookEvent) { requests++ },
+			},
+		}
+
+		ctx := WithHooks(context.Background(), &HookHandlers{
+			OnResponse: func(e *HookEvent) { responses++ },
+		})
+		req, _ := http.NewRequestWithContext(ctx, "GET", siteURL+"/_api/get", nil)
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if requests != 1 {
+			t.Errorf("client hooks should be called once, got %d", requests)
+		}
+		if responses != 1 {
+			t.Errorf("context hooks should be called once, got %d", responses)
+		}
+	})
+}
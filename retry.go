@@ -1,9 +1,13 @@
 package gosip
 
 import (
+	"context"
 	"math"
+	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,51 +20,225 @@ var retryPolicies = map[int]int{
 	504: 5,  // on 504 - Gateway Timeout Error
 }
 
-// getRetryPolicy receives retries policy retry number
-func (c *SPClient) getRetryPolicy(statusCode int) int {
-	// Return defaults when no custom
-	if c.RetryPolicies == nil {
-		return retryPolicies[statusCode]
+// RetryStrategy decides whether a failed request should be retried and how long to wait before the next attempt.
+// Implementations should be safe for concurrent use as a strategy is shared between requests.
+type RetryStrategy interface {
+	// ShouldRetry receives the request, the response (nil on transport errors), the transport error
+	// and the number of retries already made; returns true to retry the request after the delay
+	ShouldRetry(req *http.Request, resp *http.Response, err error, retry int) (bool, time.Duration)
+}
+
+// BackoffRetryStrategy is the default retry strategy: exponential backoff with jitter
+// which honors Retry-After header on throttled (429) and unavailable (503) responses.
+// Non-idempotent requests are only retried on the responses which guarantee the request has not been processed.
+type BackoffRetryStrategy struct {
+	Policies     map[int]int   // max retries per response status code, falls back to default retry policies
+	ErrorRetries int           // max retries on transport errors (no response received)
+	BaseDelay    time.Duration // first retry delay which is doubled with each next retry, 100ms when not set
+	MaxDelay     time.Duration // backoff delay cap, no cap when not set
+	Jitter       float64       // delay randomization factor (0..1) to prevent concurrent clients retrying in lockstep
+}
+
+// retryStrategyCtxKey context key for per-request retry strategy overrides
+type retryStrategyCtxKey struct{}
+
+// retryAttemptCtxKey context key for the request retry attempt number
+type retryAttemptCtxKey struct{}
+
+// noRetryStrategy never retries
+type noRetryStrategy struct{}
+
+// retryRand jitter randomizer, seeded per process for the clients not to share the sequence
+var retryRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// NewBackoffRetryStrategy creates default retry strategy with the custom retry policies, `policies` are optional
+func NewBackoffRetryStrategy(policies map[int]int) *BackoffRetryStrategy {
+	return &BackoffRetryStrategy{
+		Policies:  policies,
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  5 * time.Minute,
+		Jitter:    0.2,
 	}
-	// Check in custom
-	retries, ok := c.RetryPolicies[statusCode]
-	if !ok {
-		// Fallback to default
-		return retryPolicies[statusCode]
+}
+
+// WithRetryStrategy returns a copy of the context which overrides client's retry strategy for the requests
+func WithRetryStrategy(ctx context.Context, strategy RetryStrategy) context.Context {
+	return context.WithValue(ctx, retryStrategyCtxKey{}, strategy)
+}
+
+// WithNoRetry returns a copy of the context which disables retries for the requests
+func WithNoRetry(ctx context.Context) context.Context {
+	return WithRetryStrategy(ctx, noRetryStrategy{})
+}
+
+// RetryAttempt gets the request retry attempt number, 0 for the initial request
+func RetryAttempt(req *http.Request) int {
+	if req == nil {
+		return 0
 	}
-	return retries
+	attempt, _ := req.Context().Value(retryAttemptCtxKey{}).(int)
+	return attempt
 }
 
-// shouldRetry checks should the request be retried, used with specific resp.StatusCode's
-func (c *SPClient) shouldRetry(req *http.Request, resp *http.Response, retries int) bool {
+// withRetryAttempt returns a shallow copy of the request with the retry attempt number
+func withRetryAttempt(req *http.Request, attempt int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), retryAttemptCtxKey{}, attempt))
+}
+
+// applyNoRetryHeader maps deprecated X-Gosip-NoRetry header to WithNoRetry context override,
+// the header is removed not to be sent to the server
+func applyNoRetryHeader(req *http.Request) *http.Request {
 	noRetry := req.Header.Get("X-Gosip-NoRetry")
-	if noRetry == "true" {
-		return false
+	if noRetry == "" {
+		return req
 	}
-	retry, _ := strconv.Atoi(req.Header.Get("X-Gosip-Retry"))
-	if resp == nil { // no response, e.g. no such host
-		return false
+	req.Header.Del("X-Gosip-NoRetry")
+	if noRetry != "true" {
+		return req
 	}
-	if retry < retries {
-		if resp.Body != nil {
-			_ = resp.Body.Close() // closing to reuse request
+	return req.WithContext(WithNoRetry(req.Context()))
+}
+
+// ShouldRetry checks should the request be retried due to the policies, returns the delay before the retry
+func (s *BackoffRetryStrategy) ShouldRetry(req *http.Request, resp *http.Response, err error, retry int) (bool, time.Duration) {
+	if resp == nil { // no response, e.g. connection reset
+		if err == nil || retry >= s.ErrorRetries || !isIdempotent(req) {
+			return false, 0
 		}
-		retryAfter := 0
-		if resp.StatusCode == 429 { // sometimes SPO is abusing Retry-After header on 503 errors
-			retryAfter, _ = strconv.Atoi(resp.Header.Get("Retry-After"))
+		return true, s.backoff(retry)
+	}
+
+	if retry >= s.getRetryPolicy(resp.StatusCode) {
+		return false, 0
+	}
+	if !isIdempotent(req) && !isNotProcessed(resp.StatusCode) {
+		return false, 0
+	}
+
+	// sometimes SPO is abusing Retry-After header on 503 errors
+	if resp.StatusCode == 429 || resp.StatusCode == 503 {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			// jitter only prolongs Retry-After as the server asks to wait at least for that long
+			return true, retryAfter + time.Duration(s.Jitter*randFloat()*float64(retryAfter))
 		}
-		req.Header.Set("X-Gosip-Retry", strconv.Itoa(retry+1))
-		sleepTimeout := time.Duration(100*math.Pow(2, float64(retry))) * time.Millisecond // default, no Retry-After header
-		if retryAfter != 0 {
-			sleepTimeout = time.Duration(retryAfter) * time.Second // wait for Retry-After header info value
+	}
+
+	return true, s.backoff(retry)
+}
+
+// getRetryPolicy receives retries policy retry number
+func (s *BackoffRetryStrategy) getRetryPolicy(statusCode int) int {
+	// Check in custom
+	if retries, ok := s.Policies[statusCode]; ok {
+		return retries
+	}
+	// Fallback to default
+	return retryPolicies[statusCode]
+}
+
+// backoff calculates exponential delay with jitter for the retry number
+func (s *BackoffRetryStrategy) backoff(retry int) time.Duration {
+	baseDelay := s.BaseDelay
+	if baseDelay == 0 {
+		baseDelay = 100 * time.Millisecond
+	}
+	delay := float64(baseDelay) * math.Pow(2, float64(retry))
+	if s.MaxDelay > 0 && delay > float64(s.MaxDelay) {
+		delay = float64(s.MaxDelay)
+	}
+	if s.Jitter > 0 {
+		delay += delay * s.Jitter * (2*randFloat() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// ShouldRetry never retries
+func (noRetryStrategy) ShouldRetry(_ *http.Request, _ *http.Response, _ error, _ int) (bool, time.Duration) {
+	return false, 0
+}
+
+// getRetryStrategy resolves retry strategy for the request: context override, client's strategy or the default one
+func (c *SPClient) getRetryStrategy(req *http.Request) RetryStrategy {
+	if strategy, ok := req.Context().Value(retryStrategyCtxKey{}).(RetryStrategy); ok && strategy != nil {
+		return strategy
+	}
+	if c.RetryStrategy != nil {
+		return c.RetryStrategy
+	}
+	strategy := NewBackoffRetryStrategy(c.RetryPolicies)
+	// Retry transport errors only for NTLM
+	if c.AuthCnfg.GetStrategy() == "ntlm" {
+		strategy.ErrorRetries = 5
+	}
+	return strategy
+}
+
+// shouldRetry checks should the request be retried due to the retry strategy, returns the delay before the retry
+func (c *SPClient) shouldRetry(req *http.Request, resp *http.Response, err error) (bool, time.Duration) {
+	// Streaming bodies can't be sent again
+	if !canRewind(req) {
+		return false, 0
+	}
+	return c.getRetryStrategy(req).ShouldRetry(req, resp, err, RetryAttempt(req))
+}
+
+// waitRetry waits for the delay before the retry, returns false when the request context is canceled
+func (c *SPClient) waitRetry(req *http.Request, resp *http.Response, delay time.Duration) bool {
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close() // closing to reuse request
+	}
+	select {
+	case <-req.Context().Done():
+		return false // do not retry when context is canceled
+	case <-time.After(delay):
+		return true
+	}
+}
+
+// isIdempotent checks if the request can be safely replayed
+func isIdempotent(req *http.Request) bool {
+	switch strings.ToUpper(req.Header.Get("X-Http-Method")) {
+	case "MERGE", "PUT", "DELETE":
+		return true
+	}
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	// Context info requests only read the digest
+	return strings.HasSuffix(strings.ToLower(req.URL.Path), "/_api/contextinfo")
+}
+
+// isNotProcessed checks if the response status guarantees the request has not been processed by the server
+func isNotProcessed(statusCode int) bool {
+	return statusCode == 401 || statusCode == 429 || statusCode == 503
+}
+
+// parseRetryAfter parses Retry-After header value either in delay seconds or HTTP-date formats
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, false
 		}
-		// time.Sleep(sleepTimeout)
-		select {
-		case <-req.Context().Done():
-			return false // do not retry when context is canceled
-		case <-time.After(sleepTimeout):
-			return true
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
 		}
 	}
-	return false
+	return 0, false
+}
+
+// randFloat returns pseudo-random number in [0.0,1.0)
+func randFloat() float64 {
+	retryRand.Lock()
+	defer retryRand.Unlock()
+	return retryRand.Float64()
 }
@@ -7,33 +7,62 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+type countingRetryStrategy struct {
+	calls int32
+}
+
+func (s *countingRetryStrategy) ShouldRetry(_ *http.Request, resp *http.Response, _ error, retry int) (bool, time.Duration) {
+	atomic.AddInt32(&s.calls, 1)
+	return resp != nil && resp.StatusCode == 503 && retry < 2, 0
+}
+
 func TestRetry(t *testing.T) {
 	siteURL := "http://localhost:8989"
+	var createRequests, leakedHeaders int32
 	closer, err := startFakeServer(":8989", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// retry state should not be sent to the server
+		if r.Header.Get("X-Gosip-Retry") != "" || r.Header.Get("X-Gosip-NoRetry") != "" {
+			atomic.AddInt32(&leakedHeaders, 1)
+		}
 		// faking digest response
 		if r.RequestURI == "/_api/ContextInfo" {
 			_, _ = fmt.Fprintf(w, `{"d":{"GetContextWebInformation":{"FormDigestValue":"FAKE","FormDigestTimeoutSeconds":120,"LibraryVersion":"FAKE"}}}`)
 			return
 		}
 		// retry after
-		if r.RequestURI == "/_api/retryafter" && r.Header.Get("X-Gosip-Retry") == "1" {
+		if r.RequestURI == "/_api/retryafter" && r.Header.Get("X-Test-Attempt") == "1" {
 			w.Header().Add("Retry-After", "1")
 			w.WriteHeader(http.StatusTooManyRequests)
 			_, _ = w.Write([]byte(`{ "error": "Body is not backed off" }`))
 			return
 		}
+		// retry after in HTTP-date format
+		if r.RequestURI == "/_api/retryafterdate" && r.Header.Get("X-Test-Attempt") == "" {
+			w.Header().Add("Retry-After", time.Now().Add(2*time.Second).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{ "error": "503 Retry Please" }`))
+			return
+		}
+		// non-idempotent request failure
+		if r.RequestURI == "/_api/post/create" {
+			atomic.AddInt32(&createRequests, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{ "error": "500 Internal Server Error" }`))
+			return
+		}
 		// ntlm retry
-		if r.RequestURI == "/_api/ntlm" && r.Header.Get("X-Gosip-Retry") == "" {
+		if r.RequestURI == "/_api/ntlm" && r.Header.Get("X-Test-Attempt") == "" {
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte(`{ "error": "NTLM force retry" }`))
 			return
 		}
 		// context cancel
-		if r.RequestURI == "/_api/contextcancel" && r.Header.Get("X-Gosip-Retry") == "" {
+		if r.RequestURI == "/_api/contextcancel" && r.Header.Get("X-Test-Attempt") == "" {
 			w.Header().Add("Retry-After", "5")
 			w.WriteHeader(http.StatusTooManyRequests)
 			_, _ = w.Write([]byte(`{ "error": "context cancel" }`))
@@ -42,7 +71,7 @@ func TestRetry(t *testing.T) {
 		if r.Body != nil {
 			defer func() { _ = r.Body.Close() }()
 			data, _ := io.ReadAll(r.Body)
-			if r.RequestURI == "/_api/post/keepbody" && r.Header.Get("X-Gosip-Retry") == "1" {
+			if r.RequestURI == "/_api/post/keepbody" && r.Header.Get("X-Test-Attempt") == "1" {
 				if string(data) != "none-empty" {
 					w.WriteHeader(http.StatusInternalServerError)
 					_, _ = w.Write([]byte(`{ "error": "Body is not backed off" }`))
@@ -51,7 +80,7 @@ func TestRetry(t *testing.T) {
 			}
 		}
 		// backoff after 2 retries
-		if r.Header.Get("X-Gosip-Retry") == "2" {
+		if r.Header.Get("X-Test-Attempt") == "2" {
 			_, _ = fmt.Fprintf(w, `{ "result": "Cool alfter some retries" }`)
 			return
 		}
@@ -66,6 +95,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("GetRequest", func(t *testing.T) {
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 3},
 		}
@@ -84,10 +114,14 @@ func TestRetry(t *testing.T) {
 		if resp.StatusCode != 200 {
 			t.Error("can't retry a request")
 		}
+		if atomic.LoadInt32(&leakedHeaders) != 0 {
+			t.Error("retry attempt should not be sent in headers")
+		}
 	})
 
 	t.Run("PostRequest", func(t *testing.T) {
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 3},
 		}
@@ -110,6 +144,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("PostRequestEmptyBody", func(t *testing.T) {
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 3},
 		}
@@ -132,6 +167,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("PostRequestShould503", func(t *testing.T) {
 		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
 			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
 			RetryPolicies: map[int]int{503: 1},
 		}
@@ -151,6 +187,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("DisableRetry", func(t *testing.T) {
 		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
 			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
 		}
 
@@ -167,10 +204,14 @@ func TestRetry(t *testing.T) {
 		if resp.StatusCode != 503 {
 			t.Error("should receive 503")
 		}
+		if atomic.LoadInt32(&leakedHeaders) != 0 {
+			t.Error("deprecated X-Gosip-NoRetry header should not be sent")
+		}
 	})
 
 	t.Run("RetryAfter", func(t *testing.T) {
 		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
 			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
 		}
 
@@ -192,6 +233,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("NtlmRetry", func(t *testing.T) {
 		client := &SPClient{
+			Client: http.Client{Transport: attemptTransport{}},
 			AuthCnfg: &AnonymousCnfg{
 				SiteURL:  siteURL,
 				Strategy: "ntlm",
@@ -210,6 +252,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("ContextCancel", func(t *testing.T) {
 		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
 			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
 		}
 
@@ -240,6 +283,7 @@ func TestRetry(t *testing.T) {
 
 	t.Run("ContextCancel", func(t *testing.T) {
 		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
 			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
 		}
 
@@ -258,4 +302,183 @@ func TestRetry(t *testing.T) {
 			t.Error("context canceling failed")
 		}
 	})
+	t.Run("RetryAfterDate", func(t *testing.T) {
+		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+		}
+
+		req, err := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/retryafterdate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		beforeReq := time.Now()
+		if _, err := client.Execute(req); err != nil {
+			t.Error(err)
+		}
+
+		dur := time.Since(beforeReq)
+		if dur < 1*time.Second {
+			t.Error("retry after date is ignored")
+		}
+	})
+
+	t.Run("NonIdempotentShouldNotRetry", func(t *testing.T) {
+		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
+			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
+			RetryPolicies: map[int]int{500: 3},
+		}
+
+		req, err := http.NewRequest("POST", client.AuthCnfg.GetSiteURL()+"/_api/post/create", bytes.NewBuffer([]byte("none-empty")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, _ := client.Execute(req)
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != 500 {
+			t.Error("should receive 500")
+		}
+		if atomic.LoadInt32(&createRequests) != 1 {
+			t.Errorf("non-idempotent request should not be retried, got %d requests", createRequests)
+		}
+	})
+
+	t.Run("CustomStrategy", func(t *testing.T) {
+		strategy := &countingRetryStrategy{}
+		client := &SPClient{
+			Client:        http.Client{Transport: attemptTransport{}},
+			AuthCnfg:      &AnonymousCnfg{SiteURL: siteURL},
+			RetryStrategy: strategy,
+		}
+
+		req, err := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/get", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Error(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != 200 {
+			t.Error("can't retry a request")
+		}
+		if atomic.LoadInt32(&strategy.calls) != 3 {
+			t.Errorf("strategy should be called 3 times, got %d", strategy.calls)
+		}
+	})
+
+	t.Run("ContextNoRetry", func(t *testing.T) {
+		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+		}
+
+		req, err := http.NewRequestWithContext(WithNoRetry(context.Background()), "GET", client.AuthCnfg.GetSiteURL()+"/_api/get", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, _ := client.Execute(req)
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != 503 {
+			t.Error("should receive 503")
+		}
+	})
+
+	t.Run("ContextStrategy", func(t *testing.T) {
+		strategy := &countingRetryStrategy{}
+		client := &SPClient{
+			Client:   http.Client{Transport: attemptTransport{}},
+			AuthCnfg: &AnonymousCnfg{SiteURL: siteURL},
+		}
+
+		ctx := WithRetryStrategy(context.Background(), strategy)
+		req, err := http.NewRequestWithContext(ctx, "GET", client.AuthCnfg.GetSiteURL()+"/_api/get", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Execute(req)
+		if err != nil {
+			t.Error(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if atomic.LoadInt32(&strategy.calls) != 3 {
+			t.Errorf("context strategy should be called 3 times, got %d", strategy.calls)
+		}
+	})
+}
+
+func TestBackoffRetryStrategy(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost/_api/web", nil)
+
+	t.Run("Backoff", func(t *testing.T) {
+		strategy := &BackoffRetryStrategy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+		resp := &http.Response{StatusCode: 503, Header: http.Header{}}
+		expected := []time.Duration{100, 200, 300, 300}
+		for retry, exp := range expected {
+			_, delay := strategy.ShouldRetry(req, resp, nil, retry)
+			if delay != exp*time.Millisecond {
+				t.Errorf("incorrect delay for retry %d, expected %s, got %s", retry, exp*time.Millisecond, delay)
+			}
+		}
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		strategy := NewBackoffRetryStrategy(nil)
+		resp := &http.Response{StatusCode: 503, Header: http.Header{}}
+		for i := 0; i < 100; i++ {
+			_, delay := strategy.ShouldRetry(req, resp, nil, 1)
+			if delay < 160*time.Millisecond || delay > 240*time.Millisecond {
+				t.Errorf("delay is out of jitter range: %s", delay)
+			}
+		}
+	})
+
+	t.Run("RetryAfterOn503", func(t *testing.T) {
+		strategy := &BackoffRetryStrategy{}
+		resp := &http.Response{StatusCode: 503, Header: http.Header{"Retry-After": []string{"7"}}}
+		if retry, delay := strategy.ShouldRetry(req, resp, nil, 0); !retry || delay != 7*time.Second {
+			t.Errorf("retry after is ignored, got %s", delay)
+		}
+	})
+
+	t.Run("Policies", func(t *testing.T) {
+		strategy := &BackoffRetryStrategy{Policies: map[int]int{404: 1}}
+		resp := &http.Response{StatusCode: 404, Header: http.Header{}}
+		if retry, _ := strategy.ShouldRetry(req, resp, nil, 0); !retry {
+			t.Error("custom policy is ignored")
+		}
+		if retry, _ := strategy.ShouldRetry(req, resp, nil, 1); retry {
+			t.Error("custom policy retries limit is ignored")
+		}
+		resp.StatusCode = 429
+		if retry, _ := strategy.ShouldRetry(req, resp, nil, 0); !retry {
+			t.Error("default policy is ignored")
+		}
+	})
+
+	t.Run("TransportErrors", func(t *testing.T) {
+		strategy := &BackoffRetryStrategy{ErrorRetries: 1}
+		if retry, _ := strategy.ShouldRetry(req, nil, fmt.Errorf("connection reset"), 0); !retry {
+			t.Error("transport error should be retried")
+		}
+		post, _ := http.NewRequest("POST", "http://localhost/_api/web/lists", nil)
+		if retry, _ := strategy.ShouldRetry(post, nil, fmt.Errorf("connection reset"), 0); retry {
+			t.Error("non-idempotent request should not be retried on transport error")
+		}
+		post.Header.Set("X-Http-Method", "MERGE")
+		if retry, _ := strategy.ShouldRetry(post, nil, fmt.Errorf("connection reset"), 0); !retry {
+			t.Error("MERGE request should be retried on transport error")
+		}
+	})
 }
@@ -0,0 +1,90 @@
+package sptest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Redacted is a placeholder for redacted secret values
+const Redacted = "[REDACTED]"
+
+// DefaultRedactHeaders headers which values are redacted in cassettes by default
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-RequestDigest",
+	"X-FORMS_BASED_AUTH_ACCEPTED",
+	"WWW-Authenticate",
+}
+
+// digestRegExp matches form digest values in context info and CSOM responses
+var digestRegExp = regexp.MustCompile(`("FormDigestValue"\s*:\s*)"[^"]*"`)
+
+// Cassette is a set of recorded request/response interactions
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Request is a recorded HTTP request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads cassette from the file
+func LoadCassette(cassettePath string) (*Cassette, error) {
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, err
+	}
+	return cassette, nil
+}
+
+// Save writes cassette to the file creating missing folders
+func (c *Cassette) Save(cassettePath string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cassettePath), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(cassettePath, data, 0644)
+}
+
+// redact replaces secret values of the interaction with Redacted placeholder
+func redact(i *Interaction, headers []string) {
+	for _, h := range headers {
+		if i.Request.Header.Get(h) != "" {
+			i.Request.Header.Set(h, Redacted)
+		}
+		if i.Response.Header.Get(h) != "" {
+			i.Response.Header.Set(h, Redacted)
+		}
+	}
+	i.Response.Body = digestRegExp.ReplaceAllString(i.Response.Body, `$1"`+Redacted+`"`)
+}
@@ -0,0 +1,479 @@
+package sptest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/auth/anon"
+)
+
+// ServerSitePath is the fake server's site server relative URL
+const ServerSitePath = "/sites/test"
+
+// Server is an in-memory fake SharePoint REST API server for unit tests.
+// It emulates the core REST surface used by the api package: context info, web and lists,
+// list items with OData modifiers, CAML queries and RenderListDataAsStream,
+// folders and files including chunked upload and versions, item versions, sharing and recycle bin.
+// Always use NewServer constructor instead of &Server{}
+type Server struct {
+	*httptest.Server
+	SiteURL string // fake site absolute URL
+
+	mu         sync.Mutex
+	digest     string
+	web        *fakeWeb
+	lists      []*fakeList
+	folders    map[string]*fakeFolder // by lower-cased server relative URL
+	files      map[string]*fakeFile   // by lower-cased server relative URL
+	recycleBin []*recycleEntry
+	uploads    map[string]*bytesUpload
+	sharing    map[string]*fakeSharing // by list item unique ID
+}
+
+type fakeWeb struct {
+	id          string
+	title       string
+	description string
+	created     time.Time
+}
+
+type fakeList struct {
+	id           string
+	title        string
+	description  string
+	baseTemplate int
+	entityType   string
+	rootFolder   string
+	created      time.Time
+	modified     time.Time
+	items        []map[string]interface{}
+	versions     map[int][]map[string]interface{} // item ID to previous item versions
+	nextID       int
+}
+
+type fakeFolder struct {
+	id       string
+	url      string
+	created  time.Time
+	modified time.Time
+}
+
+type fakeFile struct {
+	id       string
+	url      string
+	content  []byte
+	version  int
+	versions []*fakeFileVersion
+	created  time.Time
+	modified time.Time
+}
+
+type fakeFileVersion struct {
+	id      int
+	label   string
+	content []byte
+	created time.Time
+}
+
+type bytesUpload struct {
+	file    *fakeFile
+	content []byte
+}
+
+// NewServer starts a fake SharePoint server, the site is available at SiteURL,
+// the site has a "Documents" library in "Shared Documents" folder
+func NewServer() *Server {
+	s := &Server{
+		digest:  uuid.New().String(),
+		folders: map[string]*fakeFolder{},
+		files:   map[string]*fakeFile{},
+		uploads: map[string]*bytesUpload{},
+		sharing: map[string]*fakeSharing{},
+		web: &fakeWeb{
+			id:      uuid.New().String(),
+			title:   "Test",
+			created: time.Now().UTC(),
+		},
+	}
+	s.Server = httptest.NewServer(s)
+	s.SiteURL = s.URL + ServerSitePath
+	s.addFolder(ServerSitePath)
+	_, _ = s.addList("Documents", 101, "Shared Documents")
+	return s
+}
+
+// Client creates SPClient for the fake site with anonymous auth strategy
+func (s *Server) Client() *gosip.SPClient {
+	return &gosip.SPClient{AuthCnfg: &anon.AuthCnfg{SiteURL: s.SiteURL}}
+}
+
+// AddList creates a list with the title, baseTemplate is 100 for a generic list, 101 for a document library
+func (s *Server) AddList(title string, baseTemplate int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.addList(title, baseTemplate, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddItem adds an item with the fields to the list and returns its ID
+func (s *Server) AddItem(listTitle string, fields map[string]interface{}) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.getListByTitle(listTitle)
+	if list == nil {
+		return 0, fmt.Errorf("list '%s' does not exist", listTitle)
+	}
+	return list.addItem(fields)["Id"].(int), nil
+}
+
+// AddFile adds a file by its server relative URL creating missing folders
+func (s *Server) AddFile(serverRelativeURL string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	serverRelativeURL = s.absPath(serverRelativeURL)
+	s.ensureFolder(path.Dir(serverRelativeURL))
+	s.addFile(serverRelativeURL, content)
+}
+
+// ServeHTTP handles SharePoint REST API requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &response{w: w, r: r, siteURL: s.SiteURL, mode: getODataMode(r)}
+
+	prefix := ServerSitePath + "/_api/"
+	if !strings.HasPrefix(strings.ToLower(r.URL.Path), strings.ToLower(prefix)) {
+		res.error(404, "-1, Microsoft.SharePoint.Client.ResourceNotFoundException", "Cannot find resource for the request "+r.URL.Path)
+		return
+	}
+
+	method := r.Method
+	if m := r.Header.Get("X-Http-Method"); m != "" && method == "POST" {
+		method = strings.ToUpper(m)
+	}
+
+	segments := splitSegments(r.URL.Path[len(prefix):])
+	if len(segments) == 1 && strings.EqualFold(segments[0].name, "contextinfo") {
+		s.contextInfo(res)
+		return
+	}
+
+	if method != "GET" && r.Header.Get("X-RequestDigest") != s.digest {
+		res.error(403, "-2130575251, Microsoft.SharePoint.SPException",
+			"The security validation for this page is invalid and might be corrupted. Please use your web browser's Back button to try your operation again.")
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	t, err := s.resolve(segments)
+	if err != nil {
+		err.write(res)
+		return
+	}
+	if err := s.handle(res, t, method, body); err != nil {
+		err.write(res)
+	}
+}
+
+// contextInfo responds with context info including the form digest
+func (s *Server) contextInfo(res *response) {
+	info := map[string]interface{}{
+		"FormDigestTimeoutSeconds": 1800,
+		"FormDigestValue":          s.digest,
+		"LibraryVersion":           "16.0.0.0",
+		"SiteFullUrl":              s.SiteURL,
+		"WebFullUrl":               s.SiteURL,
+		"SupportedSchemaVersions":  []string{"14.0.0.0", "15.0.0.0"},
+	}
+	if res.mode == modeVerbose {
+		info["SupportedSchemaVersions"] = map[string]interface{}{"results": info["SupportedSchemaVersions"]}
+		res.json(200, map[string]interface{}{"d": map[string]interface{}{"GetContextWebInformation": info}})
+		return
+	}
+	res.json(200, info)
+}
+
+// absPath converts web relative URL to server relative
+func (s *Server) absPath(u string) string {
+	if !strings.HasPrefix(u, "/") {
+		u = ServerSitePath + "/" + u
+	}
+	return strings.TrimRight(u, "/")
+}
+
+// addList creates a list with its root folder
+func (s *Server) addList(title string, baseTemplate int, uri string) (*fakeList, *serverError) {
+	if s.getListByTitle(title) != nil {
+		return nil, &serverError{500, "-2130575342, Microsoft.SharePoint.SPException",
+			"A list, survey, discussion board, or document library with the specified title already exists in this Web site.  Please choose another title."}
+	}
+	if uri == "" {
+		uri = strings.ReplaceAll(title, " ", "")
+	}
+	entityType := strings.ReplaceAll(uri, " ", "_x0020_")
+	rootFolder := ServerSitePath + "/" + uri
+	if baseTemplate != 101 {
+		entityType += "List"
+		rootFolder = ServerSitePath + "/Lists/" + uri
+	}
+	now := time.Now().UTC()
+	list := &fakeList{
+		id:           uuid.New().String(),
+		title:        title,
+		baseTemplate: baseTemplate,
+		entityType:   entityType,
+		rootFolder:   rootFolder,
+		created:      now,
+		modified:     now,
+		nextID:       1,
+	}
+	s.lists = append(s.lists, list)
+	s.ensureFolder(rootFolder)
+	return list, nil
+}
+
+// getListByTitle gets list by its title ignoring case
+func (s *Server) getListByTitle(title string) *fakeList {
+	for _, l := range s.lists {
+		if strings.EqualFold(l.title, title) {
+			return l
+		}
+	}
+	return nil
+}
+
+// getListByURL gets list containing the server relative URL
+func (s *Server) getListByURL(u string) *fakeList {
+	u = strings.ToLower(u)
+	for _, l := range s.lists {
+		root := strings.ToLower(l.rootFolder)
+		if u == root || strings.HasPrefix(u, root+"/") {
+			return l
+		}
+	}
+	return nil
+}
+
+// addItem adds an item to the list
+func (l *fakeList) addItem(fields map[string]interface{}) map[string]interface{} {
+	now := time.Now().UTC().Format(time.RFC3339)
+	item := map[string]interface{}{
+		"Title":                nil,
+		"ContentTypeId":        "0x0100" + strings.ToUpper(strings.ReplaceAll(l.id, "-", "")),
+		"Attachments":          false,
+		"AuthorId":             1,
+		"EditorId":             1,
+		"Created":              now,
+		"Modified":             now,
+		"GUID":                 uuid.New().String(),
+		"FileSystemObjectType": 0,
+		"owshiddenversion":     1,
+	}
+	for k, v := range fields {
+		item[k] = v
+	}
+	item["Id"] = l.nextID
+	item["ID"] = l.nextID
+	l.nextID++
+	l.items = append(l.items, item)
+	l.modified = time.Now().UTC()
+	return item
+}
+
+// getItem gets list item by ID
+func (l *fakeList) getItem(id int) map[string]interface{} {
+	for _, item := range l.items {
+		if item["Id"] == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// addItemVersion keeps a copy of the item fields as a previous version
+func (l *fakeList) addItemVersion(item map[string]interface{}) {
+	version := map[string]interface{}{}
+	for k, v := range item {
+		version[k] = v
+	}
+	if l.versions == nil {
+		l.versions = map[int][]map[string]interface{}{}
+	}
+	id := item["Id"].(int)
+	l.versions[id] = append(l.versions[id], version)
+}
+
+// itemVersions gets item versions, the current version goes first
+func (l *fakeList) itemVersions(item map[string]interface{}) []map[string]interface{} {
+	history := l.versions[item["Id"].(int)]
+	versions := []map[string]interface{}{item}
+	for i := len(history) - 1; i >= 0; i-- {
+		versions = append(versions, history[i])
+	}
+	return versions
+}
+
+// getItemVersion gets item version by version ID
+func (l *fakeList) getItemVersion(item map[string]interface{}, versionID int) map[string]interface{} {
+	for _, v := range l.itemVersions(item) {
+		if v["owshiddenversion"].(int)*512 == versionID {
+			return v
+		}
+	}
+	return nil
+}
+
+// removeItem removes list item by ID
+func (l *fakeList) removeItem(id int) map[string]interface{} {
+	for i, item := range l.items {
+		if item["Id"] == id {
+			l.items = append(l.items[:i], l.items[i+1:]...)
+			return item
+		}
+	}
+	return nil
+}
+
+// restoreItem puts removed item back keeping items sorted by ID
+func (l *fakeList) restoreItem(item map[string]interface{}) {
+	l.items = append(l.items, item)
+	sort.SliceStable(l.items, func(i, j int) bool {
+		return l.items[i]["Id"].(int) < l.items[j]["Id"].(int)
+	})
+}
+
+// addFolder creates a folder, library folders get list items
+func (s *Server) addFolder(u string) *fakeFolder {
+	now := time.Now().UTC()
+	folder := &fakeFolder{id: uuid.New().String(), url: u, created: now, modified: now}
+	s.folders[strings.ToLower(u)] = folder
+	if list := s.getListByURL(u); list != nil && !strings.EqualFold(list.rootFolder, u) {
+		list.addItem(fileItemFields(u, 1))
+	}
+	return folder
+}
+
+// ensureFolder creates the folder and its missing parents
+func (s *Server) ensureFolder(u string) {
+	if s.folders[strings.ToLower(u)] != nil || len(u) <= len(ServerSitePath) {
+		return
+	}
+	s.ensureFolder(path.Dir(u))
+	s.addFolder(u)
+}
+
+// addFile creates or overwrites a file, library files get list items
+func (s *Server) addFile(u string, content []byte) *fakeFile {
+	now := time.Now().UTC()
+	if file, ok := s.files[strings.ToLower(u)]; ok {
+		file.versions = append(file.versions, &fakeFileVersion{
+			id:      file.version * 512,
+			label:   fmt.Sprintf("%d.0", file.version),
+			content: file.content,
+			created: file.modified,
+		})
+		file.content = content
+		file.version++
+		file.modified = now
+		return file
+	}
+	file := &fakeFile{id: uuid.New().String(), url: u, content: content, version: 1, created: now, modified: now}
+	s.files[strings.ToLower(u)] = file
+	if list := s.getListByURL(u); list != nil {
+		list.addItem(fileItemFields(u, 0))
+	}
+	return file
+}
+
+// getVersion gets file version by version ID
+func (f *fakeFile) getVersion(id int) *fakeFileVersion {
+	for _, v := range f.versions {
+		if v.id == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// getVersionByLabel gets file version by version label
+func (f *fakeFile) getVersionByLabel(label string) *fakeFileVersion {
+	for _, v := range f.versions {
+		if v.label == label {
+			return v
+		}
+	}
+	return nil
+}
+
+// removeVersion removes file version, returns false when the version is not found
+func (f *fakeFile) removeVersion(version *fakeFileVersion) bool {
+	for i, v := range f.versions {
+		if v == version {
+			f.versions = append(f.versions[:i], f.versions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fileItemFields list item fields of a file or a folder
+func fileItemFields(u string, fsObjType int) map[string]interface{} {
+	return map[string]interface{}{
+		"FileRef":              u,
+		"FileDirRef":           path.Dir(u),
+		"FileLeafRef":          path.Base(u),
+		"FSObjType":            fsObjType,
+		"FileSystemObjectType": fsObjType,
+	}
+}
+
+// getFileItem gets list item of a file or a folder
+func (s *Server) getFileItem(u string) (*fakeList, map[string]interface{}) {
+	list := s.getListByURL(u)
+	if list == nil {
+		return nil, nil
+	}
+	for _, item := range list.items {
+		if ref, ok := item["FileRef"].(string); ok && strings.EqualFold(ref, u) {
+			return list, item
+		}
+	}
+	return list, nil
+}
+
+// childFolders gets direct subfolders of the folder
+func (s *Server) childFolders(u string) []*fakeFolder {
+	var folders []*fakeFolder
+	for _, f := range s.folders {
+		if strings.EqualFold(path.Dir(f.url), u) && !strings.EqualFold(f.url, u) {
+			folders = append(folders, f)
+		}
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].url < folders[j].url })
+	return folders
+}
+
+// childFiles gets files of the folder
+func (s *Server) childFiles(u string) []*fakeFile {
+	var files []*fakeFile
+	for _, f := range s.files {
+		if strings.EqualFold(path.Dir(f.url), u) {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].url < files[j].url })
+	return files
+}
@@ -0,0 +1,1082 @@
+package sptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// segment is REST API URL path segment, e.g. GetByTitle('Tasks')
+type segment struct {
+	name string
+	args string
+}
+
+// target is resolved REST API object
+type target struct {
+	kind    string // web, lists, list, items, item, folder, folders, files, file, value, fileversions, fileversion, versionvalue, itemversions, itemversion, recyclebin, recycleitem, action
+	list    *fakeList
+	item    map[string]interface{}
+	folder  *fakeFolder
+	file    *fakeFile
+	version *fakeFileVersion
+	entry   *recycleEntry
+	action  string   // method name for action targets
+	args    []string // method arguments
+	named   map[string]string
+	parent  *target // the object the action is called on
+}
+
+// splitSegments splits REST API path to segments ignoring slashes within method arguments
+func splitSegments(p string) []segment {
+	var segments []segment
+	depth, quoted, start := 0, false, 0
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		seg := segment{name: s}
+		if i := strings.Index(s, "("); i != -1 && strings.HasSuffix(s, ")") {
+			seg.name, seg.args = s[:i], s[i+1:len(s)-1]
+		}
+		segments = append(segments, seg)
+	}
+	for i, r := range p {
+		switch {
+		case r == '\'' && depth > 0:
+			quoted = !quoted
+		case r == '(' && !quoted:
+			depth++
+		case r == ')' && !quoted:
+			depth--
+		case r == '/' && depth == 0:
+			add(p[start:i])
+			start = i + 1
+		}
+	}
+	add(p[start:])
+	return segments
+}
+
+// parseArgs parses method arguments to positional and named values, e.g. (overwrite=true,url='a.txt')
+func parseArgs(args string) ([]string, map[string]string) {
+	var positional []string
+	named := map[string]string{}
+	var parts []string
+	quoted, start := false, 0
+	for i, r := range args {
+		if r == '\'' {
+			quoted = !quoted
+		}
+		if r == ',' && !quoted {
+			parts = append(parts, args[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, args[start:])
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if i := strings.Index(part, "="); i != -1 && (strings.Index(part, "'") == -1 || i < strings.Index(part, "'")) {
+			named[strings.ToLower(part[:i])] = unquote(part[i+1:])
+			continue
+		}
+		positional = append(positional, unquote(part))
+	}
+	return positional, named
+}
+
+// unquote removes literal quotes and type prefix, e.g. guid'...'
+func unquote(value string) string {
+	if i := strings.Index(value, "'"); i != -1 && strings.HasSuffix(value, "'") && len(value) > i+1 {
+		return strings.ReplaceAll(value[i+1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// Errors emulating SharePoint ones
+
+func errNotFound(message string) *serverError {
+	return &serverError{404, "-1, Microsoft.SharePoint.Client.ResourceNotFoundException", message}
+}
+
+func errFileNotFound() *serverError {
+	return &serverError{404, "-2147024894, System.IO.FileNotFoundException", "File Not Found."}
+}
+
+func errListNotFound(name string) *serverError {
+	return &serverError{404, "-1, System.ArgumentException", fmt.Sprintf("List '%s' does not exist at site with URL '%s'.", name, ServerSitePath)}
+}
+
+func errItemNotFound() *serverError {
+	return &serverError{404, "-2147024809, System.ArgumentException", "Item does not exist. It may have been deleted by another user."}
+}
+
+func errBadRequest(message string) *serverError {
+	return &serverError{400, "-1, Microsoft.SharePoint.Client.InvalidClientQueryException", message}
+}
+
+func errMethod(method string) *serverError {
+	return &serverError{405, "-1, Microsoft.SharePoint.Client.ClientServiceException", fmt.Sprintf("The HTTP method '%s' cannot be used to access the resource.", method)}
+}
+
+// resolve walks the path segments to the target object
+func (s *Server) resolve(segments []segment) (*target, *serverError) {
+	t := &target{kind: "root"}
+	for _, seg := range segments {
+		name := strings.ToLower(seg.name)
+		args, named := parseArgs(seg.args)
+		arg := ""
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		next := &target{}
+
+		switch t.kind + "/" + name {
+		case "root/web":
+			next.kind = "web"
+		case "web/lists":
+			next.kind = "lists"
+			if seg.args != "" {
+				next.kind, next.list = "list", s.getListByID(arg)
+				if next.list == nil {
+					return nil, errListNotFound(arg)
+				}
+			}
+		case "lists/getbytitle":
+			next.kind, next.list = "list", s.getListByTitle(arg)
+			if next.list == nil {
+				return nil, errListNotFound(arg)
+			}
+		case "lists/getbyid":
+			next.kind, next.list = "list", s.getListByID(arg)
+			if next.list == nil {
+				return nil, errListNotFound(arg)
+			}
+		case "web/getlist":
+			u := s.absPath(arg)
+			next.kind, next.list = "list", s.getListByURL(u)
+			if next.list == nil || !strings.EqualFold(next.list.rootFolder, u) {
+				return nil, errFileNotFound()
+			}
+		case "list/items", "list/getitembyid", "items/getbyid":
+			next.kind, next.list = "items", t.list
+			if seg.args != "" {
+				id, _ := strconv.Atoi(arg)
+				next.kind, next.item = "item", t.list.getItem(id)
+				if next.item == nil {
+					return nil, errItemNotFound()
+				}
+			}
+		case "web/rootfolder":
+			next.kind, next.folder = "folder", s.folders[strings.ToLower(ServerSitePath)]
+		case "list/rootfolder":
+			next.kind, next.folder = "folder", s.folders[strings.ToLower(t.list.rootFolder)]
+		case "web/getfolderbyserverrelativeurl", "web/getfolderbyserverrelativepath":
+			if v, ok := named["decodedurl"]; ok {
+				arg = v
+			}
+			next.kind, next.folder = "folder", s.folders[strings.ToLower(s.absPath(arg))]
+		case "web/getfolderbyid":
+			next.kind, next.folder = "folder", s.getFolderByID(arg)
+		case "web/getfilebyserverrelativeurl", "web/getfilebyserverrelativepath":
+			if v, ok := named["decodedurl"]; ok {
+				arg = v
+			}
+			next.kind, next.file = "file", s.files[strings.ToLower(s.absPath(arg))]
+		case "web/getfilebyid":
+			next.kind, next.file = "file", s.getFileByID(arg)
+		case "web/folders":
+			next.kind, next.folder = "folders", s.folders[strings.ToLower(ServerSitePath)]
+			if seg.args != "" {
+				next.kind, next.folder = "folder", s.folders[strings.ToLower(ServerSitePath+"/"+arg)]
+			}
+		case "folder/folders":
+			next.kind, next.folder = "folders", t.folder
+			if seg.args != "" {
+				next.kind, next.folder = "folder", s.folders[strings.ToLower(t.folder.url+"/"+arg)]
+			}
+		case "folder/files":
+			next.kind, next.folder = "files", t.folder
+			if seg.args != "" {
+				next.kind, next.file = "file", s.files[strings.ToLower(t.folder.url+"/"+arg)]
+			}
+		case "folder/parentfolder":
+			next.kind, next.folder = "folder", s.folders[strings.ToLower(path.Dir(t.folder.url))]
+		case "folder/listitemallfields":
+			next.list, next.item = s.getFileItem(t.folder.url)
+			next.kind = "item"
+			if next.item == nil {
+				return nil, errItemNotFound()
+			}
+		case "file/listitemallfields":
+			next.list, next.item = s.getFileItem(t.file.url)
+			next.kind = "item"
+			if next.item == nil {
+				return nil, errItemNotFound()
+			}
+		case "file/$value":
+			next.kind, next.file = "value", t.file
+		case "file/versions":
+			next.kind, next.file = "fileversions", t.file
+			if seg.args != "" {
+				id, _ := strconv.Atoi(arg)
+				next.kind, next.version = "fileversion", t.file.getVersion(id)
+				if next.version == nil {
+					return nil, errNotFound(fmt.Sprintf("Version %s is not found.", arg))
+				}
+			}
+		case "fileversion/$value":
+			next.kind, next.file, next.version = "versionvalue", t.file, t.version
+		case "item/versions":
+			next.kind, next.list, next.item = "itemversions", t.list, t.item
+			if seg.args != "" {
+				id, _ := strconv.Atoi(arg)
+				next.kind, next.item = "itemversion", t.list.getItemVersion(t.item, id)
+				if next.item == nil {
+					return nil, errNotFound(fmt.Sprintf("Version %s is not found.", arg))
+				}
+			}
+		case "web/recyclebin":
+			next.kind = "recyclebin"
+			if seg.args != "" {
+				next.kind, next.entry = "recycleitem", s.getRecycleEntry(arg)
+				if next.entry == nil {
+					return nil, errNotFound(fmt.Sprintf("Recycle bin item '%s' is not found.", arg))
+				}
+			}
+		case "list/getitems", "list/renderlistdataasstream", "list/recycle", "item/recycle",
+			"folder/recycle", "folders/add", "files/add", "file/recycle",
+			"file/startupload", "file/continueupload", "file/finishupload", "file/cancelupload",
+			"fileversions/deletebyid", "fileversions/deletebylabel", "fileversions/deleteall", "fileversions/restorebylabel",
+			"item/sharelink", "item/getsharinginformation", "item/unsharelink", "item/deletelinkbykind",
+			"root/sp.web.shareobject", "root/sp.web.unshareobject",
+			"recycleitem/restore", "recycleitem/deleteobject":
+			next = &target{kind: "action", action: name, args: args, named: named, parent: t}
+		default:
+			return nil, errNotFound(fmt.Sprintf("Cannot find resource for the request %s.", seg.name))
+		}
+
+		if (next.kind == "folder" || next.kind == "folders" || next.kind == "files") && next.folder == nil {
+			return nil, errFileNotFound()
+		}
+		if (next.kind == "file" || next.kind == "value") && next.file == nil {
+			return nil, errFileNotFound()
+		}
+		t = next
+	}
+	if t.kind == "root" {
+		return nil, errNotFound("Cannot find resource for the request.")
+	}
+	return t, nil
+}
+
+// handle executes the method on the target
+func (s *Server) handle(res *response, t *target, method string, body []byte) *serverError {
+	switch t.kind + ":" + method {
+	case "web:GET":
+		res.entity(200, s.webEntity())
+	case "web:MERGE":
+		props, err := parseBody(body)
+		if err != nil {
+			return err
+		}
+		if v, ok := props["Title"].(string); ok {
+			s.web.title = v
+		}
+		if v, ok := props["Description"].(string); ok {
+			s.web.description = v
+		}
+		res.empty(204)
+
+	case "lists:GET":
+		var entities []*entity
+		for _, l := range s.lists {
+			entities = append(entities, s.listEntity(l))
+		}
+		return s.writeCollection(res, entities, 0)
+	case "lists:POST":
+		props, err := parseBody(body)
+		if err != nil {
+			return err
+		}
+		title, _ := props["Title"].(string)
+		if title == "" {
+			return errBadRequest("The list title is required.")
+		}
+		template := 100
+		if v, ok := props["BaseTemplate"].(float64); ok {
+			template = int(v)
+		}
+		list, err := s.addList(title, template, "")
+		if err != nil {
+			return err
+		}
+		if v, ok := props["Description"].(string); ok {
+			list.description = v
+		}
+		res.entity(201, s.listEntity(list))
+
+	case "list:GET":
+		res.entity(200, s.listEntity(t.list))
+	case "list:MERGE":
+		props, err := parseBody(body)
+		if err != nil {
+			return err
+		}
+		if v, ok := props["Title"].(string); ok {
+			t.list.title = v
+		}
+		if v, ok := props["Description"].(string); ok {
+			t.list.description = v
+		}
+		res.empty(204)
+	case "list:DELETE":
+		s.removeList(t.list)
+		res.empty(200)
+
+	case "items:GET":
+		var entities []*entity
+		for _, item := range t.list.items {
+			entities = append(entities, s.itemEntity(t.list, item))
+		}
+		return s.writeCollection(res, entities, 100)
+	case "items:POST":
+		props, err := s.parseItemBody(t.list, body)
+		if err != nil {
+			return err
+		}
+		res.entity(201, s.itemEntity(t.list, t.list.addItem(props)))
+
+	case "item:GET":
+		res.entity(200, s.itemEntity(t.list, t.item))
+	case "item:MERGE":
+		props, err := s.parseItemBody(t.list, body)
+		if err != nil {
+			return err
+		}
+		t.list.addItemVersion(t.item)
+		for k, v := range props {
+			if k != "Id" && k != "ID" {
+				t.item[k] = v
+			}
+		}
+		t.item["Modified"] = time.Now().UTC().Format(time.RFC3339)
+		t.item["owshiddenversion"] = t.item["owshiddenversion"].(int) + 1
+		t.list.modified = time.Now().UTC()
+		res.empty(204)
+	case "item:DELETE":
+		s.removeItem(t.list, t.item)
+		res.empty(200)
+
+	case "folder:GET":
+		res.entity(200, s.folderEntity(t.folder))
+	case "folder:MERGE":
+		res.empty(204)
+	case "folder:DELETE":
+		s.removeFolder(t.folder)
+		res.empty(200)
+	case "folders:GET":
+		var entities []*entity
+		for _, f := range s.childFolders(t.folder.url) {
+			entities = append(entities, s.folderEntity(f))
+		}
+		return s.writeCollection(res, entities, 0)
+	case "files:GET":
+		var entities []*entity
+		for _, f := range s.childFiles(t.folder.url) {
+			entities = append(entities, s.fileEntity(f))
+		}
+		return s.writeCollection(res, entities, 0)
+
+	case "file:GET":
+		res.entity(200, s.fileEntity(t.file))
+	case "file:DELETE":
+		s.removeFile(t.file)
+		res.empty(200)
+	case "value:GET":
+		res.w.Header().Set("Content-Type", "application/octet-stream")
+		res.w.WriteHeader(200)
+		_, _ = res.w.Write(t.file.content)
+	case "value:PUT":
+		s.addFile(t.file.url, body)
+		res.empty(204)
+
+	case "fileversions:GET":
+		var entities []*entity
+		for _, v := range t.file.versions {
+			entities = append(entities, s.fileVersionEntity(t.file, v))
+		}
+		return s.writeCollection(res, entities, 0)
+	case "fileversion:GET":
+		res.entity(200, s.fileVersionEntity(t.file, t.version))
+	case "versionvalue:GET":
+		res.w.Header().Set("Content-Type", "application/octet-stream")
+		res.w.WriteHeader(200)
+		_, _ = res.w.Write(t.version.content)
+
+	case "itemversions:GET":
+		var entities []*entity
+		for _, v := range t.list.itemVersions(t.item) {
+			entities = append(entities, s.itemVersionEntity(t.list, v))
+		}
+		return s.writeCollection(res, entities, 0)
+	case "itemversion:GET":
+		res.entity(200, s.itemVersionEntity(t.list, t.item))
+
+	case "recyclebin:GET":
+		var entities []*entity
+		for _, e := range s.recycleBin {
+			entities = append(entities, s.recycleEntity(e))
+		}
+		return s.writeCollection(res, entities, 0)
+	case "recycleitem:GET":
+		res.entity(200, s.recycleEntity(t.entry))
+
+	default:
+		if t.kind == "action" && method == "POST" {
+			return s.action(res, t, body)
+		}
+		return errMethod(method)
+	}
+	return nil
+}
+
+// action executes REST API method
+func (s *Server) action(res *response, t *target, body []byte) *serverError {
+	p := t.parent
+	switch p.kind + "/" + t.action {
+	case "list/getitems":
+		r := &struct {
+			Query struct {
+				ViewXML string `json:"ViewXml"`
+			} `json:"query"`
+		}{}
+		if err := json.Unmarshal(body, r); err != nil {
+			return errBadRequest(err.Error())
+		}
+		viewXML := r.Query.ViewXML
+		if viewXML == "" {
+			viewXML = "<View/>"
+		}
+		query, err := parseCAML(viewXML)
+		if err != nil {
+			return &serverError{400, "-2147024809, System.ArgumentException", err.Error()}
+		}
+		var entities []*entity
+		for _, item := range query.apply(p.list.items) {
+			e := s.itemEntity(p.list, item)
+			if len(query.viewFields) > 0 {
+				props := map[string]interface{}{"Id": item["Id"], "ID": item["ID"]}
+				for _, f := range query.viewFields {
+					if v, ok := item[f]; ok {
+						props[f] = v
+					}
+				}
+				e.props = props
+			}
+			entities = append(entities, e)
+		}
+		return s.writeCollection(res, entities, 0)
+
+	case "list/renderlistdataasstream":
+		return s.renderListDataAsStream(res, p.list, body)
+
+	case "list/recycle":
+		res.value("Recycle", s.recycle(p.list.title, "", p.list.rootFolder, 4, 0, s.removeList(p.list)))
+	case "item/recycle":
+		title, _ := p.item["Title"].(string)
+		if ref, ok := p.item["FileRef"].(string); ok {
+			title = path.Base(ref)
+		}
+		res.value("Recycle", s.recycle(title, fmt.Sprintf("%v_.000", p.item["Id"]), p.list.rootFolder, 3, 0, s.removeItem(p.list, p.item)))
+	case "folder/recycle":
+		res.value("Recycle", s.recycle(path.Base(p.folder.url), path.Base(p.folder.url), path.Dir(p.folder.url), 5, 0, s.removeFolder(p.folder)))
+	case "file/recycle":
+		res.value("Recycle", s.recycle(path.Base(p.file.url), path.Base(p.file.url), path.Dir(p.file.url), 1, len(p.file.content), s.removeFile(p.file)))
+
+	case "folders/add":
+		name := ""
+		if len(t.args) > 0 {
+			name = t.args[0]
+		}
+		if name == "" {
+			return errBadRequest("The folder name is required.")
+		}
+		u := p.folder.url + "/" + name
+		folder := s.folders[strings.ToLower(u)]
+		if folder == nil {
+			folder = s.addFolder(u)
+		}
+		res.entity(200, s.folderEntity(folder))
+
+	case "files/add":
+		name := t.named["url"]
+		if name == "" {
+			return errBadRequest("The file name is required.")
+		}
+		u := p.folder.url + "/" + name
+		if s.files[strings.ToLower(u)] != nil && !strings.EqualFold(t.named["overwrite"], "true") {
+			return &serverError{400, "-2130575257, Microsoft.SharePoint.SPException", fmt.Sprintf("A file with the name %s already exists.", strings.TrimPrefix(u, "/"))}
+		}
+		res.entity(200, s.fileEntity(s.addFile(u, body)))
+
+	case "file/startupload":
+		uploadID := t.named["uploadid"]
+		s.uploads[uploadID] = &bytesUpload{file: p.file, content: body}
+		res.value("StartUpload", strconv.Itoa(len(body)))
+	case "file/continueupload", "file/finishupload":
+		upload := s.uploads[t.named["uploadid"]]
+		if upload == nil || upload.file != p.file {
+			return errBadRequest("The upload session is not found.")
+		}
+		if offset, _ := strconv.Atoi(t.named["fileoffset"]); offset != len(upload.content) {
+			return errBadRequest(fmt.Sprintf("The file offset %d does not match the uploaded size %d.", offset, len(upload.content)))
+		}
+		upload.content = append(upload.content, body...)
+		if t.action == "continueupload" {
+			res.value("ContinueUpload", strconv.Itoa(len(upload.content)))
+			return nil
+		}
+		delete(s.uploads, t.named["uploadid"])
+		res.entity(200, s.fileEntity(s.addFile(p.file.url, upload.content)))
+	case "file/cancelupload":
+		delete(s.uploads, t.named["uploadid"])
+		res.empty(204)
+
+	case "fileversions/deletebyid":
+		id, _ := strconv.Atoi(t.named["vid"])
+		if !p.file.removeVersion(p.file.getVersion(id)) {
+			return errNotFound(fmt.Sprintf("Version %s is not found.", t.named["vid"]))
+		}
+		res.empty(200)
+	case "fileversions/deletebylabel":
+		if !p.file.removeVersion(p.file.getVersionByLabel(t.named["versionlabel"])) {
+			return errNotFound(fmt.Sprintf("Version %s is not found.", t.named["versionlabel"]))
+		}
+		res.empty(200)
+	case "fileversions/deleteall":
+		p.file.versions = nil
+		res.empty(200)
+	case "fileversions/restorebylabel":
+		version := p.file.getVersionByLabel(t.named["versionlabel"])
+		if version == nil {
+			return errNotFound(fmt.Sprintf("Version %s is not found.", t.named["versionlabel"]))
+		}
+		s.addFile(p.file.url, version.content)
+		res.empty(200)
+
+	case "item/sharelink":
+		return s.shareLink(res, p.list, p.item, body)
+	case "item/getsharinginformation":
+		return s.getSharingInformation(res, p.list, p.item)
+	case "item/unsharelink":
+		return s.unshareLink(res, p.item, body)
+	case "item/deletelinkbykind":
+		return s.deleteLinkByKind(res, p.item, body)
+	case "root/sp.web.shareobject":
+		return s.shareObject(res, body, false)
+	case "root/sp.web.unshareobject":
+		return s.shareObject(res, body, true)
+
+	case "recycleitem/restore":
+		s.restore(p.entry)
+		res.empty(200)
+	case "recycleitem/deleteobject":
+		s.dropRecycleEntry(p.entry)
+		res.empty(200)
+
+	default:
+		return errNotFound(fmt.Sprintf("Method %s is not supported.", t.action))
+	}
+	return nil
+}
+
+// writeCollection applies OData modifiers and writes the collection
+func (s *Server) writeCollection(res *response, entities []*entity, pageSize int) *serverError {
+	entities, nextURL, err := res.query(entities, pageSize)
+	if err != nil {
+		return err
+	}
+	res.collection(entities, nextURL)
+	return nil
+}
+
+// parseBody parses JSON payload
+func parseBody(body []byte) (map[string]interface{}, *serverError) {
+	props := map[string]interface{}{}
+	if len(body) == 0 {
+		return props, nil
+	}
+	if err := json.Unmarshal(body, &props); err != nil {
+		return nil, errBadRequest("Invalid JSON. " + err.Error())
+	}
+	return props, nil
+}
+
+// parseItemBody parses item payload validating its entity type
+func (s *Server) parseItemBody(list *fakeList, body []byte) (map[string]interface{}, *serverError) {
+	props, err := parseBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if metadata, ok := props["__metadata"].(map[string]interface{}); ok {
+		if typ, _ := metadata["type"].(string); typ != "" && typ != "SP.Data."+list.entityType+"Item" {
+			return nil, &serverError{400, "-1, Microsoft.SharePoint.Client.InvalidClientQueryException",
+				fmt.Sprintf("A type named '%s' could not be resolved by the model. When a model is available, each type name must resolve to a valid type.", typ)}
+		}
+	}
+	delete(props, "__metadata")
+	return props, nil
+}
+
+// Lookups by IDs
+
+func (s *Server) getListByID(id string) *fakeList {
+	for _, l := range s.lists {
+		if strings.EqualFold(l.id, id) {
+			return l
+		}
+	}
+	return nil
+}
+
+func (s *Server) getFolderByID(id string) *fakeFolder {
+	for _, f := range s.folders {
+		if strings.EqualFold(f.id, id) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) getFileByID(id string) *fakeFile {
+	for _, f := range s.files {
+		if strings.EqualFold(f.id, id) {
+			return f
+		}
+	}
+	return nil
+}
+
+// Entities
+
+const dateFormat = "2006-01-02T15:04:05Z"
+
+func (s *Server) webEntity() *entity {
+	return &entity{
+		typ: "SP.Web",
+		uri: s.SiteURL + "/_api/Web",
+		props: map[string]interface{}{
+			"Id":                s.web.id,
+			"Title":             s.web.title,
+			"Description":       s.web.description,
+			"Url":               s.SiteURL,
+			"ServerRelativeUrl": ServerSitePath,
+			"Created":           s.web.created.Format(dateFormat),
+			"Language":          1033,
+			"WebTemplate":       "STS",
+		},
+	}
+}
+
+func (s *Server) listEntity(l *fakeList) *entity {
+	baseType := 0
+	if l.baseTemplate == 101 {
+		baseType = 1
+	}
+	return &entity{
+		typ: "SP.List",
+		uri: fmt.Sprintf("%s/_api/Web/Lists(guid'%s')", s.SiteURL, l.id),
+		props: map[string]interface{}{
+			"Id":                         l.id,
+			"Title":                      l.title,
+			"Description":                l.description,
+			"BaseTemplate":               l.baseTemplate,
+			"BaseType":                   baseType,
+			"Created":                    l.created.Format(dateFormat),
+			"LastItemModifiedDate":       l.modified.Format(dateFormat),
+			"EntityTypeName":             l.entityType,
+			"ListItemEntityTypeFullName": "SP.Data." + l.entityType + "Item",
+			"ItemCount":                  len(l.items),
+			"Hidden":                     false,
+			"EnableFolderCreation":       l.baseTemplate == 101,
+			"ParentWebUrl":               ServerSitePath,
+		},
+	}
+}
+
+// renderListDataAsStream renders list items as rows with formatted values, supports ViewXml CAML queries,
+// paging with `p_ID` tokens passed in NextHref and ListSchema render option
+func (s *Server) renderListDataAsStream(res *response, l *fakeList, body []byte) *serverError {
+	r := &struct {
+		Parameters struct {
+			RenderOptions int    `json:"RenderOptions"`
+			ViewXML       string `json:"ViewXml"`
+		} `json:"parameters"`
+	}{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errBadRequest(err.Error())
+	}
+	viewXML := r.Parameters.ViewXML
+	if viewXML == "" {
+		viewXML = "<View/>"
+	}
+	query, err := parseCAML(viewXML)
+	if err != nil {
+		return &serverError{400, "-2147024809, System.ArgumentException", err.Error()}
+	}
+	rowLimit := query.rowLimit
+	if rowLimit == 0 {
+		rowLimit = 30
+	}
+	query.rowLimit = 0
+	items := query.apply(l.items)
+
+	// Paging token refers to the last item of the previous page
+	start := 0
+	if lastID := res.r.URL.Query().Get("p_ID"); lastID != "" {
+		for i, item := range items {
+			if fmt.Sprint(item["Id"]) == lastID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := start + rowLimit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	fieldNames := query.viewFields
+	if len(fieldNames) == 0 && len(items) > 0 {
+		for k := range items[0] {
+			fieldNames = append(fieldNames, k)
+		}
+		sort.Strings(fieldNames)
+	}
+
+	rows := []map[string]interface{}{}
+	for _, item := range items[start:end] {
+		row := map[string]interface{}{"ID": fmt.Sprint(item["Id"])}
+		for _, f := range fieldNames {
+			row[f] = formatRowValue(item[f])
+		}
+		rows = append(rows, row)
+	}
+	listData := map[string]interface{}{
+		"Row":      rows,
+		"FirstRow": start + 1,
+		"LastRow":  end,
+		"RowLimit": rowLimit,
+	}
+	if end < len(items) {
+		listData["NextHref"] = fmt.Sprintf("?Paged=TRUE&p_ID=%v&PageFirstRow=%d", items[end-1]["Id"], end+1)
+	}
+
+	if r.Parameters.RenderOptions&4 == 0 { // ListSchema
+		res.json(200, listData)
+		return nil
+	}
+	var fields []map[string]interface{}
+	for _, f := range fieldNames {
+		fieldType := "Text"
+		if len(items) > 0 {
+			switch items[0][f].(type) {
+			case int, float64:
+				fieldType = "Number"
+			case bool:
+				fieldType = "Boolean"
+			}
+		}
+		fields = append(fields, map[string]interface{}{
+			"Name": f, "RealFieldName": f, "DisplayName": f, "FieldType": fieldType, "Type": fieldType,
+		})
+	}
+	res.json(200, map[string]interface{}{
+		"ListData":   listData,
+		"ListSchema": map[string]interface{}{"Field": fields},
+	})
+	return nil
+}
+
+// formatRowValue formats item property the way RenderListDataAsStream rows do, complex values are kept
+func formatRowValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if val {
+			return "Yes"
+		}
+		return "No"
+	case int, float64, string:
+		return fmt.Sprint(val)
+	}
+	return v
+}
+
+func (s *Server) itemEntity(l *fakeList, item map[string]interface{}) *entity {
+	props := map[string]interface{}{}
+	for k, v := range item {
+		props[k] = v
+	}
+	props["EncodedAbsUrl"] = s.itemAbsURL(l, item)
+	return &entity{
+		typ:   "SP.Data." + l.entityType + "Item",
+		uri:   fmt.Sprintf("%s/_api/Web/Lists(guid'%s')/Items(%d)", s.SiteURL, l.id, item["Id"]),
+		etag:  fmt.Sprintf(`"%d"`, item["owshiddenversion"]),
+		props: props,
+	}
+}
+
+func (s *Server) folderEntity(f *fakeFolder) *entity {
+	return &entity{
+		typ: "SP.Folder",
+		uri: fmt.Sprintf("%s/_api/Web/GetFolderByServerRelativePath(decodedurl='%s')", s.SiteURL, f.url),
+		props: map[string]interface{}{
+			"Exists":            true,
+			"IsWOPIEnabled":     false,
+			"ItemCount":         len(s.childFolders(f.url)) + len(s.childFiles(f.url)),
+			"Name":              path.Base(f.url),
+			"ProgID":            nil,
+			"ServerRelativeUrl": f.url,
+			"TimeCreated":       f.created.Format(dateFormat),
+			"TimeLastModified":  f.modified.Format(dateFormat),
+			"UniqueId":          f.id,
+			"WelcomePage":       "",
+		},
+	}
+}
+
+func (s *Server) fileEntity(f *fakeFile) *entity {
+	return &entity{
+		typ:  "SP.File",
+		uri:  fmt.Sprintf("%s/_api/Web/GetFileByServerRelativePath(decodedurl='%s')", s.SiteURL, f.url),
+		etag: fmt.Sprintf(`"{%s},%d"`, strings.ToUpper(f.id), f.version),
+		props: map[string]interface{}{
+			"CheckInComment":    "",
+			"CheckOutType":      2,
+			"ETag":              fmt.Sprintf(`"{%s},%d"`, strings.ToUpper(f.id), f.version),
+			"Exists":            true,
+			"Length":            strconv.Itoa(len(f.content)),
+			"Level":             1,
+			"MajorVersion":      f.version,
+			"MinorVersion":      0,
+			"Name":              path.Base(f.url),
+			"ServerRelativeUrl": f.url,
+			"TimeCreated":       f.created.Format(dateFormat),
+			"TimeLastModified":  f.modified.Format(dateFormat),
+			"Title":             nil,
+			"UIVersion":         f.version * 512,
+			"UIVersionLabel":    fmt.Sprintf("%d.0", f.version),
+			"UniqueId":          f.id,
+		},
+	}
+}
+
+func (s *Server) fileVersionEntity(f *fakeFile, v *fakeFileVersion) *entity {
+	return &entity{
+		typ: "SP.FileVersion",
+		uri: fmt.Sprintf("%s/_api/Web/GetFileByServerRelativePath(decodedurl='%s')/Versions(%d)", s.SiteURL, f.url, v.id),
+		props: map[string]interface{}{
+			"CheckInComment":   "",
+			"Created":          v.created.Format(dateFormat),
+			"ID":               v.id,
+			"IsCurrentVersion": false,
+			"Length":           strconv.Itoa(len(v.content)),
+			"Size":             len(v.content),
+			"Url":              fmt.Sprintf("_vti_history/%d%s", v.id, strings.TrimPrefix(f.url, ServerSitePath)),
+			"VersionLabel":     v.label,
+		},
+	}
+}
+
+func (s *Server) itemVersionEntity(l *fakeList, v map[string]interface{}) *entity {
+	props := map[string]interface{}{}
+	for k, val := range v {
+		props[k] = val
+	}
+	current := l.getItem(v["Id"].(int))
+	props["VersionId"] = v["owshiddenversion"].(int) * 512
+	props["VersionLabel"] = fmt.Sprintf("%d.0", v["owshiddenversion"])
+	props["IsCurrentVersion"] = current != nil && current["owshiddenversion"] == v["owshiddenversion"]
+	props["Created"] = v["Modified"]
+	return &entity{
+		typ:   "SP.ListItemVersion",
+		uri:   fmt.Sprintf("%s/_api/Web/Lists(guid'%s')/Items(%d)/Versions(%d)", s.SiteURL, l.id, v["Id"], props["VersionId"]),
+		props: props,
+	}
+}
+
+func (s *Server) recycleEntity(e *recycleEntry) *entity {
+	return &entity{
+		typ: "SP.RecycleBinItem",
+		uri: fmt.Sprintf("%s/_api/Web/RecycleBin('%s')", s.SiteURL, e.id),
+		props: map[string]interface{}{
+			"Id":          e.id,
+			"Title":       e.title,
+			"LeafName":    e.leafName,
+			"DirName":     strings.TrimPrefix(e.dirName, "/"),
+			"ItemType":    e.itemType,
+			"ItemState":   1,
+			"Size":        e.size,
+			"DeletedDate": e.deleted.Format(dateFormat),
+		},
+	}
+}
+
+// Removal and recycle bin
+
+// recycleEntry is a recycle bin item keeping removed objects for restoring
+type recycleEntry struct {
+	id       string
+	title    string
+	leafName string
+	dirName  string
+	itemType int
+	size     int
+	deleted  time.Time
+	removed  *removal
+}
+
+// removal objects removed in a single operation
+type removal struct {
+	lists   []*fakeList
+	folders []*fakeFolder
+	files   []*fakeFile
+	items   []removedItem
+}
+
+type removedItem struct {
+	list *fakeList
+	item map[string]interface{}
+}
+
+// removeList removes the list with its folders and files
+func (s *Server) removeList(l *fakeList) *removal {
+	r := &removal{lists: []*fakeList{l}}
+	for i, list := range s.lists {
+		if list == l {
+			s.lists = append(s.lists[:i], s.lists[i+1:]...)
+			break
+		}
+	}
+	s.removeTree(l.rootFolder, r, false)
+	return r
+}
+
+// removeItem removes the item, file and folder items are removed along with the file or the folder
+func (s *Server) removeItem(l *fakeList, item map[string]interface{}) *removal {
+	r := &removal{}
+	if ref, ok := item["FileRef"].(string); ok {
+		s.removeTree(ref, r, true)
+	}
+	if l.removeItem(item["Id"].(int)) != nil {
+		r.items = append(r.items, removedItem{l, item})
+	}
+	return r
+}
+
+// removeFolder removes the folder with its content
+func (s *Server) removeFolder(f *fakeFolder) *removal {
+	r := &removal{}
+	s.removeTree(f.url, r, true)
+	return r
+}
+
+// removeFile removes the file with its list item
+func (s *Server) removeFile(f *fakeFile) *removal {
+	r := &removal{}
+	s.removeTree(f.url, r, true)
+	return r
+}
+
+// removeTree removes a file or a folder with its content, optionally with the list items
+func (s *Server) removeTree(u string, r *removal, withItems bool) {
+	prefix := strings.ToLower(u) + "/"
+	for key, f := range s.files {
+		if key == strings.ToLower(u) || strings.HasPrefix(key, prefix) {
+			r.files = append(r.files, f)
+			delete(s.files, key)
+		}
+	}
+	for key, f := range s.folders {
+		if key == strings.ToLower(u) || strings.HasPrefix(key, prefix) {
+			r.folders = append(r.folders, f)
+			delete(s.folders, key)
+		}
+	}
+	if !withItems {
+		return
+	}
+	if l := s.getListByURL(u); l != nil {
+		for _, item := range append([]map[string]interface{}{}, l.items...) {
+			ref, _ := item["FileRef"].(string)
+			key := strings.ToLower(ref)
+			if ref != "" && (key == strings.ToLower(u) || strings.HasPrefix(key, prefix)) {
+				l.removeItem(item["Id"].(int))
+				r.items = append(r.items, removedItem{l, item})
+			}
+		}
+	}
+}
+
+// recycle puts removed objects to the recycle bin and returns the recycle bin item ID
+func (s *Server) recycle(title string, leafName string, dirName string, itemType int, size int, r *removal) string {
+	e := &recycleEntry{
+		id:       uuid.New().String(),
+		title:    title,
+		leafName: leafName,
+		dirName:  dirName,
+		itemType: itemType,
+		size:     size,
+		deleted:  time.Now().UTC(),
+		removed:  r,
+	}
+	s.recycleBin = append(s.recycleBin, e)
+	return e.id
+}
+
+// restore restores recycled objects and removes the recycle bin item
+func (s *Server) restore(e *recycleEntry) {
+	s.lists = append(s.lists, e.removed.lists...)
+	for _, f := range e.removed.folders {
+		s.folders[strings.ToLower(f.url)] = f
+	}
+	for _, f := range e.removed.files {
+		s.files[strings.ToLower(f.url)] = f
+	}
+	for _, i := range e.removed.items {
+		i.list.restoreItem(i.item)
+	}
+	s.dropRecycleEntry(e)
+}
+
+// getRecycleEntry gets recycle bin item by ID
+func (s *Server) getRecycleEntry(id string) *recycleEntry {
+	for _, e := range s.recycleBin {
+		if strings.EqualFold(e.id, id) {
+			return e
+		}
+	}
+	return nil
+}
+
+// dropRecycleEntry removes item from the recycle bin
+func (s *Server) dropRecycleEntry(e *recycleEntry) {
+	for i, entry := range s.recycleBin {
+		if entry == e {
+			s.recycleBin = append(s.recycleBin[:i], s.recycleBin[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package sptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// camlNode is a generic CAML XML element
+type camlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []camlNode `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr gets attribute value ignoring case
+func (n *camlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child gets first child element by name ignoring case
+func (n *camlNode) child(name string) *camlNode {
+	for i := range n.Nodes {
+		if strings.EqualFold(n.Nodes[i].XMLName.Local, name) {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// camlQuery is a parsed CAML view query
+type camlQuery struct {
+	where      func(item map[string]interface{}) bool
+	orderBy    []camlOrder
+	viewFields []string
+	rowLimit   int
+}
+
+type camlOrder struct {
+	field string
+	desc  bool
+}
+
+// parseCAML parses CAML view XML basics: Where with comparison operators, And/Or, In, IsNull, IsNotNull,
+// Contains and BeginsWith; OrderBy; ViewFields and RowLimit
+func parseCAML(viewXML string) (*camlQuery, error) {
+	root := &camlNode{}
+	if err := xml.Unmarshal([]byte(viewXML), root); err != nil {
+		return nil, err
+	}
+	// Query element can be used without View wrapper
+	query := root
+	if strings.EqualFold(root.XMLName.Local, "View") {
+		query = root.child("Query")
+	}
+
+	q := &camlQuery{where: func(map[string]interface{}) bool { return true }}
+	if query != nil {
+		if where := query.child("Where"); where != nil && len(where.Nodes) > 0 {
+			cond, err := parseCAMLCondition(&where.Nodes[0])
+			if err != nil {
+				return nil, err
+			}
+			q.where = cond
+		}
+		if orderBy := query.child("OrderBy"); orderBy != nil {
+			for _, f := range orderBy.Nodes {
+				q.orderBy = append(q.orderBy, camlOrder{
+					field: f.attr("Name"),
+					desc:  strings.EqualFold(f.attr("Ascending"), "FALSE"),
+				})
+			}
+		}
+	}
+	if root != query {
+		if viewFields := root.child("ViewFields"); viewFields != nil {
+			for _, f := range viewFields.Nodes {
+				q.viewFields = append(q.viewFields, f.attr("Name"))
+			}
+		}
+		if rowLimit := root.child("RowLimit"); rowLimit != nil {
+			q.rowLimit, _ = strconv.Atoi(strings.TrimSpace(rowLimit.Text))
+		}
+	}
+	return q, nil
+}
+
+// parseCAMLCondition compiles CAML Where condition
+func parseCAMLCondition(n *camlNode) (func(item map[string]interface{}) bool, error) {
+	op := strings.ToLower(n.XMLName.Local)
+
+	if op == "and" || op == "or" {
+		var conds []func(item map[string]interface{}) bool
+		for i := range n.Nodes {
+			cond, err := parseCAMLCondition(&n.Nodes[i])
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, cond)
+		}
+		return func(item map[string]interface{}) bool {
+			for _, cond := range conds {
+				if cond(item) == (op == "or") {
+					return op == "or"
+				}
+			}
+			return op == "and"
+		}, nil
+	}
+
+	fieldRef := n.child("FieldRef")
+	if fieldRef == nil {
+		return nil, fmt.Errorf("field reference is missed in CAML %s", n.XMLName.Local)
+	}
+	field := fieldRef.attr("Name")
+	if strings.EqualFold(fieldRef.attr("LookupId"), "TRUE") {
+		field += "Id"
+	}
+	get := func(item map[string]interface{}) interface{} {
+		return getField(item, field)
+	}
+
+	switch op {
+	case "isnull":
+		return func(item map[string]interface{}) bool { return get(item) == nil }, nil
+	case "isnotnull":
+		return func(item map[string]interface{}) bool { return get(item) != nil }, nil
+	case "in":
+		var values []interface{}
+		if vals := n.child("Values"); vals != nil {
+			for i := range vals.Nodes {
+				values = append(values, camlValue(&vals.Nodes[i]))
+			}
+		}
+		return func(item map[string]interface{}) bool {
+			for _, v := range values {
+				if c, ok := compareValues(get(item), v); ok && c == 0 {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	valueNode := n.child("Value")
+	if valueNode == nil {
+		return nil, fmt.Errorf("value is missed in CAML %s", n.XMLName.Local)
+	}
+	value := camlValue(valueNode)
+
+	switch op {
+	case "contains", "beginswith":
+		sub := strings.ToLower(fmt.Sprintf("%v", value))
+		return func(item map[string]interface{}) bool {
+			s, ok := get(item).(string)
+			if !ok {
+				return false
+			}
+			if op == "contains" {
+				return strings.Contains(strings.ToLower(s), sub)
+			}
+			return strings.HasPrefix(strings.ToLower(s), sub)
+		}, nil
+	case "eq", "neq", "gt", "geq", "lt", "leq":
+		return func(item map[string]interface{}) bool {
+			c, ok := compareValues(get(item), value)
+			switch op {
+			case "eq":
+				return ok && c == 0
+			case "neq":
+				return !ok || c != 0
+			case "gt":
+				return ok && c > 0
+			case "geq":
+				return ok && c >= 0
+			case "lt":
+				return ok && c < 0
+			}
+			return ok && c <= 0
+		}, nil
+	}
+	return nil, fmt.Errorf("CAML operator %s is not supported", n.XMLName.Local)
+}
+
+// camlValue converts CAML Value element to a comparable value due to its type
+func camlValue(n *camlNode) interface{} {
+	text := strings.TrimSpace(n.Text)
+	switch strings.ToLower(n.attr("Type")) {
+	case "integer", "number", "counter", "lookup", "user", "currency":
+		if f, ok := parseNumber(text); ok {
+			return f
+		}
+	case "boolean":
+		return text == "1" || strings.EqualFold(text, "true")
+	case "datetime":
+		return parseDateTime(text)
+	}
+	return text
+}
+
+// apply filters, sorts and limits items
+func (q *camlQuery) apply(items []map[string]interface{}) []map[string]interface{} {
+	var res []map[string]interface{}
+	for _, item := range items {
+		if q.where(item) {
+			res = append(res, item)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		for _, o := range q.orderBy {
+			c, _ := compareValues(getField(res[i], o.field), getField(res[j], o.field))
+			if c != 0 {
+				return (c < 0) != o.desc
+			}
+		}
+		return false
+	})
+	if q.rowLimit > 0 && len(res) > q.rowLimit {
+		res = res[:q.rowLimit]
+	}
+	return res
+}
@@ -0,0 +1,642 @@
+package sptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// oDataMode response OData metadata mode
+type oDataMode int
+
+const (
+	modeVerbose oDataMode = iota
+	modeMinimal
+	modeNoMetadata
+)
+
+// getODataMode gets OData mode from the request Accept header, minimal metadata is the default one
+func getODataMode(r *http.Request) oDataMode {
+	accept := strings.ToLower(r.Header.Get("Accept"))
+	switch {
+	case strings.Contains(accept, "odata=verbose"):
+		return modeVerbose
+	case strings.Contains(accept, "odata=nometadata"):
+		return modeNoMetadata
+	}
+	return modeMinimal
+}
+
+// entity is a REST API object
+type entity struct {
+	typ   string
+	uri   string
+	etag  string
+	props map[string]interface{}
+}
+
+// serverError is SharePoint API error response
+type serverError struct {
+	status  int
+	code    string
+	message string
+}
+
+// Error returns error message
+func (e *serverError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.status, e.code, e.message)
+}
+
+// write writes error response
+func (e *serverError) write(res *response) {
+	res.error(e.status, e.code, e.message)
+}
+
+// response writes REST API responses in the request's OData mode
+type response struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	siteURL string
+	mode    oDataMode
+}
+
+// json writes JSON payload
+func (res *response) json(status int, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	contentType := "application/json;odata=minimalmetadata;streaming=true;charset=utf-8"
+	switch res.mode {
+	case modeVerbose:
+		contentType = "application/json;odata=verbose;charset=utf-8"
+	case modeNoMetadata:
+		contentType = "application/json;odata=nometadata;streaming=true;charset=utf-8"
+	}
+	res.w.Header().Set("Content-Type", contentType)
+	res.w.Header().Set("SPRequestGuid", uuid.New().String())
+	res.w.WriteHeader(status)
+	_, _ = res.w.Write(data)
+}
+
+// empty writes response without a body
+func (res *response) empty(status int) {
+	res.w.Header().Set("SPRequestGuid", uuid.New().String())
+	res.w.WriteHeader(status)
+}
+
+// error writes OData error
+func (res *response) error(status int, code string, message string) {
+	e := map[string]interface{}{
+		"code":    code,
+		"message": map[string]interface{}{"lang": "en-US", "value": message},
+	}
+	if res.mode == modeVerbose {
+		res.json(status, map[string]interface{}{"error": e})
+		return
+	}
+	res.json(status, map[string]interface{}{"odata.error": e})
+}
+
+// entity writes single entity
+func (res *response) entity(status int, e *entity) {
+	props := res.project(e)
+	if res.mode == modeVerbose {
+		res.json(status, map[string]interface{}{"d": props})
+		return
+	}
+	if res.mode == modeMinimal {
+		props["odata.metadata"] = fmt.Sprintf("%s/_api/$metadata#%s/@Element", res.siteURL, e.typ)
+	}
+	res.json(status, props)
+}
+
+// collection writes entities collection with an optional next page URL
+func (res *response) collection(entities []*entity, nextURL string) {
+	results := make([]map[string]interface{}, 0, len(entities))
+	for _, e := range entities {
+		results = append(results, res.project(e))
+	}
+	if res.mode == modeVerbose {
+		d := map[string]interface{}{"results": results}
+		if nextURL != "" {
+			d["__next"] = nextURL
+		}
+		res.json(200, map[string]interface{}{"d": d})
+		return
+	}
+	payload := map[string]interface{}{"value": results}
+	if res.mode == modeMinimal {
+		payload["odata.metadata"] = res.siteURL + "/_api/$metadata#Collection"
+	}
+	if nextURL != "" {
+		payload["odata.nextLink"] = nextURL
+	}
+	res.json(200, payload)
+}
+
+// value writes primitive method result
+func (res *response) value(name string, value interface{}) {
+	if res.mode == modeVerbose {
+		res.json(200, map[string]interface{}{"d": map[string]interface{}{name: value}})
+		return
+	}
+	payload := map[string]interface{}{"value": value}
+	if res.mode == modeMinimal {
+		payload["odata.metadata"] = res.siteURL + "/_api/$metadata#Edm.String"
+	}
+	res.json(200, payload)
+}
+
+// project applies $select and adds entity metadata
+func (res *response) project(e *entity) map[string]interface{} {
+	props := map[string]interface{}{}
+	selected := parseList(res.r.URL.Query().Get("$select"))
+	for key, val := range e.props {
+		if len(selected) > 0 && !containsFold(selected, key) {
+			continue
+		}
+		if list, ok := val.([]interface{}); ok && res.mode == modeVerbose {
+			val = map[string]interface{}{"results": list}
+		}
+		props[key] = val
+	}
+	switch res.mode {
+	case modeVerbose:
+		metadata := map[string]interface{}{"id": e.uri, "uri": e.uri, "type": e.typ}
+		if e.etag != "" {
+			metadata["etag"] = e.etag
+		}
+		props["__metadata"] = metadata
+	case modeMinimal:
+		props["odata.type"] = e.typ
+		props["odata.id"] = e.uri
+		props["odata.editLink"] = strings.TrimPrefix(e.uri, res.siteURL+"/_api/")
+		if e.etag != "" {
+			props["odata.etag"] = e.etag
+		}
+	}
+	return props
+}
+
+// query applies $filter, $orderby, $skiptoken, $skip and $top OData modifiers to a collection.
+// Page size is used when $top is not provided, paged collections get next page URL
+func (res *response) query(entities []*entity, pageSize int) ([]*entity, string, *serverError) {
+	q := res.r.URL.Query()
+
+	if filter := q.Get("$filter"); filter != "" {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			return nil, "", &serverError{400, "-1, Microsoft.SharePoint.Client.InvalidClientQueryException", err.Error()}
+		}
+		var filtered []*entity
+		for _, e := range entities {
+			if v, _ := expr(e.props).(bool); v {
+				filtered = append(filtered, e)
+			}
+		}
+		entities = filtered
+	}
+
+	if orderBy := q.Get("$orderby"); orderBy != "" {
+		entities = append([]*entity{}, entities...)
+		orders := parseList(orderBy)
+		sort.SliceStable(entities, func(i, j int) bool {
+			for _, order := range orders {
+				field, desc := order, false
+				if parts := strings.Fields(order); len(parts) == 2 {
+					field, desc = parts[0], strings.EqualFold(parts[1], "desc")
+				}
+				c, _ := compareValues(getField(entities[i].props, field), getField(entities[j].props, field))
+				if c != 0 {
+					return (c < 0) != desc
+				}
+			}
+			return false
+		})
+	}
+
+	// Paged=TRUE&p_ID=N continues after the item with ID N
+	if skipToken := q.Get("$skiptoken"); skipToken != "" {
+		token, _ := url.ParseQuery(skipToken)
+		if lastID, err := strconv.Atoi(token.Get("p_ID")); err == nil {
+			for i, e := range entities {
+				if e.props["Id"] == lastID {
+					entities = entities[i+1:]
+					break
+				}
+			}
+		}
+	}
+
+	if skip, err := strconv.Atoi(q.Get("$skip")); err == nil && skip > 0 {
+		if skip > len(entities) {
+			skip = len(entities)
+		}
+		entities = entities[skip:]
+	}
+
+	top := pageSize
+	if t, err := strconv.Atoi(q.Get("$top")); err == nil {
+		top = t
+	}
+	nextURL := ""
+	if top > 0 && len(entities) > top {
+		entities = entities[:top]
+		if pageSize > 0 {
+			q.Set("$skiptoken", fmt.Sprintf("Paged=TRUE&p_ID=%v", entities[top-1].props["Id"]))
+			u := *res.r.URL
+			u.RawQuery = q.Encode()
+			nextURL = "http://" + res.r.Host + u.RequestURI()
+		}
+	}
+
+	return entities, nextURL, nil
+}
+
+// parseList splits comma separated modifier values
+func parseList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// containsFold checks if the list contains the value ignoring case
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// getField gets entity property ignoring case, lookup paths are not supported
+func getField(props map[string]interface{}, field string) interface{} {
+	if v, ok := props[field]; ok {
+		return v
+	}
+	for key, v := range props {
+		if strings.EqualFold(key, field) {
+			return v
+		}
+	}
+	return nil
+}
+
+// compareValues compares property values: numbers, strings (ignoring case), booleans and nulls,
+// returns false when values are not comparable
+func compareValues(a, b interface{}) (int, bool) {
+	a, b = normalizeValue(a), normalizeValue(b)
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0, true
+		}
+		if a == nil {
+			return -1, false
+		}
+		return 1, false
+	}
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			if s, isStr := b.(string); isStr {
+				bv, ok = parseNumber(s)
+			}
+		}
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if bv, ok := b.(float64); ok {
+			c, ok := compareValues(bv, av)
+			return -c, ok
+		}
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(strings.ToLower(av), strings.ToLower(bv)), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av == bv:
+			return 0, true
+		case !av:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// normalizeValue converts numbers to float64
+func normalizeValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	}
+	return v
+}
+
+// parseNumber parses a number
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// parseDateTime parses date literal to the format used for stored dates
+func parseDateTime(s string) string {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return s
+}
+
+// filterExpr is a compiled $filter expression evaluated against entity properties
+type filterExpr func(props map[string]interface{}) interface{}
+
+// filterParser is $filter expression recursive descent parser
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseFilter compiles $filter expression, supports eq, ne, gt, ge, lt, le, and, or, not,
+// parentheses, substringof, startswith and endswith functions, string, number, boolean, null and datetime literals
+func parseFilter(filter string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token '%s' in $filter", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizeFilter splits $filter expression to tokens, string literals keep their quotes
+func tokenizeFilter(filter string) ([]string, error) {
+	var tokens []string
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '\'':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal in $filter")
+			}
+			tokens = append(tokens, string(runes[i:j+1]))
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("(),'", runes[j]) {
+				j++
+			}
+			token := string(runes[i:j])
+			// typed literals, e.g. datetime'2020-01-01T00:00:00Z'
+			if j < len(runes) && runes[j] == '\'' {
+				k := j + 1
+				for k < len(runes) && runes[k] != '\'' {
+					k++
+				}
+				if k >= len(runes) {
+					return nil, fmt.Errorf("unterminated literal in $filter")
+				}
+				token = string(runes[i : k+1])
+				j = k + 1
+			}
+			tokens = append(tokens, token)
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *filterParser) expect(token string) error {
+	if t := p.next(); t != token {
+		return fmt.Errorf("expected '%s' in $filter, got '%s'", token, t)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]interface{}) interface{} {
+			lv, _ := l(props).(bool)
+			rv, _ := right(props).(bool)
+			return lv || rv
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]interface{}) interface{} {
+			lv, _ := l(props).(bool)
+			rv, _ := right(props).(bool)
+			return lv && rv
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if strings.EqualFold(p.peek(), "not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(props map[string]interface{}) interface{} {
+			v, _ := expr(props).(bool)
+			return !v
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(p.peek())
+	switch op {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+	default:
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(props map[string]interface{}) interface{} {
+		c, ok := compareValues(left(props), right(props))
+		switch op {
+		case "eq":
+			return ok && c == 0
+		case "ne":
+			return !ok || c != 0
+		case "gt":
+			return ok && c > 0
+		case "ge":
+			return ok && c >= 0
+		case "lt":
+			return ok && c < 0
+		}
+		return ok && c <= 0
+	}, nil
+}
+
+func (p *filterParser) parseOperand() (filterExpr, error) {
+	token := p.next()
+	lower := strings.ToLower(token)
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of $filter")
+	case token == "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	case strings.HasPrefix(token, "'"):
+		value := strings.ReplaceAll(token[1:len(token)-1], "''", "'")
+		return func(map[string]interface{}) interface{} { return value }, nil
+	case strings.HasPrefix(lower, "datetime'"):
+		value := parseDateTime(token[len("datetime'") : len(token)-1])
+		return func(map[string]interface{}) interface{} { return value }, nil
+	case lower == "true" || lower == "false":
+		value := lower == "true"
+		return func(map[string]interface{}) interface{} { return value }, nil
+	case lower == "null":
+		return func(map[string]interface{}) interface{} { return nil }, nil
+	case p.peek() == "(":
+		return p.parseFunction(lower)
+	}
+	if n, ok := parseNumber(token); ok {
+		return func(map[string]interface{}) interface{} { return n }, nil
+	}
+	return func(props map[string]interface{}) interface{} { return getField(props, token) }, nil
+}
+
+func (p *filterParser) parseFunction(name string) (filterExpr, error) {
+	p.next() // (
+	var args []filterExpr
+	for p.peek() != ")" {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() == "," {
+			p.next()
+		}
+	}
+	p.next() // )
+	if len(args) != 2 {
+		return nil, fmt.Errorf("function %s expects 2 arguments in $filter", name)
+	}
+	str := func(expr filterExpr, props map[string]interface{}) string {
+		s, _ := expr(props).(string)
+		return strings.ToLower(s)
+	}
+	switch name {
+	case "substringof":
+		return func(props map[string]interface{}) interface{} {
+			return strings.Contains(str(args[1], props), str(args[0], props))
+		}, nil
+	case "startswith":
+		return func(props map[string]interface{}) interface{} {
+			return strings.HasPrefix(str(args[0], props), str(args[1], props))
+		}, nil
+	case "endswith":
+		return func(props map[string]interface{}) interface{} {
+			return strings.HasSuffix(str(args[0], props), str(args[1], props))
+		}, nil
+	}
+	return nil, fmt.Errorf("function %s is not supported in $filter", name)
+}
@@ -0,0 +1,309 @@
+package sptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeSharing is list item sharing state
+type fakeSharing struct {
+	links      []*fakeSharingLink
+	principals []*fakeSharingPrincipal
+}
+
+type fakeSharingLink struct {
+	shareID    string
+	kind       int
+	scope      int
+	role       int
+	expiration string
+	password   bool
+	members    []string
+	created    time.Time
+}
+
+type fakeSharingPrincipal struct {
+	loginName string
+	role      int
+}
+
+// getSharing gets list item sharing state by the item unique ID
+func (s *Server) getSharing(item map[string]interface{}) *fakeSharing {
+	id := fmt.Sprint(item["GUID"])
+	if s.sharing[id] == nil {
+		s.sharing[id] = &fakeSharing{}
+	}
+	return s.sharing[id]
+}
+
+// itemAbsURL gets list item encoded absolute URL, files and folders are addressed by their URLs
+func (s *Server) itemAbsURL(l *fakeList, item map[string]interface{}) string {
+	u := fmt.Sprintf("%s/%v_.000", l.rootFolder, item["Id"])
+	if ref, ok := item["FileRef"].(string); ok {
+		u = ref
+	}
+	return strings.TrimSuffix(s.SiteURL, ServerSitePath) + (&url.URL{Path: u}).EscapedPath()
+}
+
+// getItemByURL gets list item by its absolute URL
+func (s *Server) getItemByURL(u string) (*fakeList, map[string]interface{}) {
+	for _, l := range s.lists {
+		for _, item := range l.items {
+			if strings.EqualFold(s.itemAbsURL(l, item), u) {
+				return l, item
+			}
+		}
+	}
+	return nil, nil
+}
+
+// shareLink creates sharing link, links of the same kind are reused except specific people links
+func (s *Server) shareLink(res *response, l *fakeList, item map[string]interface{}, body []byte) *serverError {
+	r := &struct {
+		Request struct {
+			Settings struct {
+				LinkKind                int    `json:"linkKind"`
+				Scope                   int    `json:"scope"`
+				Role                    int    `json:"role"`
+				Expiration              string `json:"expiration"`
+				Password                string `json:"password"`
+				RestrictShareMembership bool   `json:"restrictShareMembership"`
+			} `json:"settings"`
+			PeoplePickerInput string `json:"peoplePickerInput"`
+		} `json:"request"`
+	}{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errBadRequest(err.Error())
+	}
+	settings := r.Request.Settings
+	if settings.LinkKind == 0 {
+		return errBadRequest("The link kind is required.")
+	}
+	sharing := s.getSharing(item)
+	var link *fakeSharingLink
+	for _, existing := range sharing.links {
+		if existing.kind == settings.LinkKind && existing.kind != 6 {
+			link = existing
+		}
+	}
+	if link == nil {
+		link = &fakeSharingLink{shareID: uuid.New().String(), kind: settings.LinkKind, created: time.Now().UTC()}
+		sharing.links = append(sharing.links, link)
+	}
+	link.scope, link.role = settings.Scope, settings.Role
+	link.expiration = settings.Expiration
+	link.password = settings.Password != ""
+	link.members = append(link.members, parsePeoplePicker(r.Request.PeoplePickerInput)...)
+	res.json(200, s.sharingPayload(res, map[string]interface{}{"sharingLinkInfo": s.sharingLinkInfo(l, item, link)}))
+	return nil
+}
+
+// getSharingInformation responds with item sharing links and principals
+func (s *Server) getSharingInformation(res *response, l *fakeList, item map[string]interface{}) *serverError {
+	sharing := s.getSharing(item)
+	links := []map[string]interface{}{}
+	for _, link := range sharing.links {
+		members := []map[string]interface{}{}
+		for _, m := range link.members {
+			members = append(members, sharingPrincipal(m))
+		}
+		links = append(links, map[string]interface{}{
+			"isInherited": false,
+			"linkDetails": s.sharingLinkInfo(l, item, link),
+			"linkMembers": members,
+		})
+	}
+	principals := []map[string]interface{}{}
+	for _, p := range sharing.principals {
+		principals = append(principals, map[string]interface{}{
+			"isInherited": false,
+			"principal":   sharingPrincipal(p.loginName),
+			"role":        p.role,
+		})
+	}
+	res.json(200, s.sharingPayload(res, map[string]interface{}{
+		"itemUrl":                                s.itemAbsURL(l, item),
+		"canAddExternalPrincipal":                true,
+		"canAddInternalPrincipal":                true,
+		"canBeUnshared":                          len(links) > 0 || len(principals) > 0,
+		"hasUniquePermissions":                   len(principals) > 0,
+		"anonymousLinkExpirationRestrictionDays": 0,
+		"permissionsInformation": map[string]interface{}{
+			"hasInheritedLinks": false,
+			"links":             links,
+			"principals":        principals,
+		},
+	}))
+	return nil
+}
+
+// unshareLink removes sharing link by its share ID
+func (s *Server) unshareLink(res *response, item map[string]interface{}, body []byte) *serverError {
+	r := &struct {
+		LinkKind int    `json:"linkKind"`
+		ShareID  string `json:"shareId"`
+	}{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errBadRequest(err.Error())
+	}
+	sharing := s.getSharing(item)
+	for i, link := range sharing.links {
+		if strings.EqualFold(link.shareID, r.ShareID) && link.kind == r.LinkKind {
+			sharing.links = append(sharing.links[:i], sharing.links[i+1:]...)
+			res.empty(200)
+			return nil
+		}
+	}
+	return errNotFound(fmt.Sprintf("Sharing link '%s' is not found.", r.ShareID))
+}
+
+// deleteLinkByKind removes all sharing links of the kind
+func (s *Server) deleteLinkByKind(res *response, item map[string]interface{}, body []byte) *serverError {
+	r := &struct {
+		LinkKind int `json:"linkKind"`
+	}{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errBadRequest(err.Error())
+	}
+	sharing := s.getSharing(item)
+	var links []*fakeSharingLink
+	for _, link := range sharing.links {
+		if link.kind != r.LinkKind {
+			links = append(links, link)
+		}
+	}
+	sharing.links = links
+	res.empty(200)
+	return nil
+}
+
+// shareObject grants the users direct access to the object, unshare removes all links and direct access
+func (s *Server) shareObject(res *response, body []byte, unshare bool) *serverError {
+	r := &struct {
+		URL               string `json:"url"`
+		PeoplePickerInput string `json:"peoplePickerInput"`
+		RoleValue         string `json:"roleValue"`
+	}{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errBadRequest(err.Error())
+	}
+	l, item := s.getItemByURL(r.URL)
+	if item == nil {
+		return errFileNotFound()
+	}
+	sharing := s.getSharing(item)
+	result := map[string]interface{}{
+		"StatusCode":                0,
+		"ErrorMessage":              nil,
+		"Name":                      path.Base(s.itemAbsURL(l, item)),
+		"Url":                       r.URL,
+		"UniquelyPermissionedUsers": []map[string]interface{}{},
+	}
+	if unshare {
+		sharing.links, sharing.principals = nil, nil
+		res.json(200, s.sharingPayload(res, result))
+		return nil
+	}
+
+	loginNames := parsePeoplePicker(r.PeoplePickerInput)
+	if len(loginNames) == 0 {
+		result["StatusCode"] = -1
+		result["ErrorMessage"] = "No resolved users."
+		res.json(200, s.sharingPayload(res, result))
+		return nil
+	}
+	role := 1
+	if r.RoleValue == "role:1073741827" {
+		role = 2
+	}
+	var users []map[string]interface{}
+	for _, loginName := range loginNames {
+		sharing.principals = append(sharing.principals, &fakeSharingPrincipal{loginName: loginName, role: role})
+		p := sharingPrincipal(loginName)
+		users = append(users, map[string]interface{}{
+			"User":        loginName,
+			"Email":       p["email"],
+			"DisplayName": p["name"],
+			"Status":      true,
+			"Message":     nil,
+			"IsUserKnown": true,
+		})
+	}
+	result["UniquelyPermissionedUsers"] = users
+	res.json(200, s.sharingPayload(res, result))
+	return nil
+}
+
+// sharingLinkInfo formats SP.SharingLinkInfo
+func (s *Server) sharingLinkInfo(l *fakeList, item map[string]interface{}, link *fakeSharingLink) map[string]interface{} {
+	anonymous := link.kind == 4 || link.kind == 5 || (link.kind == 6 && link.scope == 0)
+	external := false
+	for _, m := range link.members {
+		external = external || isExternalLogin(m)
+	}
+	return map[string]interface{}{
+		"AllowsAnonymousAccess":     anonymous,
+		"Created":                   link.created.Format(dateFormat),
+		"Expiration":                link.expiration,
+		"HasExternalGuestInvitees":  external,
+		"IsActive":                  true,
+		"IsAnonymous":               anonymous,
+		"IsEditLink":                link.kind == 3 || link.kind == 5 || link.role == 2,
+		"LinkKind":                  link.kind,
+		"RequiresPassword":          link.password,
+		"RestrictedShareMembership": link.kind == 6 && link.scope == 2,
+		"Scope":                     link.scope,
+		"ShareId":                   link.shareID,
+		"Url":                       fmt.Sprintf("%s/:u:/g/%s", strings.TrimSuffix(s.SiteURL, ServerSitePath), strings.ReplaceAll(link.shareID, "-", "")),
+	}
+}
+
+// sharingPayload wraps sharing method result in the request's OData mode
+func (s *Server) sharingPayload(res *response, payload map[string]interface{}) map[string]interface{} {
+	if res.mode == modeVerbose {
+		return map[string]interface{}{"d": payload}
+	}
+	return payload
+}
+
+// sharingPrincipal formats SP.Sharing.Principal by login name
+func sharingPrincipal(loginName string) map[string]interface{} {
+	email := loginName
+	if i := strings.LastIndex(loginName, "|"); i != -1 {
+		email = loginName[i+1:]
+	}
+	return map[string]interface{}{
+		"id":            0,
+		"loginName":     loginName,
+		"name":          strings.Split(email, "@")[0],
+		"email":         email,
+		"principalType": 1,
+		"isExternal":    isExternalLogin(loginName),
+	}
+}
+
+// isExternalLogin checks guest user login name
+func isExternalLogin(loginName string) bool {
+	return strings.Contains(strings.ToLower(loginName), "#ext#")
+}
+
+// parsePeoplePicker gets login names from people picker input
+func parsePeoplePicker(input string) []string {
+	var keys []struct {
+		Key string `json:"Key"`
+	}
+	_ = json.Unmarshal([]byte(input), &keys)
+	var res []string
+	for _, k := range keys {
+		if k.Key != "" {
+			res = append(res, k.Key)
+		}
+	}
+	return res
+}
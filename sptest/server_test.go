@@ -0,0 +1,235 @@
+package sptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/api"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	sp := api.NewSP(srv.Client())
+
+	modes := map[string]*api.RequestConfig{
+		"Verbose":         api.HeadersPresets.Verbose,
+		"Minimalmetadata": api.HeadersPresets.Minimalmetadata,
+		"Nometadata":      api.HeadersPresets.Nometadata,
+	}
+
+	t.Run("Web", func(t *testing.T) {
+		for mode, conf := range modes {
+			web, err := sp.Web().Conf(conf).Select("Title,ServerRelativeUrl").Get(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if web.Data().Title != "Test" || web.Data().ServerRelativeURL != ServerSitePath {
+				t.Errorf("%s: unexpected web data: %s", mode, web)
+			}
+		}
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		if _, err := sp.Web().Lists().Add(ctx, "Tasks", nil); err != nil {
+			t.Fatal(err)
+		}
+		for mode, conf := range modes {
+			lists, err := sp.Web().Lists().Conf(conf).Filter("BaseTemplate eq 100").Get(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lists.Data()) != 1 || lists.Data()[0].Data().Title != "Tasks" {
+				t.Errorf("%s: unexpected lists: %s", mode, lists)
+			}
+		}
+		list, err := sp.Web().GetList("Lists/Tasks").Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Data().ListItemEntityTypeFullName != "SP.Data.TasksListItem" {
+			t.Errorf("unexpected entity type: %s", list.Data().ListItemEntityTypeFullName)
+		}
+		if _, err := sp.Web().Lists().GetByTitle("Missing").Get(ctx); !gosip.IsNotFound(err) {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("Items", func(t *testing.T) {
+		list := sp.Web().Lists().GetByTitle("Tasks")
+		for i, title := range []string{"Charlie", "Alpha", "Bravo", "Delta"} {
+			body, _ := json.Marshal(map[string]interface{}{"Title": title, "Priority": i})
+			if _, err := list.Items().Add(ctx, body); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for mode, conf := range modes {
+			items, err := list.Items().Conf(conf).
+				Select("Id,Title").
+				Filter("Priority ge 1 and substringof('a',Title)").
+				OrderBy("Title", true).
+				Top(2).
+				Get(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := items.Data()
+			if len(data) != 2 || data[0].Data().Title != "Alpha" || data[1].Data().Title != "Bravo" {
+				t.Errorf("%s: unexpected items: %s", mode, items)
+			}
+			if strings.Contains(string(data[0].Normalized()), "Priority") {
+				t.Errorf("%s: $select is not applied: %s", mode, data[0].Normalized())
+			}
+		}
+
+		all, err := list.Items().Top(1).GetAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 4 {
+			t.Errorf("expected 4 items with paging, got %d", len(all))
+		}
+
+		items, err := list.Items().GetByCAML(ctx, `
+			<View>
+				<Query>
+					<Where>
+						<Or>
+							<Eq><FieldRef Name="Title" /><Value Type="Text">Delta</Value></Eq>
+							<Lt><FieldRef Name="Priority" /><Value Type="Number">2</Value></Lt>
+						</Or>
+					</Where>
+					<OrderBy><FieldRef Name="Priority" Ascending="FALSE" /></OrderBy>
+				</Query>
+				<RowLimit>2</RowLimit>
+			</View>
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data := items.Data(); len(data) != 2 || data[0].Data().Title != "Delta" || data[1].Data().Title != "Alpha" {
+			t.Errorf("unexpected CAML items: %s", items)
+		}
+
+		item := list.Items().GetByID(1)
+		if _, err := item.Update(ctx, []byte(`{"Title":"Echo"}`)); err != nil {
+			t.Fatal(err)
+		}
+		data, err := item.Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.Data().Title != "Echo" {
+			t.Errorf("item is not updated: %s", data)
+		}
+
+		if _, err := list.Items().Add(ctx, []byte(`{"__metadata":{"type":"SP.Data.WrongListItem"}}`)); err == nil {
+			t.Error("should fail with wrong entity type")
+		}
+	})
+
+	t.Run("FoldersAndFiles", func(t *testing.T) {
+		web := sp.Web()
+		if _, err := web.EnsureFolder(ctx, "Shared Documents/a/b"); err != nil {
+			t.Fatal(err)
+		}
+		folder := web.GetFolder("Shared Documents/a/b")
+		if _, err := folder.Files().Add(ctx, "hello.txt", []byte("Hello"), true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := folder.Files().Add(ctx, "hello.txt", []byte("Hello"), false); err == nil {
+			t.Error("should not overwrite existing file")
+		}
+
+		content, err := web.GetFile("Shared Documents/a/b/hello.txt").Download(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "Hello" {
+			t.Errorf("unexpected file content: %s", content)
+		}
+
+		stream := bytes.NewReader(bytes.Repeat([]byte("0123456789"), 10))
+		file, err := folder.Files().AddChunked(ctx, "chunked.txt", stream, &api.AddChunkedOptions{ChunkSize: 30, Overwrite: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Data().Length != 100 {
+			t.Errorf("unexpected chunked file length: %d", file.Data().Length)
+		}
+
+		files, err := folder.Files().Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files.Data()) != 2 {
+			t.Errorf("expected 2 files, got %d", len(files.Data()))
+		}
+
+		items, err := web.Lists().GetByTitle("Documents").Items().Filter("FSObjType eq 0").Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items.Data()) != 2 {
+			t.Errorf("library files should have items, got %d", len(items.Data()))
+		}
+	})
+
+	t.Run("RecycleBin", func(t *testing.T) {
+		web := sp.Web()
+		if err := web.GetFile("Shared Documents/a/b/hello.txt").Recycle(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := web.GetFile("Shared Documents/a/b/hello.txt").Get(ctx); !gosip.IsNotFound(err) {
+			t.Errorf("recycled file should not be found, got %v", err)
+		}
+
+		bin, err := web.RecycleBin().Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bin.Data()) != 1 {
+			t.Fatalf("expected 1 recycled item, got %d", len(bin.Data()))
+		}
+		if err := web.RecycleBin().GetByID(bin.Data()[0].Data().ID).Restore(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := web.GetFile("Shared Documents/a/b/hello.txt").Get(ctx); err != nil {
+			t.Errorf("file should be restored: %v", err)
+		}
+	})
+}
+
+func TestServerFilter(t *testing.T) {
+	item := map[string]interface{}{"Title": "It's", "Count": 5, "Done": true, "Empty": nil, "Created": "2020-05-01T00:00:00Z"}
+	cases := map[string]bool{
+		"Title eq 'it''s'":                                   true,
+		"Count gt 4 and Count lt 6":                          true,
+		"(Count eq 1 or Count eq 5) and not (Done eq false)": true,
+		"Empty eq null":                                      true,
+		"Empty ne null":                                      false,
+		"startswith(Title,'It')":                             true,
+		"Created ge datetime'2020-01-01T00:00:00Z'":          true,
+		"Created lt datetime'2020-01-01'":                    false,
+	}
+	for filter, expected := range cases {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			t.Errorf("%s: %v", filter, err)
+			continue
+		}
+		if res, _ := expr(item).(bool); res != expected {
+			t.Errorf("%s: expected %t, got %t", filter, expected, res)
+		}
+	}
+	if _, err := parseFilter("Title eq 'unterminated"); err == nil {
+		t.Error("should fail on unterminated literal")
+	}
+}
@@ -0,0 +1,289 @@
+// Package sptest helps testing SharePoint API consumers offline.
+//
+// Recorder wraps SPClient's transport and records SharePoint API request/response pairs
+// to a cassette file with secrets redacted, in replay mode the cassette is served
+// deterministically without a network access.
+//
+//	rec, _ := sptest.NewRecorder("testdata/lists.json", sptest.ModeAuto)
+//	defer rec.Stop()
+//	client := rec.Wrap(&gosip.SPClient{AuthCnfg: rec.AuthCnfg(auth)})
+package sptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/recolabs/gosip"
+)
+
+// Mode recorder mode
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette, fails for not recorded requests
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to SharePoint and records them to the cassette
+	ModeRecord
+	// ModeAuto replays when the cassette exists, records otherwise
+	ModeAuto
+)
+
+// ErrNotRecorded is returned in replay mode for requests which are not in the cassette
+var ErrNotRecorded = errors.New("sptest: request is not recorded")
+
+// Matcher checks if the recorded interaction matches the request, body is the request body
+type Matcher func(req *http.Request, body []byte, i *Interaction) bool
+
+// Recorder is http.RoundTripper recording or replaying SharePoint API interactions.
+// Always use NewRecorder constructor instead of &Recorder{}
+type Recorder struct {
+	Transport     http.RoundTripper        // transport used in record mode, http.DefaultTransport when nil
+	Matcher       Matcher                  // replay matcher, DefaultMatcher when nil
+	RedactHeaders []string                 // headers to redact, DefaultRedactHeaders by default
+	Redact        func(i *Interaction)     // optional custom redaction, called after the default one
+	Filter        func(*http.Request) bool // requests to record, SharePoint API requests sent by SPClient by default
+
+	mode         Mode
+	cassettePath string
+	cassette     *Cassette
+	used         map[*Interaction]bool
+	mu           sync.Mutex
+}
+
+// NewRecorder creates recorder for the cassette file in the mode
+func NewRecorder(cassettePath string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		RedactHeaders: DefaultRedactHeaders,
+		mode:          mode,
+		cassettePath:  cassettePath,
+		cassette:      &Cassette{},
+		used:          map[*Interaction]bool{},
+	}
+	if mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(cassettePath); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+	if r.mode == ModeReplay {
+		cassette, err := LoadCassette(cassettePath)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+	}
+	return r, nil
+}
+
+// Mode gets recorder actual mode, ModeAuto is resolved to either ModeRecord or ModeReplay
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette gets recorded interactions
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// Wrap sets the recorder as the client's transport, the client's transport is used for sending requests
+func (r *Recorder) Wrap(client *gosip.SPClient) *gosip.SPClient {
+	if client.Transport != nil && client.Transport != r {
+		r.Transport = client.Transport
+	}
+	client.Transport = r
+	return client
+}
+
+// AuthCnfg returns the auth config in record mode, and an offline stub for the same site in replay mode
+func (r *Recorder) AuthCnfg(auth gosip.AuthCnfg) gosip.AuthCnfg {
+	if r.mode == ModeRecord {
+		return auth
+	}
+	return &AuthCnfg{SiteURL: auth.GetSiteURL()}
+}
+
+// Stop saves the cassette in record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.cassettePath)
+}
+
+// RoundTrip records or replays the request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// record sends the request and records the interaction
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil || !r.filter(req) {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: &Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: &Response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+	redact(i, r.RedactHeaders)
+	if r.Redact != nil {
+		r.Redact(i)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay finds the matching interaction and creates the response from it.
+// Interactions are served in the recorded order, the last matching one is reused when all are served
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	matcher := r.Matcher
+	if matcher == nil {
+		matcher = DefaultMatcher
+	}
+
+	r.mu.Lock()
+	var match *Interaction
+	for _, i := range r.cassette.Interactions {
+		if !matcher(req, body, i) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match != nil {
+		r.used[match] = true
+	}
+	r.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+
+	return &http.Response{
+		StatusCode:    match.Response.StatusCode,
+		Status:        match.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Response.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// filter checks if the request should be recorded
+func (r *Recorder) filter(req *http.Request) bool {
+	if r.Filter != nil {
+		return r.Filter(req)
+	}
+	// Auth flows' requests are not recorded, SPClient tags API requests with the client header
+	return req.Header.Get("X-ClientService-ClientTag") != ""
+}
+
+// boundaryRegExp matches OData batch and changeset boundaries which are unique per request
+var boundaryRegExp = regexp.MustCompile(`(batch|changeset)_[0-9a-fA-F-]{36}`)
+
+// DefaultMatcher matches requests by method, path, query and body,
+// query parameters order and batch boundaries are ignored
+func DefaultMatcher(req *http.Request, body []byte, i *Interaction) bool {
+	if !strings.EqualFold(req.Method, i.Request.Method) {
+		return false
+	}
+	u, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return false
+	}
+	if req.URL.Path != u.Path || req.URL.Query().Encode() != u.Query().Encode() {
+		return false
+	}
+	return boundaryRegExp.ReplaceAllString(string(body), "$1") ==
+		boundaryRegExp.ReplaceAllString(i.Request.Body, "$1")
+}
+
+// readBody reads request body and restores it for sending
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// AuthCnfg is an offline auth config stub for replay mode, no authentication is applied
+type AuthCnfg struct {
+	SiteURL string `json:"siteUrl"`
+}
+
+// ReadConfig does nothing, the stub has no private config
+func (c *AuthCnfg) ReadConfig(_ string) error { return nil }
+
+// ParseConfig parses site URL from a provided JSON byte array content
+func (c *AuthCnfg) ParseConfig(jsonConf []byte) error { return json.Unmarshal(jsonConf, &c) }
+
+// WriteConfig does nothing, the stub has no private config
+func (c *AuthCnfg) WriteConfig(_ string) error { return nil }
+
+// GetAuth returns redacted token
+func (c *AuthCnfg) GetAuth(_ context.Context) (string, int64, error) { return Redacted, 0, nil }
+
+// GetSiteURL gets site URL
+func (c *AuthCnfg) GetSiteURL() string { return c.SiteURL }
+
+// GetStrategy gets auth strategy name
+func (c *AuthCnfg) GetStrategy() string { return "sptest" }
+
+// SetAuth does nothing, replayed requests are not authenticated
+func (c *AuthCnfg) SetAuth(_ *http.Request, _ *gosip.SPClient) error { return nil }
@@ -0,0 +1,144 @@
+package sptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/gosip"
+	"github.com/recolabs/gosip/api"
+)
+
+type secretCnfg struct{ siteURL string }
+
+func (c *secretCnfg) ReadConfig(_ string) error                        { return nil }
+func (c *secretCnfg) ParseConfig(_ []byte) error                       { return nil }
+func (c *secretCnfg) WriteConfig(_ string) error                       { return nil }
+func (c *secretCnfg) GetAuth(_ context.Context) (string, int64, error) { return "secret-token", 0, nil }
+func (c *secretCnfg) GetSiteURL() string                               { return c.siteURL }
+func (c *secretCnfg) GetStrategy() string                              { return "secret" }
+func (c *secretCnfg) SetAuth(req *http.Request, _ *gosip.SPClient) error {
+	req.Header.Set("Authorization", "Bearer secret-token")
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	var title = "Site"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Set-Cookie", "FedAuth=secret-cookie")
+		switch {
+		case strings.EqualFold(r.URL.Path, "/sites/test/_api/contextinfo"):
+			_, _ = w.Write([]byte(`{"d":{"GetContextWebInformation":{"FormDigestValue":"secret-digest","FormDigestTimeoutSeconds":1800}}}`))
+		case r.Method == "POST":
+			title = "Updated"
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = fmt.Fprintf(w, `{"d":{"Title":"%s"}}`, title)
+		}
+	}))
+	defer srv.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "web.json")
+	siteURL := srv.URL + "/sites/test"
+
+	scenario := func(rec *Recorder) []string {
+		client := rec.Wrap(&gosip.SPClient{AuthCnfg: rec.AuthCnfg(&secretCnfg{siteURL: siteURL})})
+		web := api.NewSP(client).Web()
+		var titles []string
+		for _, update := range []bool{false, true, false} {
+			if update {
+				if _, err := web.Update(context.Background(), []byte(`{"Title":"Updated"}`)); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			resp, err := web.Select("Title").Get(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			titles = append(titles, resp.Data().Title)
+		}
+		return titles
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		rec, err := NewRecorder(cassettePath, ModeAuto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Mode() != ModeRecord {
+			t.Fatal("should record when no cassette exists")
+		}
+		if titles := scenario(rec); strings.Join(titles, ",") != "Site,Updated" {
+			t.Errorf("unexpected titles: %v", titles)
+		}
+		if err := rec.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		srv.Close()
+
+		data, err := os.ReadFile(cassettePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{"secret-token", "secret-cookie", "secret-digest"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("cassette contains not redacted %s", secret)
+			}
+		}
+		if len(rec.Cassette().Interactions) != 4 {
+			t.Errorf("expected 4 interactions, got %d", len(rec.Cassette().Interactions))
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		rec, err := NewRecorder(cassettePath, ModeAuto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Mode() != ModeReplay {
+			t.Fatal("should replay when the cassette exists")
+		}
+		if titles := scenario(rec); strings.Join(titles, ",") != "Site,Updated" {
+			t.Errorf("unexpected replayed titles: %v", titles)
+		}
+	})
+
+	t.Run("NotRecorded", func(t *testing.T) {
+		rec, err := NewRecorder(cassettePath, ModeReplay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := rec.Wrap(&gosip.SPClient{AuthCnfg: &AuthCnfg{SiteURL: siteURL}})
+		_, err = api.NewSP(client).Web().Lists().Get(context.Background())
+		if !errors.Is(err, ErrNotRecorded) {
+			t.Errorf("expected not recorded error, got %v", err)
+		}
+	})
+}
+
+func TestDefaultMatcher(t *testing.T) {
+	i := &Interaction{Request: &Request{
+		Method: "POST",
+		URL:    "https://contoso.sharepoint.com/_api/$batch?a=1&b=2",
+		Body:   "--batch_11111111-2222-3333-4444-555555555555\r\n--changeset_11111111-2222-3333-4444-555555555555",
+	}}
+	req, _ := http.NewRequest("POST", "http://localhost/_api/$batch?b=2&a=1", nil)
+	body := []byte("--batch_aaaaaaaa-2222-3333-4444-555555555555\r\n--changeset_bbbbbbbb-2222-3333-4444-555555555555")
+	if !DefaultMatcher(req, body, i) {
+		t.Error("should match ignoring host, query order and batch boundaries")
+	}
+	if DefaultMatcher(req, []byte("other"), i) {
+		t.Error("should not match different body")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 )
 
 type AnonymousCnfg struct {
@@ -65,5 +66,16 @@ func startFakeServer(addr string, handler http.Handler) (io.Closer, error) {
 		_ = srv.Serve(listener.(*net.TCPListener))
 	}()
 
-	return listener, nil
+	return srv, nil
+}
+
+// attemptTransport exposes the retry attempt number to the fake servers in X-Test-Attempt header
+type attemptTransport struct{}
+
+func (attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if attempt := RetryAttempt(req); attempt > 0 {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-Test-Attempt", strconv.Itoa(attempt))
+	}
+	return http.DefaultTransport.RoundTrip(req)
 }